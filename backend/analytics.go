@@ -0,0 +1,42 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/analytics"
+)
+
+// AnalyzeSeries computes rolling statistics, smoothing, period changes and
+// a trend for a numeric series. seriesJSON is an analytics.SeriesQuery, such
+// as {"kind":"weight"} or {"kind":"metric","key":"<metric id>"}, and
+// optionsJSON an optional analytics.Options.
+func (a *App) AnalyzeSeries(seriesJSON string, optionsJSON string) (analytics.Analysis, error) {
+	var query analytics.SeriesQuery
+	err := json.Unmarshal([]byte(seriesJSON), &query)
+	if err != nil {
+		return analytics.Analysis{}, fmt.Errorf("invalid series format: %w", err)
+	}
+
+	var options analytics.Options
+	if optionsJSON != "" {
+		err = json.Unmarshal([]byte(optionsJSON), &options)
+		if err != nil {
+			return analytics.Analysis{}, fmt.Errorf("invalid options format: %w", err)
+		}
+	}
+
+	return analytics.Analyze(query, options)
+}
+
+// ForecastGoal projects when a series reaches a target. goalJSON is an
+// analytics.Goal, such as {"series":{"kind":"body_fat"},"target":15}; metric
+// series default to the metric's goal.
+func (a *App) ForecastGoal(goalJSON string) (analytics.Forecast, error) {
+	var goal analytics.Goal
+	err := json.Unmarshal([]byte(goalJSON), &goal)
+	if err != nil {
+		return analytics.Forecast{}, fmt.Errorf("invalid goal format: %w", err)
+	}
+
+	return analytics.ForecastGoal(goal)
+}
@@ -0,0 +1,95 @@
+package analytics
+
+import "fmt"
+
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// Options tune Analyze. Window is in days (7 by default), Alpha is the
+// smoothing factor per day (2/(Window+1) by default), Period is week or
+// month, and Percentiles go from 0 to 100 (10, 50 and 90 by default).
+type Options struct {
+	Window      int       `json:"window,omitempty"`
+	Alpha       float64   `json:"alpha,omitempty"`
+	Period      string    `json:"period,omitempty"`
+	Percentiles []float64 `json:"percentiles,omitempty"`
+}
+
+// Analysis is a series with its statistics. Trend is nil when there are
+// fewer than three points.
+type Analysis struct {
+	Series
+	Summary  Summary        `json:"summary"`
+	Rolling  []WindowStats  `json:"rolling"`
+	Smoothed []Point        `json:"smoothed"`
+	Changes  []PeriodChange `json:"changes"`
+	Trend    *Trend         `json:"trend"`
+}
+
+func (o Options) withDefaults() (Options, error) {
+	if o.Window == 0 {
+		o.Window = 7
+	}
+	if o.Window < 1 {
+		return o, fmt.Errorf("window must be at least one day")
+	}
+	if o.Alpha == 0 {
+		o.Alpha = 2 / float64(o.Window+1)
+	}
+	if o.Alpha < 0 || o.Alpha > 1 {
+		return o, fmt.Errorf("alpha must be between 0 and 1")
+	}
+	if o.Period == "" {
+		o.Period = PeriodWeek
+	}
+	if o.Period != PeriodWeek && o.Period != PeriodMonth {
+		return o, fmt.Errorf("unknown period %q, expected week or month", o.Period)
+	}
+	if len(o.Percentiles) == 0 {
+		o.Percentiles = []float64{10, 50, 90}
+	}
+	for _, p := range o.Percentiles {
+		if p < 0 || p > 100 {
+			return o, fmt.Errorf("percentile %v is not between 0 and 100", p)
+		}
+	}
+	return o, nil
+}
+
+// Analyze loads a series and computes its statistics.
+func Analyze(query SeriesQuery, options Options) (Analysis, error) {
+	series, err := Load(query)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	return AnalyzeSeries(series, options)
+}
+
+// AnalyzeSeries computes the statistics of an already loaded series.
+func AnalyzeSeries(series Series, options Options) (Analysis, error) {
+	options, err := options.withDefaults()
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	values := make([]float64, len(series.Points))
+	for i, point := range series.Points {
+		values[i] = point.Value
+	}
+
+	analysis := Analysis{
+		Series:   series,
+		Summary:  Summarize(values, options.Percentiles),
+		Rolling:  Rolling(series.Points, options.Window, options.Percentiles),
+		Smoothed: Smooth(series.Points, options.Alpha),
+		Changes:  Changes(series.Points, options.Period),
+	}
+	if trend, ok := FitTrend(series.Points); ok {
+		analysis.Trend = &trend
+	}
+
+	return analysis, nil
+}
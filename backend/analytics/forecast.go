@@ -0,0 +1,312 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"myproject/backend/database"
+	"time"
+)
+
+// Forecasting methods. Linear extends the least-squares trend; Holt follows
+// the recent level and slope more closely, so it reacts to a change of pace.
+const (
+	MethodLinear = "linear"
+	MethodHolt   = "holt"
+)
+
+// Directions a goal is reached in: up when the value has to rise to the
+// target, down when it has to fall to it.
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+const defaultHorizonDays = 5 * 365
+
+// Goal asks when a series reaches Target. For metric series Target and
+// Direction default to the metric's goal_value and goal_type; otherwise
+// Direction follows from where the target lies. HorizonDays is how far past
+// the last point the projection looks, five years by default.
+type Goal struct {
+	Series      SeriesQuery `json:"series"`
+	Target      *float64    `json:"target,omitempty"`
+	Direction   string      `json:"direction,omitempty"`
+	Method      string      `json:"method,omitempty"`
+	HorizonDays int         `json:"horizonDays,omitempty"`
+}
+
+// Projection is the value a model expects on a date, with its 95% range.
+type Projection struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+	Low   float64   `json:"low"`
+	High  float64   `json:"high"`
+}
+
+// Forecast says when a goal is expected to be reached. Current is the
+// model's value on the last day with data. Date is the day the projection
+// reaches the target, and Earliest and Latest the days its 95% range does;
+// each is nil when that is beyond the horizon, Date also when the series
+// moves away from the target.
+type Forecast struct {
+	Goal       Goal         `json:"goal"`
+	Unit       string       `json:"unit,omitempty"`
+	Target     float64      `json:"target"`
+	Current    Projection   `json:"current"`
+	PerWeek    float64      `json:"perWeek"`
+	Reached    bool         `json:"reached"`
+	Date       *time.Time   `json:"date"`
+	Earliest   *time.Time   `json:"earliest"`
+	Latest     *time.Time   `json:"latest"`
+	Projection []Projection `json:"projection"`
+}
+
+// model projects a series the given number of days past its last point,
+// returning the expected value and the half-width of its 95% range.
+type model struct {
+	last    time.Time
+	perDay  float64
+	project func(days float64) (float64, float64)
+}
+
+// ForecastGoal loads the goal's series, fits the goal's method to it and
+// projects when the target is reached.
+func ForecastGoal(goal Goal) (Forecast, error) {
+	goal, err := goal.withDefaults()
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	series, err := Load(goal.Series)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	var m model
+	var ok bool
+	switch goal.Method {
+	case MethodLinear:
+		m, ok = fitLinear(series.Points)
+	case MethodHolt:
+		m, ok = fitHolt(series.Points)
+	}
+	if !ok {
+		return Forecast{}, fmt.Errorf("forecasting needs at least three days of data, found %d", len(series.Points))
+	}
+
+	return project(goal, series.Unit, m), nil
+}
+
+func (g Goal) withDefaults() (Goal, error) {
+	if g.Method == "" {
+		g.Method = MethodLinear
+	}
+	if g.Method != MethodLinear && g.Method != MethodHolt {
+		return g, fmt.Errorf("unknown method %q, expected linear or holt", g.Method)
+	}
+	if g.HorizonDays == 0 {
+		g.HorizonDays = defaultHorizonDays
+	}
+	if g.HorizonDays < 1 {
+		return g, fmt.Errorf("horizon must be at least one day")
+	}
+	if g.Direction != "" && g.Direction != DirectionUp && g.Direction != DirectionDown {
+		return g, fmt.Errorf("unknown direction %q, expected up or down", g.Direction)
+	}
+
+	if g.Series.Kind == KindMetric && g.Series.Key != "" && (g.Target == nil || g.Direction == "") {
+		if err := g.metricGoal(); err != nil {
+			return g, err
+		}
+	}
+	if g.Target == nil {
+		return g, fmt.Errorf("a forecast needs a target")
+	}
+	return g, nil
+}
+
+// metricGoal fills in the target and direction from the metric's goal.
+func (g *Goal) metricGoal() error {
+	record, err := database.GetDataRecord(g.Series.Key, false)
+	if err != nil {
+		return err
+	}
+	var metric struct {
+		GoalValue *float64 `json:"goal_value"`
+		GoalType  string   `json:"goal_type"`
+	}
+	if err := json.Unmarshal(record.Data, &metric); err != nil {
+		return fmt.Errorf("invalid metric %s: %w", g.Series.Key, err)
+	}
+
+	if g.Target == nil {
+		g.Target = metric.GoalValue
+	}
+	if g.Direction == "" {
+		switch metric.GoalType {
+		case "minimum":
+			g.Direction = DirectionUp
+		case "maximum":
+			g.Direction = DirectionDown
+		}
+	}
+	return nil
+}
+
+func project(goal Goal, unit string, m model) Forecast {
+	target := *goal.Target
+	value, margin := m.project(0)
+	forecast := Forecast{
+		Goal:       goal,
+		Unit:       unit,
+		Target:     target,
+		Current:    Projection{Date: m.last, Value: value, Low: value - margin, High: value + margin},
+		PerWeek:    m.perDay * 7,
+		Projection: []Projection{},
+	}
+
+	direction := goal.Direction
+	if direction == "" {
+		direction = DirectionUp
+		if target < value {
+			direction = DirectionDown
+		}
+	}
+	reached := func(value float64) bool {
+		if direction == DirectionUp {
+			return value >= target
+		}
+		return value <= target
+	}
+	if reached(value) {
+		forecast.Reached = true
+		return forecast
+	}
+
+	// The range is reached first by its favourable edge.
+	optimistic, pessimistic := 1.0, -1.0
+	if direction == DirectionDown {
+		optimistic, pessimistic = -1, 1
+	}
+	at := func(days int) *time.Time {
+		date := m.last.AddDate(0, 0, days)
+		return &date
+	}
+	end := goal.HorizonDays
+	for days := 0; days <= goal.HorizonDays; days++ {
+		value, margin := m.project(float64(days))
+		if forecast.Earliest == nil && reached(value+optimistic*margin) {
+			forecast.Earliest = at(days)
+		}
+		if forecast.Date == nil && reached(value) {
+			forecast.Date = at(days)
+		}
+		if forecast.Latest == nil && reached(value+pessimistic*margin) {
+			forecast.Latest = at(days)
+			end = days
+			break
+		}
+	}
+
+	for days := 0; ; days += 7 {
+		days = min(days, end)
+		value, margin := m.project(float64(days))
+		forecast.Projection = append(forecast.Projection, Projection{
+			Date:  m.last.AddDate(0, 0, days),
+			Value: value,
+			Low:   value - margin,
+			High:  value + margin,
+		})
+		if days == end {
+			break
+		}
+	}
+
+	return forecast
+}
+
+// fitLinear extends the trend line, its range being the confidence interval
+// of the line itself rather than of single measurements.
+func fitLinear(points []Point) (model, bool) {
+	trend, ok := FitTrend(points)
+	if !ok {
+		return model{}, false
+	}
+
+	last := points[len(points)-1].Date
+	lastX := last.Sub(trend.Origin).Hours() / 24
+	t := tQuantile95(trend.Points - 2)
+	return model{
+		last:   last,
+		perDay: trend.Slope,
+		project: func(days float64) (float64, float64) {
+			x := lastX + days
+			dx := x - trend.meanX
+			return trend.at(x), t * trend.StdError * math.Sqrt(1/float64(trend.Points)+dx*dx/trend.sxx)
+		},
+	}, true
+}
+
+// holtState is Holt's linear smoothing over unevenly spaced points, the slope
+// being per day.
+type holtState struct {
+	level, slope float64
+	sse          float64
+	errors       int
+}
+
+// holtFactors are the smoothing factors fitHolt tries. Small ones keep a
+// steady slope, which noisy daily values usually need.
+var holtFactors = []float64{0.01, 0.02, 0.05, 0.1, 0.15, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+
+func runHolt(points []Point, alpha, beta float64) holtState {
+	gap := points[1].Date.Sub(points[0].Date).Hours() / 24
+	state := holtState{level: points[1].Value, slope: (points[1].Value - points[0].Value) / gap}
+	for i := 2; i < len(points); i++ {
+		gap := points[i].Date.Sub(points[i-1].Date).Hours() / 24
+		expected := state.level + state.slope*gap
+		residual := points[i].Value - expected
+		state.sse += residual * residual
+		state.errors++
+
+		level := alpha*points[i].Value + (1-alpha)*expected
+		state.slope = beta*(level-state.level)/gap + (1-beta)*state.slope
+		state.level = level
+	}
+	return state
+}
+
+// fitHolt picks the smoothing factors with the smallest one-step errors. The
+// range widens with the number of average gaps between points projected
+// ahead, following the variance of Holt's method for evenly spaced data.
+func fitHolt(points []Point) (model, bool) {
+	n := len(points)
+	if n < 3 {
+		return model{}, false
+	}
+
+	var best holtState
+	var alpha, beta float64
+	for _, a := range holtFactors {
+		for _, b := range holtFactors {
+			state := runHolt(points, a, b)
+			if alpha == 0 || state.sse < best.sse {
+				best, alpha, beta = state, a, b
+			}
+		}
+	}
+
+	last := points[n-1].Date
+	meanGap := last.Sub(points[0].Date).Hours() / 24 / float64(n-1)
+	sigma := math.Sqrt(best.sse / float64(best.errors))
+	return model{
+		last:   last,
+		perDay: best.slope,
+		project: func(days float64) (float64, float64) {
+			steps := math.Max(days/meanGap, 1)
+			variance := 1 + (steps-1)*(alpha*alpha+alpha*beta*steps+beta*beta*steps*(2*steps-1)/6)
+			return best.level + best.slope*days, z95 * sigma * math.Sqrt(variance)
+		},
+	}, true
+}
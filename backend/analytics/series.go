@@ -0,0 +1,375 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preset series. A query with a Kind needs no dataset, field or filter; Key
+// names the measurement, blood marker, account or metric where one is needed.
+// Net worth is the latest balance of every account summed, on each day a
+// balance was recorded; a Filter narrows the accounts.
+const (
+	KindWeight      = "weight"
+	KindBodyFat     = "body_fat"
+	KindLeanMass    = "lean_mass"
+	KindMeasurement = "measurement"
+	KindBloodMarker = "blood_marker"
+	KindBalance     = "balance"
+	KindMetric      = "metric"
+	KindNetWorth    = "net_worth"
+)
+
+// Ways values recorded on the same day are combined.
+const (
+	AggregateMean = "mean"
+	AggregateSum  = "sum"
+	AggregateMin  = "min"
+	AggregateMax  = "max"
+)
+
+// SeriesQuery selects a numeric field of a dataset, one point per day.
+// DateField defaults to the dataset's timeline date; a relation followed by
+// a field, such as blood_test_id.date, takes the date from the related
+// record. From and To are inclusive dates.
+type SeriesQuery struct {
+	Kind      string `json:"kind,omitempty"`
+	Key       string `json:"key,omitempty"`
+	DatasetID string `json:"datasetId,omitempty"`
+	Field     string `json:"field,omitempty"`
+	DateField string `json:"dateField,omitempty"`
+	Filter    string `json:"filter,omitempty"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	Aggregate string `json:"aggregate,omitempty"`
+}
+
+type Point struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+type Series struct {
+	Query  SeriesQuery `json:"query"`
+	Unit   string      `json:"unit,omitempty"`
+	Points []Point     `json:"points"`
+}
+
+// Resolve fills in the dataset, field and filter of a preset.
+func (q SeriesQuery) Resolve() (SeriesQuery, error) {
+	requireKey := func() error {
+		if strings.TrimSpace(q.Key) == "" {
+			return fmt.Errorf("series %s needs a key", q.Kind)
+		}
+		return nil
+	}
+
+	switch q.Kind {
+	case "":
+		if q.DatasetID == "" || q.Field == "" {
+			return q, fmt.Errorf("a series needs a kind, or a dataset and a field")
+		}
+	case KindWeight:
+		q.DatasetID, q.Field = database.DatasetIDBodyMeasurements, "value"
+		q.Filter = `measurement in ("Weight", "weight", "Bodyweight", "bodyweight", "Body Weight")`
+	case KindBodyFat:
+		q.DatasetID, q.Field = database.DatasetIDDEXA, "total_body_fat_percentage"
+	case KindLeanMass:
+		q.DatasetID, q.Field = database.DatasetIDDEXA, "lean_tissue_lbs"
+	case KindMeasurement:
+		if err := requireKey(); err != nil {
+			return q, err
+		}
+		q.DatasetID, q.Field = database.DatasetIDBodyMeasurements, "value"
+		q.Filter = "measurement = " + quote(q.Key)
+	case KindBloodMarker:
+		if err := requireKey(); err != nil {
+			return q, err
+		}
+		q.DatasetID, q.Field, q.DateField = database.DatasetIDBloodResult, "value_number", "blood_test_id.date"
+		q.Filter = "blood_marker_id = " + quote(q.Key)
+	case KindBalance:
+		if err := requireKey(); err != nil {
+			return q, err
+		}
+		q.DatasetID, q.Field = database.DatasetIDFinancialBalances, "amount"
+		q.Filter = "account_name = " + quote(q.Key)
+	case KindNetWorth:
+		q.DatasetID, q.Field = database.DatasetIDFinancialBalances, "amount"
+	case KindMetric:
+		if err := requireKey(); err != nil {
+			return q, err
+		}
+		q.DatasetID, q.Field = database.DatasetIDDailyLog, "value"
+		q.Filter = "metric_id = " + quote(q.Key)
+	default:
+		return q, fmt.Errorf("unknown series kind %q", q.Kind)
+	}
+
+	if q.Aggregate == "" {
+		q.Aggregate = AggregateMean
+	}
+	return q, nil
+}
+
+func quote(value string) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+// Load reads a series from the database, oldest point first. Records whose
+// value is not a number, such as an empty or text value, are left out;
+// booleans count as 1 and 0.
+func Load(query SeriesQuery) (Series, error) {
+	query, err := query.Resolve()
+	if err != nil {
+		return Series{}, err
+	}
+
+	dataset, err := database.GetDataset(query.DatasetID)
+	if err != nil {
+		return Series{}, err
+	}
+
+	fields := make(map[string]database.FieldDefinition)
+	for _, field := range dataset.Fields {
+		fields[field.Key] = field
+	}
+	valueField, ok := fields[query.Field]
+	if !ok {
+		return Series{}, fmt.Errorf("dataset %s has no field %q", dataset.ID, query.Field)
+	}
+
+	dateOf, err := dateReader(dataset, fields, query.DateField)
+	if err != nil {
+		return Series{}, err
+	}
+
+	from, to, err := dateRange(query.From, query.To)
+	if err != nil {
+		return Series{}, err
+	}
+
+	records, err := database.QueryDataRecords(dataset.ID, database.RecordQuery{Filter: query.Filter})
+	if err != nil {
+		return Series{}, err
+	}
+
+	groupOf := func(map[string]interface{}) string { return "" }
+	if query.Kind == KindNetWorth {
+		groupOf = func(data map[string]interface{}) string {
+			return fmt.Sprint(data["account_name"], "\x00", data["account_type"], "\x00", data["account_owner"])
+		}
+	}
+
+	days := make(map[string]map[time.Time][]float64)
+	for _, record := range records {
+		var data map[string]interface{}
+		if err := json.Unmarshal(record.Data, &data); err != nil {
+			continue
+		}
+		value, ok := number(data[query.Field])
+		if !ok {
+			continue
+		}
+		date, ok := dateOf(data)
+		if !ok {
+			continue
+		}
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if (!from.IsZero() && day.Before(from)) || (!to.IsZero() && day.After(to)) {
+			continue
+		}
+		group := groupOf(data)
+		if days[group] == nil {
+			days[group] = make(map[time.Time][]float64)
+		}
+		days[group][day] = append(days[group][day], value)
+	}
+
+	groups := make(map[string][]Point, len(days))
+	for group, values := range days {
+		points, err := aggregateDays(query.Aggregate, values)
+		if err != nil {
+			return Series{}, err
+		}
+		groups[group] = points
+	}
+
+	return Series{Query: query, Unit: valueField.Unit, Points: sumLatest(groups)}, nil
+}
+
+func aggregateDays(method string, days map[time.Time][]float64) ([]Point, error) {
+	points := make([]Point, 0, len(days))
+	for day, values := range days {
+		value, err := aggregate(method, values)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, Point{Date: day, Value: value})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Date.Before(points[j].Date)
+	})
+	return points, nil
+}
+
+// sumLatest adds up the groups on every day any of them has a point, each
+// group counting with its latest value up to that day.
+func sumLatest(groups map[string][]Point) []Point {
+	if len(groups) == 1 {
+		for _, points := range groups {
+			return points
+		}
+	}
+
+	var dates []time.Time
+	seen := make(map[time.Time]bool)
+	for _, points := range groups {
+		for _, point := range points {
+			if !seen[point.Date] {
+				seen[point.Date] = true
+				dates = append(dates, point.Date)
+			}
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	next := make(map[string]int, len(groups))
+	latest := make(map[string]float64, len(groups))
+	summed := make([]Point, 0, len(dates))
+	for _, date := range dates {
+		for group, points := range groups {
+			for next[group] < len(points) && !points[next[group]].Date.After(date) {
+				latest[group] = points[next[group]].Value
+				next[group]++
+			}
+		}
+		total := 0.0
+		for _, value := range latest {
+			total += value
+		}
+		summed = append(summed, Point{Date: date, Value: total})
+	}
+	return summed
+}
+
+// dateReader returns how to read a record's date, following a relation when
+// the date field is written as relation.field.
+func dateReader(dataset database.Dataset, fields map[string]database.FieldDefinition, dateField string) (func(map[string]interface{}) (time.Time, bool), error) {
+	if dateField == "" {
+		field, ok := database.TimelineDateField(dataset)
+		if !ok {
+			return nil, fmt.Errorf("dataset %s has no date field", dataset.ID)
+		}
+		dateField = field.Key
+	}
+
+	relationKey, relatedKey, related := strings.Cut(dateField, ".")
+	if !related {
+		if _, ok := fields[dateField]; !ok {
+			return nil, fmt.Errorf("dataset %s has no field %q", dataset.ID, dateField)
+		}
+		return func(data map[string]interface{}) (time.Time, bool) {
+			return database.ParseDateValue(data[dateField])
+		}, nil
+	}
+
+	relation, ok := fields[relationKey]
+	if !ok || !relation.IsRelation {
+		return nil, fmt.Errorf("dataset %s has no relation %q", dataset.ID, relationKey)
+	}
+
+	dates := make(map[string]*time.Time)
+	return func(data map[string]interface{}) (time.Time, bool) {
+		id, _ := data[relationKey].(string)
+		if id == "" {
+			return time.Time{}, false
+		}
+		if _, seen := dates[id]; !seen {
+			dates[id] = nil
+			if record, err := database.GetDataRecord(id, false); err == nil {
+				var relatedData map[string]interface{}
+				if json.Unmarshal(record.Data, &relatedData) == nil {
+					if date, ok := database.ParseDateValue(relatedData[relatedKey]); ok {
+						dates[id] = &date
+					}
+				}
+			}
+		}
+		if dates[id] == nil {
+			return time.Time{}, false
+		}
+		return *dates[id], true
+	}, nil
+}
+
+func dateRange(from string, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	for _, bound := range []struct {
+		name   string
+		value  string
+		target *time.Time
+	}{{"from", from, &start}, {"to", to, &end}} {
+		if strings.TrimSpace(bound.value) == "" {
+			continue
+		}
+		parsed, ok := database.ParseDateValue(bound.value)
+		if !ok {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid %s date %q", bound.name, bound.value)
+		}
+		*bound.target = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return start, end, nil
+}
+
+func aggregate(method string, values []float64) (float64, error) {
+	result := values[0]
+	switch method {
+	case AggregateMean, AggregateSum:
+		result = 0
+		for _, value := range values {
+			result += value
+		}
+		if method == AggregateMean {
+			result /= float64(len(values))
+		}
+	case AggregateMin:
+		for _, value := range values {
+			result = min(result, value)
+		}
+	case AggregateMax:
+		for _, value := range values {
+			result = max(result, value)
+		}
+	default:
+		return 0, fmt.Errorf("unknown aggregate %q", method)
+	}
+	return result, nil
+}
+
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		v = strings.TrimSpace(v)
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			return parsed, true
+		}
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			return number(parsed)
+		}
+	}
+	return 0, false
+}
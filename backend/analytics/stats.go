@@ -0,0 +1,254 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// Summary describes a set of values, with percentiles keyed like "p50".
+type Summary struct {
+	Count       int                `json:"count"`
+	Mean        float64            `json:"mean"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+// WindowStats summarizes the points in the window of days ending at Date.
+type WindowStats struct {
+	Date time.Time `json:"date"`
+	Summary
+}
+
+// PeriodChange is the mean of a calendar week or month and how it moved
+// from the period before that has points.
+type PeriodChange struct {
+	Start   time.Time `json:"start"`
+	Count   int       `json:"count"`
+	Mean    float64   `json:"mean"`
+	Change  *float64  `json:"change,omitempty"`
+	Percent *float64  `json:"percent,omitempty"`
+}
+
+// Trend is a least-squares line through the points, with the slope per day
+// and its 95% confidence interval. Significant is set when that interval
+// does not include zero.
+type Trend struct {
+	Points      int       `json:"points"`
+	Origin      time.Time `json:"origin"`
+	Intercept   float64   `json:"intercept"`
+	Slope       float64   `json:"slope"`
+	SlopeLow    float64   `json:"slopeLow"`
+	SlopeHigh   float64   `json:"slopeHigh"`
+	PerWeek     float64   `json:"perWeek"`
+	PerMonth    float64   `json:"perMonth"`
+	RSquared    float64   `json:"rSquared"`
+	StdError    float64   `json:"stdError"`
+	Significant bool      `json:"significant"`
+
+	meanX float64
+	sxx   float64
+}
+
+const daysPerMonth = 365.25 / 12
+
+// z95 is the two-sided 95% quantile of the normal distribution.
+const z95 = 1.959964
+
+// Summarize computes the count, mean, extremes and percentiles of values.
+func Summarize(values []float64, percentiles []float64) Summary {
+	summary := Summary{Count: len(values), Percentiles: make(map[string]float64)}
+	if len(values) == 0 {
+		return summary
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	total := 0.0
+	for _, value := range sorted {
+		total += value
+	}
+	summary.Mean = total / float64(len(sorted))
+	summary.Min = sorted[0]
+	summary.Max = sorted[len(sorted)-1]
+	for _, p := range percentiles {
+		summary.Percentiles[PercentileKey(p)] = Percentile(sorted, p)
+	}
+
+	return summary
+}
+
+func PercentileKey(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// Percentile interpolates between the closest ranks of sorted values, p
+// going from 0 to 100.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	rank := math.Max(0, math.Min(100, p)) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// Rolling summarizes, for every point, the points of the days-long window
+// ending on its date.
+func Rolling(points []Point, days int, percentiles []float64) []WindowStats {
+	windows := make([]WindowStats, 0, len(points))
+	start := 0
+	for i, point := range points {
+		for points[start].Date.Add(time.Duration(days)*day).Compare(point.Date) <= 0 {
+			start++
+		}
+		values := make([]float64, 0, i-start+1)
+		for _, inWindow := range points[start : i+1] {
+			values = append(values, inWindow.Value)
+		}
+		windows = append(windows, WindowStats{Date: point.Date, Summary: Summarize(values, percentiles)})
+	}
+	return windows
+}
+
+// Smooth is an exponential moving average. Alpha is the weight of a new
+// value one day after the last; longer gaps weigh the new value more, so
+// sparse series like DEXA scans are not dragged by old values.
+func Smooth(points []Point, alpha float64) []Point {
+	smoothed := make([]Point, 0, len(points))
+	for i, point := range points {
+		if i == 0 {
+			smoothed = append(smoothed, point)
+			continue
+		}
+		previous := smoothed[i-1]
+		gap := point.Date.Sub(previous.Date).Hours() / 24
+		weight := 1 - math.Pow(1-alpha, math.Max(gap, 1))
+		smoothed = append(smoothed, Point{Date: point.Date, Value: previous.Value + weight*(point.Value-previous.Value)})
+	}
+	return smoothed
+}
+
+// Changes groups points into calendar weeks, starting on Monday, or months.
+func Changes(points []Point, period string) []PeriodChange {
+	var changes []PeriodChange
+	var total float64
+	for _, point := range points {
+		start := periodStart(point.Date, period)
+		if len(changes) == 0 || !changes[len(changes)-1].Start.Equal(start) {
+			if len(changes) > 0 {
+				changes[len(changes)-1].Mean = total / float64(changes[len(changes)-1].Count)
+			}
+			changes = append(changes, PeriodChange{Start: start})
+			total = 0
+		}
+		changes[len(changes)-1].Count++
+		total += point.Value
+	}
+	if len(changes) == 0 {
+		return []PeriodChange{}
+	}
+	changes[len(changes)-1].Mean = total / float64(changes[len(changes)-1].Count)
+
+	for i := 1; i < len(changes); i++ {
+		change := changes[i].Mean - changes[i-1].Mean
+		changes[i].Change = &change
+		if changes[i-1].Mean != 0 {
+			percent := change / math.Abs(changes[i-1].Mean) * 100
+			changes[i].Percent = &percent
+		}
+	}
+	return changes
+}
+
+func periodStart(date time.Time, period string) time.Time {
+	if period == PeriodMonth {
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	offset := (int(date.Weekday()) + 6) % 7
+	return time.Date(date.Year(), date.Month(), date.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// FitTrend fits a line to the points, x being days since the first point.
+// It needs three points on at least two different days.
+func FitTrend(points []Point) (Trend, bool) {
+	n := len(points)
+	if n < 3 {
+		return Trend{}, false
+	}
+
+	trend := Trend{Points: n, Origin: points[0].Date}
+	x := make([]float64, n)
+	var meanY float64
+	for i, point := range points {
+		x[i] = point.Date.Sub(trend.Origin).Hours() / 24
+		trend.meanX += x[i]
+		meanY += point.Value
+	}
+	trend.meanX /= float64(n)
+	meanY /= float64(n)
+
+	var sxy, syy float64
+	for i, point := range points {
+		dx, dy := x[i]-trend.meanX, point.Value-meanY
+		trend.sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if trend.sxx == 0 {
+		return Trend{}, false
+	}
+
+	trend.Slope = sxy / trend.sxx
+	trend.Intercept = meanY - trend.Slope*trend.meanX
+	trend.PerWeek = trend.Slope * 7
+	trend.PerMonth = trend.Slope * daysPerMonth
+
+	var residuals float64
+	for i, point := range points {
+		residual := point.Value - trend.at(x[i])
+		residuals += residual * residual
+	}
+	if syy > 0 {
+		trend.RSquared = 1 - residuals/syy
+	} else {
+		trend.RSquared = 1
+	}
+	trend.StdError = math.Sqrt(residuals / float64(n-2))
+
+	margin := tQuantile95(n-2) * trend.StdError / math.Sqrt(trend.sxx)
+	trend.SlopeLow = trend.Slope - margin
+	trend.SlopeHigh = trend.Slope + margin
+	trend.Significant = trend.SlopeLow > 0 || trend.SlopeHigh < 0
+
+	return trend, true
+}
+
+func (t Trend) at(x float64) float64 {
+	return t.Intercept + t.Slope*x
+}
+
+// tValues95 are the two-sided 95% quantiles of Student's t distribution for
+// 1 to 30 degrees of freedom.
+var tValues95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+func tQuantile95(df int) float64 {
+	if df <= len(tValues95) {
+		return tValues95[df-1]
+	}
+	// Cornish-Fisher expansion around the normal quantile, within 0.001 from
+	// 30 degrees of freedom up.
+	return z95 + (z95*z95*z95+z95)/(4*float64(df))
+}
@@ -7,10 +7,13 @@ import (
 	"log"
 	"myproject/backend/database"
 	"myproject/backend/file"
+	"myproject/backend/server"
+	"myproject/backend/settings"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,62 +22,95 @@ import (
 type App struct {
 	ctx        context.Context
 	appDataDir string
+	filesRoot  string
+	dbPath     string
+	overrides  settings.Overrides
+	apiServer  *server.Server
+
+	settingsMu sync.Mutex
+	settings   settings.Settings
+
+	encryptionMu  sync.Mutex
+	encryptionJob EncryptionJob
+
+	// restoreMu is held while a backup is restored, and shared by the
+	// background work that reads the database or files meanwhile.
+	restoreMu sync.RWMutex
 }
 
-func NewApp() *App {
-	return &App{}
+func NewApp(overrides settings.Overrides) *App {
+	return &App{overrides: overrides}
 }
 
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 
-	appDir, err := getAppDataDir()
+	err := a.initialize()
 	if err != nil {
-		log.Println("Error getting app data directory:", err.Error())
+		log.Println("Error starting application:", err.Error())
 		return
 	}
 
+	go a.runScheduledBackups(ctx)
+
+	if a.currentSettings().Server.Enabled {
+		_, err = a.StartAPIServer()
+		if err != nil {
+			log.Println("Error starting API server:", err.Error())
+		}
+	}
+}
+
+func OpenHeadless(overrides settings.Overrides) (*App, error) {
+	app := NewApp(overrides)
+	app.ctx = context.Background()
+
+	err := app.initialize()
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (a *App) initialize() error {
+	appDir, err := getAppDataDir()
+	if err != nil {
+		return fmt.Errorf("error getting app data directory: %w", err)
+	}
+
 	a.appDataDir = appDir
 
-	err = file.Initialize(appDir)
+	loaded, err := settings.Load(appDir)
 	if err != nil {
-		log.Println("Error initializing file directory:", err.Error())
-		return
+		return fmt.Errorf("error loading settings: %w", err)
 	}
+	a.settingsMu.Lock()
+	a.settings = loaded
+	a.settingsMu.Unlock()
 
-	var dbPath string
-	var isDev bool
+	resolved := loaded.Resolve(appDir, a.overrides)
+	a.filesRoot = resolved.FilesLocation
+	a.dbPath = resolved.DatabasePath
 
-	executable, err := os.Executable()
-	if err == nil {
-		executableName := filepath.Base(executable)
-		isDev = strings.Contains(executableName, "wails-") || strings.Contains(executableName, "__debug_bin") || os.Getenv("BUILD_MODE") == "dev"
+	err = file.Initialize(a.filesRoot)
+	if err != nil {
+		return fmt.Errorf("error initializing file directory: %w", err)
+	}
 
-		if isDev {
-			log.Println("Running in development mode (detected via executable name):", executableName)
-			dbPath = filepath.Join(appDir, "DataDesktop-dev.db")
-		} else {
-			log.Println("Running in production mode with executable:", executableName)
-			dbPath = filepath.Join(appDir, "DataDesktop.db")
-		}
+	file.SetEncryptNewFiles(loaded.Encryption.Enabled)
+
+	if a.overrides.DevMode {
+		log.Println("Running in development mode")
 	} else {
-		if os.Getenv("BUILD_MODE") == "dev" {
-			log.Println("Running in development mode (detected via environment variable)")
-			isDev = true
-			dbPath = filepath.Join(appDir, "DataDesktop-dev.db")
-		} else {
-			log.Println("Running in production mode (fallback)")
-			isDev = false
-			dbPath = filepath.Join(appDir, "DataDesktop.db")
-		}
+		log.Println("Running in production mode")
 	}
+	log.Printf("Using database path: %s", resolved.DatabasePath)
 
-	log.Printf("Using database path: %s", dbPath)
-
-	err = database.Initialize(dbPath)
+	err = database.Initialize(resolved.DatabasePath, resolved.LockTimeoutMs)
 	if err != nil {
-		log.Println("Error initializing database:", err.Error())
-		return
+		return fmt.Errorf("error initializing database: %w", err)
 	}
 
 	err = database.SyncDatasets()
@@ -87,7 +123,12 @@ func (a *App) Startup(ctx context.Context) {
 		log.Println("Error cleaning up unused tables:", err.Error())
 	}
 
-	if isDev {
+	err = database.SyncDefaultRules()
+	if err != nil {
+		log.Println("Error synchronizing default rules:", err.Error())
+	}
+
+	if a.overrides.DevMode {
 		err = database.LoadSampleDataOnce()
 		if err != nil {
 			log.Println("Error loading sample data:", err.Error())
@@ -95,6 +136,25 @@ func (a *App) Startup(ctx context.Context) {
 			log.Println("Sample data loaded successfully")
 		}
 	}
+
+	err = database.RunMigrationOnce("record_tags", database.RebuildRecordTags)
+	if err != nil {
+		log.Println("Error rebuilding record tags:", err.Error())
+	}
+
+	err = a.syncFileStore()
+	if err != nil {
+		log.Println("Error synchronizing file references:", err.Error())
+	}
+
+	removed, err := file.CleanupStaleUploads(a.filesRoot, staleUploadAge)
+	if err != nil {
+		log.Println("Error cleaning up stale uploads:", err.Error())
+	} else if removed > 0 {
+		log.Printf("Removed %d stale upload session(s)", removed)
+	}
+
+	return nil
 }
 
 func getAppDataDir() (string, error) {
@@ -124,6 +184,11 @@ func getAppDataDir() (string, error) {
 }
 
 func (a *App) Shutdown(ctx context.Context) {
+	err := a.StopAPIServer()
+	if err != nil {
+		log.Println("Error stopping API server:", err.Error())
+	}
+
 	database.Close()
 }
 
@@ -186,7 +251,13 @@ func (a *App) UpdateDataset(id string, name string, description string, fields s
 }
 
 func (a *App) DeleteDataset(id string) error {
-	return database.DeleteDataset(id)
+	deleted, err := database.DeleteDataset(id)
+	if err != nil {
+		return err
+	}
+
+	a.releaseFiles(recordAttachments(deleted))
+	return nil
 }
 
 func (a *App) GetRecords(datasetID string, fetchImages bool) ([]map[string]interface{}, error) {
@@ -195,10 +266,25 @@ func (a *App) GetRecords(datasetID string, fetchImages bool) ([]map[string]inter
 		return nil, err
 	}
 
+	return recordMaps(records)
+}
+
+// GetRecordsSorted lists a dataset ordered by one of its fields instead of by
+// creation time.
+func (a *App) GetRecordsSorted(datasetID string, sortKey string, descending bool) ([]map[string]interface{}, error) {
+	records, err := database.GetDataRecordsSorted(datasetID, sortKey, descending)
+	if err != nil {
+		return nil, err
+	}
+
+	return recordMaps(records)
+}
+
+func recordMaps(records []database.DataRecord) ([]map[string]interface{}, error) {
 	result := make([]map[string]interface{}, len(records))
 	for i, record := range records {
 		var data map[string]interface{}
-		err = json.Unmarshal(record.Data, &data)
+		err := json.Unmarshal(record.Data, &data)
 		if err != nil {
 			return nil, err
 		}
@@ -234,34 +320,6 @@ func (a *App) GetRecord(id string, fetchRelatedData bool, fetchImages bool) (map
 	return data, nil
 }
 
-func (a *App) updateMetricLastOccurrence(metricID string, logDate time.Time) error {
-	metricRecord, err := database.GetDataRecord(metricID, false)
-	if err != nil {
-		return err
-	}
-
-	var metricData map[string]interface{}
-	err = json.Unmarshal(metricRecord.Data, &metricData)
-	if err != nil {
-		return err
-	}
-
-	frequency, _ := metricData["schedule_frequency"].(string)
-	if frequency != "interval" {
-		return nil
-	}
-
-	metricData["schedule_last_occurrence"] = logDate.Format(time.RFC3339)
-
-	updatedData, err := json.Marshal(metricData)
-	if err != nil {
-		return err
-	}
-
-	metricRecord.Data = updatedData
-	return database.UpdateDataRecord(metricRecord)
-}
-
 func (a *App) AddRecord(datasetID string, data string, fetchFiles bool) (map[string]interface{}, error) {
 	_, err := database.GetDataset(datasetID)
 	if err != nil {
@@ -290,7 +348,9 @@ func (a *App) AddRecord(datasetID string, data string, fetchFiles bool) (map[str
 		Data:      json.RawMessage(processedJSON),
 	}
 
-	err = database.AddDataRecord(record)
+	err = a.journaled("Add record", func(op *database.Operation) ([]string, error) {
+		return nil, database.AddDataRecord(op, record)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -329,7 +389,13 @@ func (a *App) UpdateRecord(id string, data string, fetchRelatedData bool, fetchF
 	record.Data = processedJSON
 	record.LastModified = time.Now()
 
-	err = database.UpdateDataRecord(record)
+	err = a.journaled("Edit record", func(op *database.Operation) ([]string, error) {
+		err := database.UpdateDataRecord(op, record)
+		if err != nil {
+			return nil, err
+		}
+		return removedReferences(oldData, processedData), nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -338,51 +404,37 @@ func (a *App) UpdateRecord(id string, data string, fetchRelatedData bool, fetchF
 }
 
 func (a *App) DeleteRecord(id string) error {
-	recordData, err := database.DeleteDataRecord(id)
-	if err != nil {
-		return err
-	}
+	return a.journaled("Delete record", func(op *database.Operation) ([]string, error) {
+		return a.deleteRecord(op, id)
+	})
+}
 
-	var data map[string]interface{}
-	err = json.Unmarshal(recordData, &data)
-	if err == nil {
-		a.deleteFilesInData(data)
+// deleteRecord deletes a record and its cascades, and returns their
+// attachments.
+func (a *App) deleteRecord(op *database.Operation, id string) ([]string, error) {
+	deleted, err := database.DeleteDataRecord(op, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return recordAttachments(deleted), nil
 }
 
-func (a *App) deleteFilesInData(data map[string]interface{}) {
-	for _, value := range data {
-		switch v := value.(type) {
-		case string:
-			if isFilePath(v) {
-				file.DeleteFile(a.appDataDir, v)
-			}
-		case map[string]interface{}:
-			if src, hasSrc := v["src"].(string); hasSrc {
-				if isFilePath(src) {
-					file.DeleteFile(a.appDataDir, src)
-				}
-			}
-			a.deleteFilesInData(v)
-		case []interface{}:
-			for _, item := range v {
-				if m, ok := item.(map[string]interface{}); ok {
-					if src, hasSrc := m["src"].(string); hasSrc {
-						if isFilePath(src) {
-							file.DeleteFile(a.appDataDir, src)
-						}
-					}
-					a.deleteFilesInData(m)
-				} else if s, ok := item.(string); ok {
-					if isFilePath(s) {
-						file.DeleteFile(a.appDataDir, s)
-					}
-				}
-			}
+func recordAttachments(records []database.DataRecord) []string {
+	var paths []string
+	for _, record := range records {
+		var data map[string]interface{}
+		if json.Unmarshal(record.Data, &data) == nil {
+			paths = append(paths, file.CollectReferences(data)...)
 		}
 	}
+	return paths
+}
+
+// PreviewDelete lists the records DeleteRecord would remove, cascades
+// included, the attachments it would delete and the records blocking it.
+func (a *App) PreviewDelete(recordID string) (database.DeleteImpact, error) {
+	return database.PreviewDelete(recordID)
 }
 
 type DuplicateResult struct {
@@ -498,7 +550,9 @@ func (a *App) ImportRecords(datasetID string, records string) (int, error) {
 		}
 	}
 
-	err = database.ImportRecords(dbRecords)
+	err = a.journaled("Import records", func(op *database.Operation) ([]string, error) {
+		return nil, database.ImportRecords(op, dbRecords)
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -558,11 +612,7 @@ func (a *App) processGenericArray(arr []interface{}, prefix string) ([]interface
 }
 
 func (a *App) UploadFile(base64File string, prefix string, fileName string) (string, error) {
-	if base64File == "" {
-		return "", nil
-	}
-
-	return file.SaveFile(a.appDataDir, base64File, prefix, fileName)
+	return a.UploadFileWithName(base64File, prefix, fileName)
 }
 
 func (a *App) GetFilePath(relativePath string) (string, error) {
@@ -570,10 +620,13 @@ func (a *App) GetFilePath(relativePath string) (string, error) {
 		return "", nil
 	}
 
-	fullPath := file.GetFilePath(a.appDataDir, relativePath)
+	fullPath, err := file.GetFilePath(a.filesRoot, relativePath)
+	if err != nil {
+		return "", err
+	}
 
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("file does not exist")
+		return "", &file.PathError{Path: relativePath, Err: file.ErrNotFound}
 	}
 
 	return fullPath, nil
@@ -584,18 +637,23 @@ func (a *App) GetFileAsBase64(relativePath string) (string, error) {
 		return "", nil
 	}
 
-	return file.GetFileAsBase64(a.appDataDir, relativePath)
+	return file.GetFileAsBase64(a.filesRoot, relativePath)
 }
 
 func (a *App) DeleteFile(relativePath string) error {
-	return file.DeleteFile(a.appDataDir, relativePath)
+	if _, err := file.CleanPath(relativePath); err != nil {
+		return err
+	}
+
+	a.releaseFiles([]string{relativePath})
+	return nil
 }
 
 func (a *App) ProcessRecord(record map[string]interface{}, fetchFiles bool) error {
 	if fetchFiles {
 		for key, value := range record {
 			if filePath, ok := value.(string); ok && isFilePath(filePath) {
-				base64File, err := file.GetFileAsBase64(a.appDataDir, filePath)
+				base64File, err := file.GetFileAsBase64(a.filesRoot, filePath)
 				if err == nil {
 					record[key] = base64File
 				}
@@ -611,7 +669,20 @@ func (a *App) UploadFileWithName(base64File string, prefix string, fileName stri
 		return "", nil
 	}
 
-	return file.SaveFile(a.appDataDir, base64File, prefix, fileName)
+	saved, err := file.SaveFile(a.filesRoot, base64File, fileName, a.currentSettings().Attachments.StripPhotoMetadata)
+	if err != nil {
+		return "", err
+	}
+	relativePath := saved.Path
+
+	err = a.registerFile(relativePath, fileName, saved.Image)
+	if err != nil {
+		return "", fmt.Errorf("failed to register file: %w", err)
+	}
+
+	a.pregenerateThumbnails(relativePath)
+
+	return relativePath, nil
 }
 
 func (a *App) SaveFiles(data interface{}, prefix string) (interface{}, error) {
@@ -657,6 +728,9 @@ func (a *App) processFilesRecursive(data interface{}, prefix string) (interface{
 			}
 			return filePath, nil
 		}
+		if err := checkFileReference(v); err != nil {
+			return nil, err
+		}
 		return v, nil
 
 	default:
@@ -689,7 +763,7 @@ func (a *App) ProcessRecordWithFiles(record map[string]interface{}, fetchFiles b
 	if fetchFiles {
 		for key, value := range record {
 			if filePath, ok := value.(string); ok && isFilePath(filePath) {
-				base64File, err := file.GetFileAsBase64(a.appDataDir, filePath)
+				base64File, err := file.GetFileAsBase64(a.filesRoot, filePath)
 				if err == nil {
 					record[key] = base64File
 				}
@@ -700,7 +774,7 @@ func (a *App) ProcessRecordWithFiles(record map[string]interface{}, fetchFiles b
 					if itemMap, ok := item.(map[string]interface{}); ok {
 						for itemKey, itemValue := range itemMap {
 							if filePath, ok := itemValue.(string); ok && isFilePath(filePath) {
-								base64File, err := file.GetFileAsBase64(a.appDataDir, filePath)
+								base64File, err := file.GetFileAsBase64(a.filesRoot, filePath)
 								if err == nil {
 									itemMap[itemKey] = base64File
 									array[i] = itemMap
@@ -721,11 +795,18 @@ func (a *App) ProcessRecordWithFiles(record map[string]interface{}, fetchFiles b
 }
 
 func isFilePath(path string) bool {
-	if path == "" || strings.HasPrefix(path, "data:") {
-		return false
+	return file.IsFileReference(path)
+}
+
+// checkFileReference refuses record values that look like stored files but
+// point outside files/, so they are never counted, served or released.
+func checkFileReference(value string) error {
+	if !isFilePath(value) {
+		return nil
 	}
 
-	return strings.HasPrefix(path, file.FilesDir+"/")
+	_, err := file.CleanPath(value)
+	return err
 }
 
 func (a *App) processDataWithExistingFiles(oldData, newData map[string]interface{}, prefix string) (map[string]interface{}, error) {
@@ -747,7 +828,7 @@ func (a *App) processDataWithExistingFiles(oldData, newData map[string]interface
 
 		case []interface{}:
 			if isFileArray(v) {
-				processedArray, err := a.processFileArray(v, oldData[key], prefix+"-"+key)
+				processedArray, err := a.processFileArray(v, prefix+"-"+key)
 				if err != nil {
 					return nil, err
 				}
@@ -770,12 +851,6 @@ func (a *App) processDataWithExistingFiles(oldData, newData map[string]interface
 						return nil, err
 					}
 
-					if oldFilePath, ok := oldData[key].(string); ok && oldFilePath != "" && !strings.HasPrefix(oldFilePath, "data:") {
-						if isFilePath(oldFilePath) {
-							_ = file.DeleteFile(a.appDataDir, oldFilePath)
-						}
-					}
-
 					result[key] = filePath
 				} else {
 					filePath, err := a.UploadFileWithName(v, prefix+"-"+key, "")
@@ -783,15 +858,12 @@ func (a *App) processDataWithExistingFiles(oldData, newData map[string]interface
 						return nil, err
 					}
 
-					if oldFilePath, ok := oldData[key].(string); ok && oldFilePath != "" && !strings.HasPrefix(oldFilePath, "data:") {
-						if isFilePath(oldFilePath) {
-							_ = file.DeleteFile(a.appDataDir, oldFilePath)
-						}
-					}
-
 					result[key] = filePath
 				}
 			} else {
+				if err := checkFileReference(v); err != nil {
+					return nil, err
+				}
 				result[key] = v
 			}
 
@@ -823,24 +895,11 @@ func isFileArray(arr []interface{}) bool {
 	return false
 }
 
-func (a *App) processFileArray(arr []interface{}, oldArr interface{}, prefix string) ([]interface{}, error) {
+func (a *App) processFileArray(arr []interface{}, prefix string) ([]interface{}, error) {
 	result := make([]interface{}, len(arr))
-	oldItems := make(map[string]interface{})
-
-	if oldArrSlice, ok := oldArr.([]interface{}); ok {
-		for _, oldItem := range oldArrSlice {
-			if oldObj, isObj := oldItem.(map[string]interface{}); isObj {
-				if id, hasID := oldObj["id"].(string); hasID {
-					oldItems[id] = oldObj
-				}
-			}
-		}
-	}
 
 	for i, item := range arr {
 		if obj, ok := item.(map[string]interface{}); ok {
-			id, hasID := obj["id"].(string)
-
 			if src, ok := obj["src"].(string); ok && strings.HasPrefix(src, "data:") {
 				fileName := ""
 				if name, hasName := obj["name"].(string); hasName {
@@ -852,19 +911,11 @@ func (a *App) processFileArray(arr []interface{}, oldArr interface{}, prefix str
 					return nil, err
 				}
 
-				if hasID {
-					if oldObj, exists := oldItems[id]; exists {
-						if oldItemObj, isObj := oldObj.(map[string]interface{}); isObj {
-							if oldSrc, hasSrc := oldItemObj["src"].(string); hasSrc && oldSrc != "" && !strings.HasPrefix(oldSrc, "data:") {
-								if isFilePath(oldSrc) {
-									_ = file.DeleteFile(a.appDataDir, oldSrc)
-								}
-							}
-						}
-					}
-				}
-
 				obj["src"] = filePath
+			} else if ok {
+				if err := checkFileReference(src); err != nil {
+					return nil, err
+				}
 			}
 
 			result[i] = obj
@@ -876,12 +927,8 @@ func (a *App) processFileArray(arr []interface{}, oldArr interface{}, prefix str
 	return result, nil
 }
 
-func (a *App) UploadFileChunk(chunkData string, fileName string, chunkIndex int, totalChunks int, sessionId string) (string, error) {
-	return file.UploadFileInChunks(a.appDataDir, chunkData, fileName, chunkIndex, totalChunks, sessionId)
-}
-
 func (a *App) ResetAllData() error {
-	return database.ResetAllData(a.appDataDir)
+	return database.ResetAllData(a.filesRoot)
 }
 
 func (a *App) LoadSampleData() error {
@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"myproject/backend/database"
+	"myproject/backend/server"
+	"time"
+)
+
+func (a *App) SearchRecords(query string, datasetIDs []string) ([]map[string]interface{}, error) {
+	records, err := database.SearchRecords(query, datasetIDs, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		var data map[string]interface{}
+		err = json.Unmarshal(record.Data, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		data["id"] = record.ID
+		data["datasetId"] = record.DatasetID
+		data["createdAt"] = record.CreatedAt
+		data["lastModified"] = record.LastModified
+
+		result[i] = data
+	}
+
+	return result, nil
+}
+
+func (a *App) StartAPIServer() (server.Status, error) {
+	if a.apiServer == nil {
+		a.apiServer = server.New(a)
+	}
+
+	err := a.apiServer.Start(a.currentSettings().Server.Port)
+	if err != nil {
+		return server.Status{}, err
+	}
+
+	return a.apiServer.Status(), nil
+}
+
+func (a *App) StopAPIServer() error {
+	if a.apiServer == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return a.apiServer.Stop(ctx)
+}
+
+func (a *App) GetAPIServerStatus() server.Status {
+	if a.apiServer == nil {
+		return server.Status{}
+	}
+
+	return a.apiServer.Status()
+}
+
+func (a *App) CreateAPIToken(name string, allowPrivate bool) (database.CreatedAPIToken, error) {
+	return database.CreateAPIToken(name, allowPrivate)
+}
+
+func (a *App) ListAPITokens() ([]database.APIToken, error) {
+	return database.ListAPITokens()
+}
+
+func (a *App) RevokeAPIToken(id string) error {
+	return database.RevokeAPIToken(id)
+}
@@ -0,0 +1,160 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"myproject/backend/backup"
+	"myproject/backend/database"
+	"myproject/backend/file"
+	"myproject/backend/settings"
+	"time"
+)
+
+func (a *App) CreateBackup() (backup.Info, error) {
+	current := a.currentSettings()
+	resolved := current.Resolve(a.appDataDir, a.overrides)
+
+	info, err := backup.Create(resolved.Backup.Directory, a.filesRoot, current.Encryption)
+	if err != nil {
+		return backup.Info{}, err
+	}
+
+	err = backup.Prune(resolved.Backup.Directory, resolved.Backup.Retention)
+	if err != nil {
+		log.Println("Error pruning old backups:", err.Error())
+	}
+
+	return info, nil
+}
+
+func (a *App) CreateBackupIn(destDir string) (backup.Info, error) {
+	if destDir == "" {
+		return a.CreateBackup()
+	}
+
+	err := ensureWritableDir(destDir)
+	if err != nil {
+		return backup.Info{}, err
+	}
+
+	return backup.Create(destDir, a.filesRoot, a.currentSettings().Encryption)
+}
+
+func (a *App) ListBackups() ([]backup.Info, error) {
+	resolved := a.currentSettings().Resolve(a.appDataDir, a.overrides)
+	return backup.List(resolved.Backup.Directory)
+}
+
+func (a *App) RestoreBackup(archivePath string) error {
+	if archivePath == "" {
+		return fmt.Errorf("no backup selected")
+	}
+
+	if !a.restoreMu.TryLock() {
+		return errors.New("files are being converted or backed up, try again once that has finished")
+	}
+	defer a.restoreMu.Unlock()
+
+	// The API server would otherwise serve requests from a closed database.
+	serverRunning := a.GetAPIServerStatus().Running
+	if serverRunning {
+		if err := a.StopAPIServer(); err != nil {
+			return fmt.Errorf("failed to stop API server: %w", err)
+		}
+	}
+
+	restoreErr := a.restore(archivePath)
+
+	if serverRunning {
+		if _, err := a.StartAPIServer(); err != nil {
+			restoreErr = errors.Join(restoreErr, fmt.Errorf("failed to restart API server: %w", err))
+		}
+	}
+
+	return restoreErr
+}
+
+func (a *App) restore(archivePath string) error {
+	err := database.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+
+	encryption, restoreErr := backup.Restore(archivePath, a.dbPath, a.filesRoot)
+	if restoreErr == nil && encryption != nil {
+		restoreErr = a.restoreVault(*encryption)
+	}
+
+	err = a.initialize()
+	if err != nil {
+		return errors.Join(restoreErr, fmt.Errorf("failed to reopen database: %w", err))
+	}
+
+	return restoreErr
+}
+
+// restoreVault takes over the vault settings of a restored backup, whose files
+// need its salt to be read. A vault with a different salt is locked until it
+// is unlocked with its own passphrase.
+func (a *App) restoreVault(encryption settings.EncryptionSettings) error {
+	var previous settings.EncryptionSettings
+	_, err := a.updateSettings(func(updated *settings.Settings) error {
+		previous = updated.Encryption
+		updated.Encryption = encryption
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore vault settings: %w", err)
+	}
+
+	if previous.Salt != encryption.Salt {
+		file.LockVault()
+	}
+	return nil
+}
+
+func (a *App) runScheduledBackups(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		a.backupIfDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *App) backupIfDue() {
+	a.restoreMu.RLock()
+	defer a.restoreMu.RUnlock()
+
+	resolved := a.currentSettings().Resolve(a.appDataDir, a.overrides)
+	if !resolved.Backup.Enabled {
+		return
+	}
+
+	backups, err := backup.List(resolved.Backup.Directory)
+	if err != nil {
+		log.Println("Error listing backups:", err.Error())
+		return
+	}
+
+	interval := time.Duration(resolved.Backup.IntervalHours) * time.Hour
+	if len(backups) > 0 && time.Since(backups[0].CreatedAt) < interval {
+		return
+	}
+
+	info, err := a.CreateBackup()
+	if err != nil {
+		log.Println("Error creating scheduled backup:", err.Error())
+		return
+	}
+
+	log.Printf("Created scheduled backup: %s", info.Path)
+}
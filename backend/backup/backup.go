@@ -0,0 +1,385 @@
+package backup
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"myproject/backend/database"
+	"myproject/backend/file"
+	"myproject/backend/settings"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	archivePrefix    = "DataDesktop-backup-"
+	archiveExtension = ".zip"
+	databaseEntry    = "DataDesktop.db"
+	manifestEntry    = "manifest.json"
+	timestampFormat  = "20060102-150405"
+)
+
+type Info struct {
+	Path      string    `json:"path"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// manifest describes an archive. Encryption holds the vault settings when
+// files were encrypted, since the files cannot be read without its salt.
+type manifest struct {
+	CreatedAt  time.Time                    `json:"createdAt"`
+	FileCount  int                          `json:"fileCount"`
+	Encryption *settings.EncryptionSettings `json:"encryption,omitempty"`
+}
+
+func Create(destDir string, filesRoot string, encryption settings.EncryptionSettings) (Info, error) {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return Info{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	now := time.Now()
+	archivePath := filepath.Join(destDir, archivePrefix+now.Format(timestampFormat)+archiveExtension)
+
+	tempDir, err := os.MkdirTemp(destDir, ".backup-*")
+	if err != nil {
+		return Info{}, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	snapshotPath := filepath.Join(tempDir, databaseEntry)
+	err = database.BackupTo(snapshotPath)
+	if err != nil {
+		return Info{}, err
+	}
+
+	partialPath := archivePath + ".partial"
+	contents := manifest{CreatedAt: now}
+	if encryption.Salt != "" {
+		contents.Encryption = &encryption
+	}
+	err = writeArchive(partialPath, snapshotPath, filesRoot, contents)
+	if err != nil {
+		os.Remove(partialPath)
+		return Info{}, err
+	}
+
+	if err := os.Rename(partialPath, archivePath); err != nil {
+		return Info{}, err
+	}
+
+	return stat(archivePath)
+}
+
+func writeArchive(archivePath string, snapshotPath string, filesRoot string, contents manifest) error {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create backup archive: %w", err)
+	}
+	defer out.Close()
+
+	archive := zip.NewWriter(out)
+
+	err = addFile(archive, databaseEntry, snapshotPath)
+	if err != nil {
+		return err
+	}
+
+	filesDir := filepath.Join(filesRoot, file.FilesDir)
+	err = filepath.WalkDir(filesDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(filesRoot, path)
+		if err != nil {
+			return err
+		}
+
+		contents.FileCount++
+		return addFile(archive, filepath.ToSlash(relativePath), path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive files: %w", err)
+	}
+
+	manifestJSON, err := json.MarshalIndent(contents, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	writer, err := archive.Create(manifestEntry)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(manifestJSON); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+func addFile(archive *zip.Writer, name string, path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, source)
+	return err
+}
+
+// Restore replaces the database and files with the archive's. It returns the
+// vault settings the restored files were encrypted with, or nil when the
+// backup has none.
+func Restore(archivePath string, dbPath string, filesRoot string) (*settings.EncryptionSettings, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup archive: %w", err)
+	}
+	defer reader.Close()
+
+	hasDatabase := false
+	var contents manifest
+	for _, entry := range reader.File {
+		switch entry.Name {
+		case databaseEntry:
+			hasDatabase = true
+		case manifestEntry:
+			if err := readManifest(entry, &contents); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !hasDatabase {
+		return nil, errors.New("backup archive does not contain a database")
+	}
+
+	stagingDir, err := os.MkdirTemp(filesRoot, ".restore-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() || entry.Name == manifestEntry {
+			continue
+		}
+
+		if entry.Name != databaseEntry && !strings.HasPrefix(entry.Name, file.FilesDir+"/") {
+			return nil, fmt.Errorf("unexpected entry in backup archive: %s", entry.Name)
+		}
+
+		target := filepath.Join(stagingDir, filepath.FromSlash(entry.Name))
+		if !strings.HasPrefix(target, stagingDir+string(os.PathSeparator)) {
+			return nil, fmt.Errorf("invalid entry path in backup archive: %s", entry.Name)
+		}
+
+		err = extractFile(entry, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filesDir := filepath.Join(filesRoot, file.FilesDir)
+	stagedFilesDir := filepath.Join(stagingDir, file.FilesDir)
+	if err := os.MkdirAll(stagedFilesDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, err
+	}
+
+	// The current database and files are moved aside first, so a failure
+	// puts both back rather than leaving the restore half-applied.
+	previousDatabase := filepath.Join(stagingDir, "previous-database")
+	databaseFiles := []string{"", "-wal", "-shm"}
+	restoreDatabase := func() {
+		os.Remove(dbPath)
+		for _, suffix := range databaseFiles {
+			moveFile(previousDatabase+suffix, dbPath+suffix)
+		}
+	}
+	for _, suffix := range databaseFiles {
+		err := moveFile(dbPath+suffix, previousDatabase+suffix)
+		if err == nil {
+			err = os.Remove(dbPath + suffix)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			restoreDatabase()
+			return nil, fmt.Errorf("failed to move existing database aside: %w", err)
+		}
+	}
+
+	previousFilesDir := filepath.Join(stagingDir, "previous-files")
+	if err := os.Rename(filesDir, previousFilesDir); err != nil && !os.IsNotExist(err) {
+		restoreDatabase()
+		return nil, fmt.Errorf("failed to move existing files aside: %w", err)
+	}
+	restoreFiles := func() {
+		os.RemoveAll(filesDir)
+		os.Rename(previousFilesDir, filesDir)
+	}
+
+	if err := os.Rename(stagedFilesDir, filesDir); err != nil {
+		restoreFiles()
+		restoreDatabase()
+		return nil, fmt.Errorf("failed to restore files: %w", err)
+	}
+
+	if err := moveFile(filepath.Join(stagingDir, databaseEntry), dbPath); err != nil {
+		restoreFiles()
+		restoreDatabase()
+		return nil, fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	return contents.Encryption, nil
+}
+
+func readManifest(entry *zip.File, contents *manifest) error {
+	source, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	if err := json.NewDecoder(source).Decode(contents); err != nil {
+		return fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	return nil
+}
+
+func extractFile(entry *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	source, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, source); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+	}
+
+	return out.Close()
+}
+
+func moveFile(source string, target string) error {
+	if err := os.Rename(source, target); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Info{}, nil
+		}
+		return nil, err
+	}
+
+	backups := []Info{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, archivePrefix) || !strings.HasSuffix(name, archiveExtension) {
+			continue
+		}
+
+		info, err := stat(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, info)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+func Prune(dir string, retention int) error {
+	backups, err := List(dir)
+	if err != nil {
+		return err
+	}
+
+	for i := retention; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return fmt.Errorf("failed to remove old backup %s: %w", backups[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+func stat(path string) (Info, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+
+	name := filepath.Base(path)
+	createdAt, err := time.ParseInLocation(timestampFormat, strings.TrimSuffix(strings.TrimPrefix(name, archivePrefix), archiveExtension), time.Local)
+	if err != nil {
+		createdAt = fileInfo.ModTime()
+	}
+
+	return Info{
+		Path:      path,
+		Name:      name,
+		Size:      fileInfo.Size(),
+		CreatedAt: createdAt,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"myproject/backend"
+	"myproject/backend/settings"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	summary string
+	run     func(app *backend.App, args []string, out io.Writer) error
+}
+
+var commands = map[string]command{
+	"export":     {summary: "export dataset records as JSON or CSV", run: runExport},
+	"import":     {summary: "import records from a JSON or CSV file", run: runImport},
+	"backup":     {summary: "write a backup archive of the database and files", run: runBackup},
+	"restore":    {summary: "restore the database and files from a backup archive", run: runRestore},
+	"query":      {summary: "print records of a dataset, optionally filtered", run: runQuery},
+	"add-record": {summary: "add a single record to a dataset", run: runAddRecord},
+	"update":     {summary: "change fields on every record matching a filter", run: runUpdate},
+	"delete":     {summary: "delete every record matching a filter", run: runDelete},
+	"view":       {summary: "list saved views or print the records of one", run: runView},
+	"tags":       {summary: "list tags with their usage, or rename, merge or delete one", run: runTags},
+	"verify":     {summary: "check the database and attachments, optionally repairing them", run: runVerify},
+	"generate":   {summary: "fill the datasets with seeded synthetic data, or remove it again", run: runGenerate},
+}
+
+var errUsage = errors.New("usage")
+
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok || name == "help"
+}
+
+func Run(args []string, overrides settings.Overrides) int {
+	if len(args) == 0 || args[0] == "help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	if os.Getenv("DATADESKTOP_VERBOSE") == "" {
+		log.SetOutput(io.Discard)
+	}
+
+	app, err := backend.OpenHeadless(overrides)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	defer app.Shutdown(context.Background())
+
+	err = cmd.run(app, args[1:], os.Stdout)
+	if err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	return 0
+}
+
+func printUsage(out io.Writer) {
+	fmt.Fprintln(out, "Usage: DataDesktop [global flags] <command> [flags]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-12s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Global flags: --dev, --db-path, --files-location, --lock-timeout-ms, --unit-system, --backup-dir")
+	fmt.Fprintln(out, "Run 'DataDesktop <command> -h' for command flags.")
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	return flags
+}
+
+func requireFlag(flags *flag.FlagSet, name string, value string) error {
+	if value == "" {
+		fmt.Fprintf(os.Stderr, "%s: --%s is required\n", flags.Name(), name)
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func formatFromPath(format string, path string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".csv") {
+		return formatCSV
+	}
+	return formatJSON
+}
+
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *multiFlag) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
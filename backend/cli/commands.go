@@ -0,0 +1,644 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"myproject/backend"
+	"myproject/backend/database"
+	"myproject/backend/generator"
+	"myproject/backend/integrity"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func runExport(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("export")
+	datasetID := flags.String("dataset", "", "dataset to export (all datasets when omitted)")
+	format := flags.String("format", "", "output format: json or csv")
+	output := flags.String("output", "", "output file, or directory when exporting all datasets as CSV (default stdout)")
+	filter := flags.String("filter", "", "only export records matching this filter expression (needs --dataset)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *filter != "" {
+		if err := requireFlag(flags, "dataset", *datasetID); err != nil {
+			return err
+		}
+	}
+
+	outputFormat := formatFromPath(*format, *output)
+
+	if *datasetID != "" {
+		dataset, err := app.GetDataset(*datasetID)
+		if err != nil {
+			return err
+		}
+
+		records, err := queryRecords(app, dataset.ID, map[string]interface{}{"filter": *filter})
+		if err != nil {
+			return err
+		}
+
+		return withOutput(*output, out, func(w io.Writer) error {
+			return writeRecords(w, outputFormat, dataset, records)
+		})
+	}
+
+	datasets, err := app.GetDatasets()
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == formatCSV {
+		if *output == "" {
+			return fmt.Errorf("exporting every dataset as CSV needs --output pointing at a directory")
+		}
+		if err := os.MkdirAll(*output, 0755); err != nil {
+			return err
+		}
+
+		for _, dataset := range datasets {
+			records, err := app.GetRecords(dataset.ID, false)
+			if err != nil {
+				return err
+			}
+
+			err = withOutput(filepath.Join(*output, dataset.ID+".csv"), out, func(w io.Writer) error {
+				return writeCSV(w, dataset, records)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	export := make(map[string][]map[string]interface{})
+	for _, dataset := range datasets {
+		records, err := app.GetRecords(dataset.ID, false)
+		if err != nil {
+			return err
+		}
+		export[dataset.ID] = records
+	}
+
+	return withOutput(*output, out, func(w io.Writer) error {
+		return writeJSON(w, export)
+	})
+}
+
+func runImport(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("import")
+	datasetID := flags.String("dataset", "", "dataset to import into")
+	format := flags.String("format", "", "input format: json or csv (default from file extension)")
+	input := flags.String("file", "", "input file, or - for stdin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "dataset", *datasetID); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "file", *input); err != nil {
+		return err
+	}
+
+	dataset, err := app.GetDataset(*datasetID)
+	if err != nil {
+		return err
+	}
+
+	var records []map[string]interface{}
+	err = withInput(*input, func(r io.Reader) error {
+		records, err = readRecords(r, formatFromPath(*format, *input), dataset)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	recordsJSON, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	count, err := app.ImportRecords(dataset.ID, string(recordsJSON))
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(out, map[string]interface{}{"dataset": dataset.ID, "imported": count})
+}
+
+func runBackup(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("backup")
+	output := flags.String("output", "", "directory to write the backup to (default from settings)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	info, err := app.CreateBackupIn(*output)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(out, info)
+}
+
+func runRestore(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("restore")
+	input := flags.String("file", "", "backup archive to restore")
+	force := flags.Bool("force", false, "replace the current database and files without asking")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "file", *input); err != nil {
+		return err
+	}
+	if !*force {
+		return fmt.Errorf("restoring replaces the current database and files; pass --force to continue")
+	}
+
+	err := app.RestoreBackup(*input)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(out, map[string]interface{}{"restored": *input})
+}
+
+func runQuery(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("query")
+	datasetID := flags.String("dataset", "", "dataset to query")
+	format := flags.String("format", formatJSON, "output format: json or csv")
+	limit := flags.Int("limit", 0, "maximum number of records to print (0 for all)")
+	sortKey := flags.String("sort", "", "field to order by, prefixed with - for descending")
+	filter := flags.String("filter", "", `filter expression, e.g. 'date >= 2024-01-01 and amount > 50'`)
+	var where multiFlag
+	flags.Var(&where, "where", "key=value filter, may be repeated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "dataset", *datasetID); err != nil {
+		return err
+	}
+
+	dataset, err := app.GetDataset(*datasetID)
+	if err != nil {
+		return err
+	}
+
+	filters := make(map[string]string)
+	for _, condition := range where {
+		parts := strings.SplitN(condition, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid --where %q, expected key=value", condition)
+		}
+		filters[parts[0]] = parts[1]
+	}
+
+	query := map[string]interface{}{
+		"filter":     *filter,
+		"sort":       strings.TrimPrefix(*sortKey, "-"),
+		"descending": strings.HasPrefix(*sortKey, "-"),
+	}
+	if len(filters) == 0 {
+		query["limit"] = *limit
+	}
+	records, err := queryRecords(app, dataset.ID, query)
+	if err != nil {
+		return err
+	}
+
+	matched := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		if !matchesFilters(record, filters) {
+			continue
+		}
+		matched = append(matched, record)
+		if *limit > 0 && len(matched) >= *limit {
+			break
+		}
+	}
+
+	return writeRecords(out, strings.ToLower(*format), dataset, matched)
+}
+
+func queryRecords(app *backend.App, datasetID string, query map[string]interface{}) ([]map[string]interface{}, error) {
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	return app.QueryRecords(datasetID, string(queryJSON))
+}
+
+func matchesFilters(record map[string]interface{}, filters map[string]string) bool {
+	for key, expected := range filters {
+		if csvValue(record[key]) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func runUpdate(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("update")
+	datasetID := flags.String("dataset", "", "dataset to update")
+	filter := flags.String("filter", "", "filter expression selecting the records to change")
+	var set multiFlag
+	flags.Var(&set, "set", "key=value to set, where value is JSON or plain text (repeatable)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "dataset", *datasetID); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "filter", *filter); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "set", strings.Join(set, "")); err != nil {
+		return err
+	}
+
+	changes := make(map[string]interface{})
+	for _, assignment := range set {
+		parts := strings.SplitN(assignment, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid --set %q, expected key=value", assignment)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(parts[1]), &value); err != nil {
+			value = parts[1]
+		}
+		changes[parts[0]] = value
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	count, err := app.UpdateRecordsMatching(*datasetID, *filter, string(changesJSON))
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(out, map[string]interface{}{"dataset": *datasetID, "updated": count})
+}
+
+func runDelete(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("delete")
+	datasetID := flags.String("dataset", "", "dataset to delete from")
+	filter := flags.String("filter", "", "filter expression selecting the records to delete")
+	dryRun := flags.Bool("dry-run", false, "only count the matching records")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "dataset", *datasetID); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "filter", *filter); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		records, err := queryRecords(app, *datasetID, map[string]interface{}{"filter": *filter})
+		if err != nil {
+			return err
+		}
+		return writeJSON(out, map[string]interface{}{"dataset": *datasetID, "matching": len(records)})
+	}
+
+	count, err := app.DeleteRecordsMatching(*datasetID, *filter)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(out, map[string]interface{}{"dataset": *datasetID, "deleted": count})
+}
+
+func runView(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("view")
+	name := flags.String("run", "", "name or ID of the saved view to run (lists the views when omitted)")
+	format := flags.String("format", formatJSON, "output format: json or csv")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	views, err := app.GetViews("")
+	if err != nil {
+		return err
+	}
+	if *name == "" {
+		return writeJSON(out, views)
+	}
+
+	viewID := ""
+	for _, view := range views {
+		if view.ID == *name || strings.EqualFold(view.Name, *name) {
+			viewID = view.ID
+			break
+		}
+	}
+	if viewID == "" {
+		return fmt.Errorf("no saved view named %q", *name)
+	}
+
+	result, err := app.RunView(viewID)
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(*format) == formatJSON {
+		return writeJSON(out, result)
+	}
+
+	dataset, err := app.GetDataset(result.View.DatasetID)
+	if err != nil {
+		return err
+	}
+	if len(result.View.Columns) > 0 {
+		fields := make([]database.FieldDefinition, 0, len(result.View.Columns))
+		for _, key := range result.View.Columns {
+			for _, field := range dataset.Fields {
+				if field.Key == key {
+					fields = append(fields, field)
+				}
+			}
+		}
+		dataset.Fields = fields
+	}
+
+	records := result.Records
+	for _, group := range result.Groups {
+		records = append(records, group.Records...)
+	}
+
+	return writeRecords(out, strings.ToLower(*format), dataset, records)
+}
+
+func runTags(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("tags")
+	rename := flags.String("rename", "", "rename a tag everywhere, as old=new")
+	merge := flags.String("merge", "", "comma-separated tags to merge into the --into tag")
+	into := flags.String("into", "", "tag that --merge folds the others into")
+	remove := flags.String("delete", "", "tag to remove from every record")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	tags, err := app.GetTags()
+	if err != nil {
+		return err
+	}
+	tagID := func(name string) (string, error) {
+		for _, tag := range tags {
+			if strings.EqualFold(tag.Name, strings.TrimSpace(name)) {
+				return tag.ID, nil
+			}
+		}
+		return "", fmt.Errorf("no tag named %q", name)
+	}
+
+	var updated int
+	switch {
+	case *rename != "":
+		from, to, ok := strings.Cut(*rename, "=")
+		if !ok || from == "" {
+			return fmt.Errorf("invalid --rename %q, expected old=new", *rename)
+		}
+		id, err := tagID(from)
+		if err != nil {
+			return err
+		}
+		updated, err = app.RenameTag(id, to)
+		if err != nil {
+			return err
+		}
+
+	case *merge != "":
+		if err := requireFlag(flags, "into", *into); err != nil {
+			return err
+		}
+		targetID, err := tagID(*into)
+		if err != nil {
+			return err
+		}
+		var sourceIDs []string
+		for _, name := range database.SplitTags(*merge) {
+			id, err := tagID(name)
+			if err != nil {
+				return err
+			}
+			sourceIDs = append(sourceIDs, id)
+		}
+		ids, err := json.Marshal(sourceIDs)
+		if err != nil {
+			return err
+		}
+		updated, err = app.MergeTags(targetID, string(ids))
+		if err != nil {
+			return err
+		}
+
+	case *remove != "":
+		id, err := tagID(*remove)
+		if err != nil {
+			return err
+		}
+		updated, err = app.DeleteTag(id)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return writeJSON(out, tags)
+	}
+
+	return writeJSON(out, map[string]interface{}{"updated": updated})
+}
+
+func runAddRecord(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("add-record")
+	datasetID := flags.String("dataset", "", "dataset to add the record to")
+	data := flags.String("data", "", "record as a JSON object, or - to read it from stdin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "dataset", *datasetID); err != nil {
+		return err
+	}
+	if err := requireFlag(flags, "data", *data); err != nil {
+		return err
+	}
+
+	recordJSON := *data
+	if recordJSON == "-" {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		recordJSON = string(input)
+	}
+
+	record, err := app.AddRecord(*datasetID, recordJSON, false)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(out, record)
+}
+
+func runVerify(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("verify")
+	repair := flags.Bool("repair", false, "fix the problems that can be repaired automatically")
+	dryRun := flags.Bool("dry-run", false, "with --repair, list the repairs without applying them")
+	var kinds multiFlag
+	flags.Var(&kinds, "kind", "limit repairs to one kind of issue (repeatable)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !*repair {
+		report, err := app.VerifyIntegrity()
+		if err != nil {
+			return err
+		}
+
+		err = writeJSON(out, report)
+		if err != nil {
+			return err
+		}
+
+		if !report.OK() {
+			return fmt.Errorf("integrity check found %d problem(s)", len(report.Issues))
+		}
+		return nil
+	}
+
+	options, err := json.Marshal(integrity.RepairOptions{DryRun: *dryRun, Kinds: kinds})
+	if err != nil {
+		return err
+	}
+
+	result, err := app.RepairIntegrity(string(options))
+	if err != nil {
+		return err
+	}
+
+	err = writeJSON(out, result)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, action := range result.Actions {
+		if action.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d problem(s) could not be repaired", failed)
+	}
+	return nil
+}
+
+func runGenerate(app *backend.App, args []string, out io.Writer) error {
+	flags := newFlagSet("generate")
+	seed := flags.Int64("seed", 0, "random seed; the same seed and options produce the same data (default: random)")
+	from := flags.String("from", "", "first day of generated data, YYYY-MM-DD")
+	to := flags.String("to", "", "last day of generated data, YYYY-MM-DD (default: today)")
+	days := flags.Int("days", 0, fmt.Sprintf("length of the span when --from is not given (default %d)", generator.DefaultDays))
+	scale := flags.Float64("scale", 1, "multiplier for the default volume of every dataset")
+	cleanup := flags.Bool("cleanup", false, "delete all generated records instead of generating")
+	list := flags.Bool("list", false, "list previous generator runs")
+	var datasets, counts multiFlag
+	flags.Var(&datasets, "dataset", "only generate this dataset and what it relates to (repeatable)")
+	flags.Var(&counts, "count", "dataset=N exact number of records for a dataset (repeatable)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *cleanup {
+		result, err := app.CleanupGeneratedData()
+		if err != nil {
+			return err
+		}
+		return writeJSON(out, result)
+	}
+
+	if *list {
+		batches, err := app.ListGeneratedBatches()
+		if err != nil {
+			return err
+		}
+		return writeJSON(out, batches)
+	}
+
+	options := generator.Options{
+		Seed:     *seed,
+		From:     *from,
+		To:       *to,
+		Days:     *days,
+		Scale:    *scale,
+		Datasets: datasets,
+		Counts:   make(map[string]int),
+	}
+	for _, count := range counts {
+		parts := strings.SplitN(count, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid --count %q, expected dataset=N", count)
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid --count %q: %w", count, err)
+		}
+		options.Counts[parts[0]] = n
+	}
+
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	batch, err := app.GenerateData(string(encoded))
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(out, batch)
+}
+
+func withOutput(path string, stdout io.Writer, write func(io.Writer) error) error {
+	if path == "" || path == "-" {
+		return write(stdout)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func withInput(path string, read func(io.Reader) error) error {
+	if path == "-" {
+		return read(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return read(file)
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"myproject/backend/database"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+var metadataColumns = map[string]bool{
+	"id":           true,
+	"datasetId":    true,
+	"createdAt":    true,
+	"lastModified": true,
+}
+
+func writeRecords(out io.Writer, format string, dataset database.Dataset, records []map[string]interface{}) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(out, records)
+	case formatCSV:
+		return writeCSV(out, dataset, records)
+	default:
+		return fmt.Errorf("unsupported format %q (use json or csv)", format)
+	}
+}
+
+func writeJSON(out io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func writeCSV(out io.Writer, dataset database.Dataset, records []map[string]interface{}) error {
+	columns := csvColumns(dataset, records)
+
+	writer := csv.NewWriter(out)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for _, record := range records {
+		for i, column := range columns {
+			row[i] = csvValue(record[column])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvColumns(dataset database.Dataset, records []map[string]interface{}) []string {
+	columns := []string{"id"}
+	seen := map[string]bool{"id": true}
+
+	for _, field := range dataset.Fields {
+		columns = append(columns, field.Key)
+		seen[field.Key] = true
+	}
+
+	var extra []string
+	for _, record := range records {
+		for key := range record {
+			if !seen[key] && !metadataColumns[key] && !strings.HasSuffix(key, "_data") {
+				extra = append(extra, key)
+				seen[key] = true
+			}
+		}
+	}
+	sort.Strings(extra)
+
+	columns = append(columns, extra...)
+	return append(columns, "createdAt", "lastModified")
+}
+
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+}
+
+func readRecords(in io.Reader, format string, dataset database.Dataset) ([]map[string]interface{}, error) {
+	switch format {
+	case formatJSON:
+		var records []map[string]interface{}
+		err := json.NewDecoder(in).Decode(&records)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON input: %w", err)
+		}
+		return records, nil
+	case formatCSV:
+		return readCSV(in, dataset)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (use json or csv)", format)
+	}
+}
+
+func readCSV(in io.Reader, dataset database.Dataset) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(in)
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV input: %w", err)
+	}
+	if len(rows) == 0 {
+		return []map[string]interface{}{}, nil
+	}
+
+	fieldTypes := make(map[string]database.FieldType)
+	for _, field := range dataset.Fields {
+		fieldTypes[field.Key] = field.Type
+	}
+
+	header := rows[0]
+	records := make([]map[string]interface{}, 0, len(rows)-1)
+	for lineNumber, row := range rows[1:] {
+		record := make(map[string]interface{})
+		for i, column := range header {
+			if i >= len(row) || metadataColumns[column] || row[i] == "" {
+				continue
+			}
+
+			value, err := parseCSVValue(fieldTypes[column], row[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d, column %s: %w", lineNumber+2, column, err)
+			}
+			record[column] = value
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func parseCSVValue(fieldType database.FieldType, raw string) (interface{}, error) {
+	switch fieldType {
+	case database.FieldTypeNumber, database.FieldTypePercentage:
+		return strconv.ParseFloat(raw, 64)
+	case database.FieldTypeBoolean:
+		return strconv.ParseBool(raw)
+	case database.FieldTypeJSON, database.FieldTypeFileMultiple:
+		var value interface{}
+		err := json.Unmarshal([]byte(raw), &value)
+		return value, err
+	default:
+		return raw, nil
+	}
+}
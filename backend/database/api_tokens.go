@@ -0,0 +1,168 @@
+package database
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const apiTokenPrefix = "dd_"
+
+var ErrInvalidAPIToken = errors.New("invalid API token")
+
+type APIToken struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	AllowPrivate bool       `json:"allowPrivate"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+type CreatedAPIToken struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+func InitializeAPITokens(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS api_tokens (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			allow_private INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			last_used_at TIMESTAMP
+		)
+	`)
+	return err
+}
+
+func CreateAPIToken(name string, allowPrivate bool) (CreatedAPIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return CreatedAPIToken{}, errors.New("token name is required")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return CreatedAPIToken{}, err
+	}
+	token := apiTokenPrefix + hex.EncodeToString(secret)
+
+	created := CreatedAPIToken{
+		APIToken: APIToken{
+			ID:           uuid.New().String(),
+			Name:         name,
+			AllowPrivate: allowPrivate,
+			CreatedAt:    time.Now(),
+		},
+		Token: token,
+	}
+
+	_, err := DB.Exec(
+		`INSERT INTO api_tokens (id, name, token_hash, allow_private, created_at) VALUES (?, ?, ?, ?, ?)`,
+		created.ID, created.Name, hashAPIToken(token), created.AllowPrivate, created.CreatedAt,
+	)
+	if err != nil {
+		return CreatedAPIToken{}, err
+	}
+
+	return created, nil
+}
+
+func ListAPITokens() ([]APIToken, error) {
+	rows, err := DB.Query(
+		`SELECT id, name, allow_private, created_at, last_used_at FROM api_tokens ORDER BY created_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func RevokeAPIToken(id string) error {
+	result, err := DB.Exec("DELETE FROM api_tokens WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &NotFoundError{Resource: "token", ID: id}
+	}
+
+	return nil
+}
+
+func AuthenticateAPIToken(token string) (APIToken, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+
+	row := DB.QueryRow(
+		`SELECT id, name, allow_private, created_at, last_used_at FROM api_tokens WHERE token_hash = ?`,
+		hashAPIToken(token),
+	)
+
+	apiToken, err := scanAPIToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return APIToken{}, ErrInvalidAPIToken
+		}
+		return APIToken{}, err
+	}
+
+	now := time.Now()
+	_, err = DB.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", now, apiToken.ID)
+	if err != nil {
+		return APIToken{}, err
+	}
+	apiToken.LastUsedAt = &now
+
+	return apiToken, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIToken(row rowScanner) (APIToken, error) {
+	var token APIToken
+	var lastUsedAt sql.NullTime
+
+	err := row.Scan(&token.ID, &token.Name, &token.AllowPrivate, &token.CreatedAt, &lastUsedAt)
+	if err != nil {
+		return APIToken{}, err
+	}
+
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return token, nil
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"log"
 )
 
 func CleanupUnusedTables() error {
@@ -67,7 +68,7 @@ func CleanupUnusedTables() error {
 	}
 
 	if len(datasetsToDelete) > 0 {
-		fmt.Printf("Found %d non-core datasets to delete\n", len(datasetsToDelete))
+		log.Printf("Found %d non-core datasets to delete\n", len(datasetsToDelete))
 
 		tx, err := DB.Begin()
 		if err != nil {
@@ -89,7 +90,7 @@ func CleanupUnusedTables() error {
 			}
 
 			if rowsDeleted, _ := result.RowsAffected(); rowsDeleted > 0 {
-				fmt.Printf("Deleted dataset %s and %d associated records\n", datasetID, recordsDeleted)
+				log.Printf("Deleted dataset %s and %d associated records\n", datasetID, recordsDeleted)
 			}
 		}
 
@@ -97,10 +98,10 @@ func CleanupUnusedTables() error {
 			return fmt.Errorf("error committing transaction: %w", err)
 		}
 	} else {
-		fmt.Println("No non-core datasets found to delete")
+		log.Println("No non-core datasets found to delete")
 	}
 
-	fmt.Println("Checking for orphaned records...")
+	log.Println("Checking for orphaned records...")
 	result, err := DB.Exec(`
 		DELETE FROM data_records 
 		WHERE dataset_id NOT IN (SELECT id FROM datasets)
@@ -110,11 +111,11 @@ func CleanupUnusedTables() error {
 	}
 
 	if rowsDeleted, _ := result.RowsAffected(); rowsDeleted > 0 {
-		fmt.Printf("Deleted %d orphaned records\n", rowsDeleted)
+		log.Printf("Deleted %d orphaned records\n", rowsDeleted)
 	} else {
-		fmt.Println("No orphaned records found")
+		log.Println("No orphaned records found")
 	}
 
-	fmt.Println("Database cleanup completed successfully")
+	log.Println("Database cleanup completed successfully")
 	return nil
 }
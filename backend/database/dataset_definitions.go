@@ -203,7 +203,7 @@ func GetAllDatasetDefinitions() []DatasetConfig {
 
 func getBodyMeasurementsFieldsInline() []FieldDefinition {
 	return []FieldDefinition{
-		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsSearchable: true},
+		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsSearchable: true, IsIndexed: true},
 		{Key: "time", Type: FieldTypeText, DisplayName: "Time", IsOptional: true},
 		{Key: "measurement", Type: FieldTypeText, DisplayName: "Measurement", IsSearchable: true},
 		{Key: "value", Type: FieldTypeNumber, DisplayName: "Value"},
@@ -214,18 +214,18 @@ func getBodyMeasurementsFieldsInline() []FieldDefinition {
 
 func getFinancialLogsFieldsInline() []FieldDefinition {
 	return []FieldDefinition{
-		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsSearchable: true},
-		{Key: "amount", Type: FieldTypeNumber, DisplayName: "Amount", Unit: "$"},
+		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsSearchable: true, IsIndexed: true},
+		{Key: "amount", Type: FieldTypeNumber, DisplayName: "Amount", Unit: "$", IsIndexed: true},
 		{Key: "description", Type: FieldTypeText, DisplayName: "Description", IsSearchable: true},
 		{Key: "category", Type: FieldTypeText, DisplayName: "Category", IsSearchable: true},
-		{Key: "tags", Type: FieldTypeText, DisplayName: "Tags", IsSearchable: true, IsOptional: true},
+		{Key: "tags", Type: FieldTypeTags, DisplayName: "Tags", IsSearchable: true, IsOptional: true},
 	}
 }
 
 func getFinancialBalancesFieldsInline() []FieldDefinition {
 	return []FieldDefinition{
-		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsSearchable: true},
-		{Key: "amount", Type: FieldTypeNumber, DisplayName: "Amount", Unit: "$"},
+		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsSearchable: true, IsIndexed: true},
+		{Key: "amount", Type: FieldTypeNumber, DisplayName: "Amount", Unit: "$", IsIndexed: true},
 		{Key: "account_name", Type: FieldTypeText, DisplayName: "Account Name", IsSearchable: true},
 		{Key: "account_type", Type: FieldTypeText, DisplayName: "Account Type", IsSearchable: true},
 		{Key: "account_owner", Type: FieldTypeText, DisplayName: "Account Owner", IsSearchable: true},
@@ -234,8 +234,8 @@ func getFinancialBalancesFieldsInline() []FieldDefinition {
 
 func getPaycheckInfoFieldsInline() []FieldDefinition {
 	return []FieldDefinition{
-		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsSearchable: true},
-		{Key: "amount", Type: FieldTypeNumber, DisplayName: "Amount", Unit: "$"},
+		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsSearchable: true, IsIndexed: true},
+		{Key: "amount", Type: FieldTypeNumber, DisplayName: "Amount", Unit: "$", IsIndexed: true},
 		{Key: "category", Type: FieldTypeText, DisplayName: "Category", IsSearchable: true},
 		{Key: "deduction_type", Type: FieldTypeText, DisplayName: "Deduction Type", IsSearchable: true},
 	}
@@ -243,7 +243,7 @@ func getPaycheckInfoFieldsInline() []FieldDefinition {
 
 func getFinancialFilesFieldsInline() []FieldDefinition {
 	return []FieldDefinition{
-		{Key: "date", Type: FieldTypeDate, DisplayName: "Date"},
+		{Key: "date", Type: FieldTypeDate, DisplayName: "Date", IsIndexed: true},
 		{Key: "files", Type: FieldTypeFileMultiple, DisplayName: "Files"},
 	}
 }
@@ -1,12 +1,14 @@
 package database
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"time"
 )
 
 func SyncDatasets() error {
-	fmt.Println("Syncing datasets from unified definitions...")
+	log.Println("Syncing datasets from unified definitions...")
 	configs := GetAllDatasetDefinitions()
 
 	for _, config := range configs {
@@ -16,7 +18,12 @@ func SyncDatasets() error {
 		}
 	}
 
-	fmt.Println("Dataset sync completed successfully")
+	err := SyncFieldIndexes()
+	if err != nil {
+		return fmt.Errorf("failed to sync field indexes: %w", err)
+	}
+
+	log.Println("Dataset sync completed successfully")
 	return nil
 }
 
@@ -24,7 +31,8 @@ func CreateOrUpdateDataset(id string, name string, description string, datasetTy
 	dataset, err := GetDataset(id)
 
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
+		var notFoundErr *NotFoundError
+		if errors.As(err, &notFoundErr) {
 			newDataset := Dataset{
 				ID:           id,
 				Name:         name,
@@ -39,7 +47,7 @@ func CreateOrUpdateDataset(id string, name string, description string, datasetTy
 			if err != nil {
 				return fmt.Errorf("error creating dataset %s: %w", id, err)
 			}
-			fmt.Printf("Created dataset: %s\n", id)
+			log.Printf("Created dataset: %s\n", id)
 			return nil
 		}
 
@@ -53,7 +61,7 @@ func CreateOrUpdateDataset(id string, name string, description string, datasetTy
 	if err != nil {
 		return fmt.Errorf("error updating dataset %s: %w", id, err)
 	}
-	fmt.Printf("Updated fields for dataset: %s\n", id)
+	log.Printf("Updated fields for dataset: %s\n", id)
 
 	return nil
 }
@@ -79,6 +87,7 @@ func FieldsEqual(a, b []FieldDefinition) bool {
 			fieldA.Description != fieldB.Description ||
 			fieldA.Unit != fieldB.Unit ||
 			fieldA.IsSearchable != fieldB.IsSearchable ||
+			fieldA.IsIndexed != fieldB.IsIndexed ||
 			fieldA.IsRelation != fieldB.IsRelation ||
 			fieldA.RelatedDataset != fieldB.RelatedDataset ||
 			fieldA.RelatedField != fieldB.RelatedField {
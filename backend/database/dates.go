@@ -0,0 +1,39 @@
+package database
+
+import (
+	"strings"
+	"time"
+)
+
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+func ParseDateValue(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if trimmed == "" {
+			return time.Time{}, false
+		}
+
+		for _, layout := range dateLayouts {
+			parsed, err := time.Parse(layout, trimmed)
+			if err == nil {
+				return parsed, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -2,16 +2,19 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
 
 var DB *sql.DB
 
-func Initialize(dbPath string) error {
+func Initialize(dbPath string, lockTimeoutMs int) error {
 	log.Printf("Initializing database at: %s\n", dbPath)
 
 	dbDir := filepath.Dir(dbPath)
@@ -22,7 +25,7 @@ func Initialize(dbPath string) error {
 
 	var err error
 	log.Printf("Opening database connection...\n")
-	DB, err = sql.Open("sqlite", dbPath)
+	DB, err = sql.Open("sqlite", dataSourceName(dbPath, lockTimeoutMs))
 	if err != nil {
 		log.Printf("Error opening database: %v\n", err)
 		return err
@@ -44,9 +47,104 @@ func Initialize(dbPath string) error {
 	return nil
 }
 
+// dataSourceName builds a file: URI so a path containing ? or # is not read
+// as the start of the connection parameters.
+func dataSourceName(dbPath string, lockTimeoutMs int) string {
+	segments := strings.Split(filepath.ToSlash(dbPath), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	path := strings.Join(segments, "/")
+	if filepath.IsAbs(dbPath) {
+		// Absolute paths take an empty authority, as in file:///C:/data.db.
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		path = "//" + path
+	}
+
+	params := url.Values{}
+	params.Set("_pragma", fmt.Sprintf("busy_timeout(%d)", lockTimeoutMs))
+	return "file:" + path + "?" + params.Encode()
+}
+
 func Close() error {
 	if DB != nil {
 		return DB.Close()
 	}
 	return nil
 }
+
+func BackupTo(destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+
+	_, err := DB.Exec("VACUUM INTO ?", destPath)
+	if err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	return nil
+}
+
+func IntegrityCheck() ([]string, error) {
+	rows, err := DB.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	problems := []string{}
+	for rows.Next() {
+		var message string
+		if err := rows.Scan(&message); err != nil {
+			return nil, err
+		}
+
+		if message != "ok" {
+			problems = append(problems, message)
+		}
+	}
+
+	return problems, rows.Err()
+}
+
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func WithTx(fn func(tx *sql.Tx) error) error {
+	return withOperationTx(nil, fn)
+}
+
+// withOperationTx runs fn in a transaction whose record changes go to op once
+// it commits. Changes are only journaled for a non-nil op.
+func withOperationTx(op *Operation, fn func(tx *sql.Tx) error) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if op != nil {
+		trackChanges(tx, op)
+		defer discardChanges(tx)
+	}
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	if op != nil {
+		keepChanges(tx)
+	}
+	return nil
+}
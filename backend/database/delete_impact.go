@@ -0,0 +1,195 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DeleteImpact is what deleting a record would do: the records removed with
+// it through cascading relations, the attachments no record would use any
+// more, and the references that stop the delete.
+type DeleteImpact struct {
+	RecordID  string         `json:"recordId"`
+	DatasetID string         `json:"datasetId"`
+	Records   []ImpactRecord `json:"records"`
+	Files     []ImpactFile   `json:"files"`
+	Blockers  []Reference    `json:"blockers"`
+}
+
+// ImpactRecord is a record removed by a delete. The requested record comes
+// first; the others name the record and relation field they cascade from.
+type ImpactRecord struct {
+	ID          string `json:"id"`
+	DatasetID   string `json:"datasetId"`
+	Title       string `json:"title"`
+	CascadeFrom string `json:"cascadeFrom,omitempty"`
+	Field       string `json:"field,omitempty"`
+}
+
+type ImpactFile struct {
+	Path     string `json:"path"`
+	RecordID string `json:"recordId"`
+	Field    string `json:"field"`
+}
+
+type deletePlan struct {
+	records []DataRecord
+	impact  DeleteImpact
+}
+
+type incomingRelation struct {
+	datasetID string
+	field     FieldDefinition
+}
+
+// PreviewDelete works out the impact of DeleteDataRecord without deleting
+// anything.
+func PreviewDelete(id string) (DeleteImpact, error) {
+	plan, err := planDelete(DB, id)
+	if err != nil {
+		return DeleteImpact{}, err
+	}
+	return plan.impact, nil
+}
+
+// planDelete follows cascading relations from a record, breadth first, and
+// collects what the delete touches. A reference that prevents deletes only
+// blocks when the referencing record is not being deleted as well.
+func planDelete(q Querier, id string) (deletePlan, error) {
+	root, err := getDataRecord(q, id)
+	if err != nil {
+		return deletePlan{}, err
+	}
+
+	datasets, err := listDatasets(q)
+	if err != nil {
+		return deletePlan{}, fmt.Errorf("failed to list datasets: %w", err)
+	}
+
+	byID := make(map[string]Dataset)
+	incoming := make(map[string][]incomingRelation)
+	for _, dataset := range datasets {
+		byID[dataset.ID] = dataset
+		for _, field := range dataset.Fields {
+			if field.IsRelation && (field.CascadeDeleteIfReferenced || field.PreventDeleteIfReferenced) {
+				incoming[field.RelatedDataset] = append(incoming[field.RelatedDataset], incomingRelation{datasetID: dataset.ID, field: field})
+			}
+		}
+	}
+
+	plan := deletePlan{
+		impact: DeleteImpact{
+			RecordID:  root.ID,
+			DatasetID: root.DatasetID,
+			Records:   []ImpactRecord{},
+			Files:     []ImpactFile{},
+			Blockers:  []Reference{},
+		},
+	}
+	titles := &timelineTitler{related: make(map[string]string)}
+	planned := make(map[string]bool)
+	add := func(record DataRecord, from string, field string) {
+		planned[record.ID] = true
+		plan.records = append(plan.records, record)
+
+		var data map[string]interface{}
+		json.Unmarshal(record.Data, &data)
+		plan.impact.Records = append(plan.impact.Records, ImpactRecord{
+			ID:          record.ID,
+			DatasetID:   record.DatasetID,
+			Title:       titles.title(byID[record.DatasetID], data),
+			CascadeFrom: from,
+			Field:       field,
+		})
+	}
+	add(root, "", "")
+
+	var references []Reference
+	for i := 0; i < len(plan.records); i++ {
+		record := plan.records[i]
+		for _, relation := range incoming[record.DatasetID] {
+			referencing, err := referencingRecords(q, relation.datasetID, relation.field.Key, record.ID)
+			if err != nil {
+				return deletePlan{}, fmt.Errorf("error checking references: %w", err)
+			}
+
+			for _, other := range referencing {
+				if !relation.field.CascadeDeleteIfReferenced {
+					references = append(references, Reference{DatasetID: other.DatasetID, RecordID: other.ID, Field: relation.field.Key})
+					continue
+				}
+				if !planned[other.ID] {
+					add(other, record.ID, relation.field.Key)
+				}
+			}
+		}
+	}
+
+	for _, reference := range references {
+		if !planned[reference.RecordID] {
+			plan.impact.Blockers = append(plan.impact.Blockers, reference)
+		}
+	}
+
+	plan.impact.Files, err = releasedFiles(q, plan.records)
+	if err != nil {
+		return deletePlan{}, err
+	}
+
+	return plan, nil
+}
+
+func referencingRecords(q Querier, datasetID string, field string, id string) ([]DataRecord, error) {
+	query := fmt.Sprintf(
+		`SELECT id, dataset_id, data, created_at, last_modified FROM data_records
+         WHERE dataset_id = ? AND json_extract(data, '$.%s') = ?`,
+		field)
+
+	rows, err := q.Query(query, datasetID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []DataRecord
+	for rows.Next() {
+		var record DataRecord
+		if err := rows.Scan(&record.ID, &record.DatasetID, &record.Data, &record.CreatedAt, &record.LastModified); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// releasedFiles lists the attachments of the records that no other record
+// refers to, which are removed from disk after the delete.
+func releasedFiles(q Querier, records []DataRecord) ([]ImpactFile, error) {
+	counts := make(map[string]int)
+	var owners []ImpactFile
+	for _, record := range records {
+		for _, reference := range fileReferences(record.Data) {
+			if counts[reference.path] == 0 {
+				owners = append(owners, ImpactFile{Path: reference.path, RecordID: record.ID, Field: reference.field})
+			}
+			counts[reference.path]++
+		}
+	}
+
+	files := []ImpactFile{}
+	for _, owner := range owners {
+		var refCount int
+		err := q.QueryRow("SELECT ref_count FROM files WHERE path = ?", owner.Path).Scan(&refCount)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if refCount <= counts[owner.Path] {
+			files = append(files, owner)
+		}
+	}
+
+	return files, nil
+}
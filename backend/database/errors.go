@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error codes of the typed errors below. They are part of the JSON errors the
+// frontend and the REST API receive, so they must not change.
+const (
+	CodeNotFound        = "not_found"
+	CodeUniqueViolation = "unique_violation"
+	CodeReferenced      = "referenced"
+	CodeValidation      = "validation"
+	CodeConflict        = "conflict"
+	CodeInternal        = "internal"
+)
+
+// NotFoundError reports a missing dataset, record, view, rule, tag or token.
+// It matches sql.ErrNoRows under errors.Is, like the lookups it replaces.
+type NotFoundError struct {
+	Resource string `json:"resource"`
+	ID       string `json:"id,omitempty"`
+}
+
+func (e *NotFoundError) Error() string {
+	if e.ID == "" {
+		return e.Resource + " not found"
+	}
+	return fmt.Sprintf("%s %s not found", e.Resource, e.ID)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == sql.ErrNoRows
+}
+
+func notFound(resource string, id string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &NotFoundError{Resource: resource, ID: id}
+	}
+	return err
+}
+
+// UniqueViolationError reports a value of a unique field that another record
+// of the dataset already has.
+type UniqueViolationError struct {
+	DatasetID     string `json:"datasetId"`
+	Field         string `json:"field"`
+	FieldName     string `json:"fieldName"`
+	Value         string `json:"value"`
+	ConflictingID string `json:"conflictingId"`
+}
+
+func (e *UniqueViolationError) Error() string {
+	return fmt.Sprintf("field '%s' must be unique. Value '%s' already exists in another record", e.FieldName, e.Value)
+}
+
+// Reference is a record pointing at another one through a relation field.
+type Reference struct {
+	DatasetID string `json:"datasetId"`
+	RecordID  string `json:"recordId"`
+	Field     string `json:"field"`
+}
+
+// ReferencedError reports a delete blocked by records that still point at the
+// record, or at records of the dataset.
+type ReferencedError struct {
+	Resource   string      `json:"resource"`
+	ID         string      `json:"id"`
+	References []Reference `json:"references"`
+}
+
+func (e *ReferencedError) Error() string {
+	return fmt.Sprintf("cannot delete %s because it is referenced by %d other record(s)", e.Resource, len(e.References))
+}
+
+// FieldError is a problem with one field of the submitted value.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// invalid returns a ValidationError for a single field.
+func invalid(field string, format string, args ...interface{}) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: fmt.Sprintf(format, args...)}}}
+}
+
+// ConflictError reports a change that clashes with existing data, such as
+// renaming onto a name that is already taken.
+type ConflictError struct {
+	Resource      string `json:"resource"`
+	ID            string `json:"id,omitempty"`
+	ConflictingID string `json:"conflictingId,omitempty"`
+	Message       string `json:"message"`
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// ErrorInfo is the JSON form of an error. Details holds the typed error's
+// fields and is omitted for untyped errors.
+type ErrorInfo struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// DescribeError finds the typed error in err's chain. The message is always
+// the full error text, including any context wrapped around it.
+func DescribeError(err error) ErrorInfo {
+	info := ErrorInfo{Code: CodeInternal, Message: err.Error()}
+
+	var notFoundErr *NotFoundError
+	var uniqueErr *UniqueViolationError
+	var referencedErr *ReferencedError
+	var validationErr *ValidationError
+	var conflictErr *ConflictError
+	switch {
+	case errors.As(err, &notFoundErr):
+		info.Code, info.Details = CodeNotFound, notFoundErr
+	case errors.As(err, &uniqueErr):
+		info.Code, info.Details = CodeUniqueViolation, uniqueErr
+	case errors.As(err, &referencedErr):
+		info.Code, info.Details = CodeReferenced, referencedErr
+	case errors.As(err, &validationErr):
+		info.Code, info.Details = CodeValidation, validationErr
+	case errors.As(err, &conflictErr):
+		info.Code, info.Details = CodeConflict, conflictErr
+	case errors.Is(err, sql.ErrNoRows):
+		info.Code = CodeNotFound
+	}
+
+	return info
+}
@@ -8,6 +8,7 @@ func GetBloodworkFields() []FieldDefinition {
 			DisplayName:  "Date",
 			Description:  "Date when the blood test was taken",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "fasted",
@@ -8,6 +8,7 @@ func GetDEXAFields() []FieldDefinition {
 			DisplayName:  "Date",
 			Description:  "Date when the DEXA scan was performed",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "fasted",
@@ -36,6 +36,7 @@ func GetExperimentFields() []FieldDefinition {
 			DisplayName:  "Start Date",
 			Description:  "When the experiment begins",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:          "end_date",
@@ -206,6 +207,7 @@ func GetDailyLogFields() []FieldDefinition {
 			DisplayName:  "Date",
 			Description:  "Date of the log entry",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:            "metric_id",
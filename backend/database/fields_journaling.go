@@ -8,6 +8,7 @@ func GetGratitudeJournalFields() []FieldDefinition {
 			DisplayName:  "Date",
 			Description:  "Date of the gratitude journal entry",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "entry",
@@ -26,6 +27,7 @@ func GetAffirmationFields() []FieldDefinition {
 			DisplayName:  "Date",
 			Description:  "Date of the affirmation",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "affirmation",
@@ -44,6 +46,7 @@ func GetCreativityJournalFields() []FieldDefinition {
 			DisplayName:  "Date",
 			Description:  "Date of the creativity journal entry",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "entry",
@@ -62,6 +65,7 @@ func GetQuestionJournalFields() []FieldDefinition {
 			DisplayName:  "Date",
 			Description:  "Date of the question journal entry",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "entry",
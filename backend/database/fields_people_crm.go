@@ -33,7 +33,7 @@ func GetPeopleFields() []FieldDefinition {
 		},
 		{
 			Key:         "tags",
-			Type:        FieldTypeText,
+			Type:        FieldTypeTags,
 			DisplayName: "Tags",
 			Description: "Comma-separated tags for categorization",
 			IsOptional:  true,
@@ -71,6 +71,7 @@ func GetMeetingsFields() []FieldDefinition {
 			DisplayName:  "Meeting Date",
 			Description:  "Date and time of the meeting",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "location",
@@ -110,7 +111,7 @@ func GetMeetingsFields() []FieldDefinition {
 		},
 		{
 			Key:         "tags",
-			Type:        FieldTypeText,
+			Type:        FieldTypeTags,
 			DisplayName: "Tags",
 			Description: "Comma-separated tags for categorization",
 			IsOptional:  true,
@@ -299,6 +300,7 @@ func GetPersonNotesFields() []FieldDefinition {
 			DisplayName:  "Note Date",
 			Description:  "Date of the note",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "content",
@@ -315,7 +317,7 @@ func GetPersonNotesFields() []FieldDefinition {
 		},
 		{
 			Key:         "tags",
-			Type:        FieldTypeText,
+			Type:        FieldTypeTags,
 			DisplayName: "Tags",
 			Description: "Comma-separated tags for categorization",
 			IsOptional:  true,
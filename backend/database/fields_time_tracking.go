@@ -15,6 +15,7 @@ func GetTimeEntriesFields() []FieldDefinition {
 			DisplayName:  "Start Time",
 			Description:  "When the activity started",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:          "end_time",
@@ -42,7 +43,7 @@ func GetTimeEntriesFields() []FieldDefinition {
 		},
 		{
 			Key:          "tags",
-			Type:         FieldTypeText,
+			Type:         FieldTypeTags,
 			DisplayName:  "Tags",
 			Description:  "Comma-separated tags for the activity",
 			IsOptional:   true,
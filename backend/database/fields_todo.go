@@ -22,6 +22,7 @@ func GetTodoFields() []FieldDefinition {
 			DisplayName:  "Deadline",
 			Description:  "When this todo needs to be completed by",
 			IsSearchable: true,
+			IsIndexed:    true,
 		},
 		{
 			Key:         "priority",
@@ -31,7 +32,7 @@ func GetTodoFields() []FieldDefinition {
 		},
 		{
 			Key:         "tags",
-			Type:        FieldTypeText,
+			Type:        FieldTypeTags,
 			DisplayName: "Tags",
 			Description: "Comma-separated tags for categorization",
 			IsOptional:  true,
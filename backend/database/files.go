@@ -0,0 +1,535 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"myproject/backend/file"
+	"sort"
+	"strings"
+	"time"
+)
+
+type FileOwner struct {
+	RecordID  string `json:"recordId"`
+	DatasetID string `json:"datasetId"`
+	Field     string `json:"field"`
+}
+
+type FileEntry struct {
+	Path         string              `json:"path"`
+	Hash         string              `json:"hash"`
+	Size         int64               `json:"size"`
+	OriginalName string              `json:"originalName"`
+	MimeType     string              `json:"mimeType"`
+	Image        *file.ImageMetadata `json:"image,omitempty"`
+	RefCount     int                 `json:"refCount"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	LastModified time.Time           `json:"lastModified"`
+	Owners       []FileOwner         `json:"owners"`
+}
+
+type FileFilter struct {
+	DatasetID    string `json:"datasetId,omitempty"`
+	RecordID     string `json:"recordId,omitempty"`
+	Field        string `json:"field,omitempty"`
+	MimeType     string `json:"mimeType,omitempty"`
+	Query        string `json:"query,omitempty"`
+	Unreferenced bool   `json:"unreferenced,omitempty"`
+	SortBy       string `json:"sortBy,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	Offset       int    `json:"offset,omitempty"`
+}
+
+func InitializeFiles(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS files (
+			path TEXT PRIMARY KEY,
+			sha256 TEXT NOT NULL DEFAULT '',
+			size INTEGER NOT NULL DEFAULT 0,
+			original_name TEXT NOT NULL DEFAULT '',
+			mime_type TEXT NOT NULL DEFAULT '',
+			image_metadata TEXT NOT NULL DEFAULT '',
+			taken_at TIMESTAMP,
+			ref_count INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			last_modified TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(db, "files", "original_name", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(db, "files", "mime_type", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(db, "files", "image_metadata", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(db, "files", "taken_at", "TIMESTAMP")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_files_sha256 ON files(sha256)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS file_owners (
+			path TEXT NOT NULL,
+			record_id TEXT NOT NULL,
+			dataset_id TEXT NOT NULL,
+			field TEXT NOT NULL,
+			PRIMARY KEY (path, record_id, field)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_file_owners_record ON file_owners(record_id)
+	`)
+	return err
+}
+
+func addColumnIfMissing(db *sql.DB, table string, column string, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// RegisterFile records a stored file. The original name and image metadata
+// of the first upload are kept when identical content is uploaded again.
+func RegisterFile(path string, hash string, size int64, originalName string, mimeType string, image *file.ImageMetadata) error {
+	imageMetadata := ""
+	var takenAt interface{}
+	if image != nil {
+		data, err := json.Marshal(image)
+		if err != nil {
+			return err
+		}
+		imageMetadata = string(data)
+		if image.TakenAt != nil {
+			takenAt = *image.TakenAt
+		}
+	}
+
+	now := time.Now()
+	_, err := DB.Exec(
+		`INSERT INTO files (path, sha256, size, original_name, mime_type, image_metadata, taken_at, ref_count, created_at, last_modified)
+         VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?, ?)
+         ON CONFLICT(path) DO UPDATE SET
+             sha256 = excluded.sha256,
+             size = excluded.size,
+             original_name = CASE WHEN files.original_name = '' THEN excluded.original_name ELSE files.original_name END,
+             mime_type = excluded.mime_type,
+             image_metadata = CASE WHEN files.image_metadata = '' THEN excluded.image_metadata ELSE files.image_metadata END,
+             taken_at = CASE WHEN files.image_metadata = '' THEN excluded.taken_at ELSE files.taken_at END,
+             last_modified = excluded.last_modified`,
+		path, hash, size, originalName, mimeType, imageMetadata, takenAt, now, now,
+	)
+	return err
+}
+
+const fileColumns = "path, sha256, size, original_name, mime_type, image_metadata, ref_count, created_at, last_modified"
+
+func scanFileEntry(row rowScanner) (FileEntry, error) {
+	var entry FileEntry
+	var imageMetadata string
+	err := row.Scan(&entry.Path, &entry.Hash, &entry.Size, &entry.OriginalName, &entry.MimeType,
+		&imageMetadata, &entry.RefCount, &entry.CreatedAt, &entry.LastModified)
+	if err != nil {
+		return FileEntry{}, err
+	}
+
+	if imageMetadata != "" {
+		entry.Image = &file.ImageMetadata{}
+		if err := json.Unmarshal([]byte(imageMetadata), entry.Image); err != nil {
+			entry.Image = nil
+		}
+	}
+
+	return entry, nil
+}
+
+func GetFileEntry(path string) (FileEntry, error) {
+	entry, err := scanFileEntry(DB.QueryRow("SELECT "+fileColumns+" FROM files WHERE path = ?", path))
+	if err != nil {
+		return FileEntry{}, err
+	}
+
+	owners, err := fileOwners([]string{path})
+	if err != nil {
+		return FileEntry{}, err
+	}
+	entry.Owners = owners[path]
+	if entry.Owners == nil {
+		entry.Owners = []FileOwner{}
+	}
+
+	return entry, nil
+}
+
+func ListFiles(filter FileFilter) ([]FileEntry, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.DatasetID != "" || filter.RecordID != "" || filter.Field != "" {
+		ownerConditions := []string{"o.path = files.path"}
+		if filter.DatasetID != "" {
+			ownerConditions = append(ownerConditions, "o.dataset_id = ?")
+			args = append(args, filter.DatasetID)
+		}
+		if filter.RecordID != "" {
+			ownerConditions = append(ownerConditions, "o.record_id = ?")
+			args = append(args, filter.RecordID)
+		}
+		if filter.Field != "" {
+			ownerConditions = append(ownerConditions, "o.field = ?")
+			args = append(args, filter.Field)
+		}
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM file_owners o WHERE "+strings.Join(ownerConditions, " AND ")+")")
+	}
+
+	if filter.MimeType != "" {
+		if strings.HasSuffix(filter.MimeType, "/") || strings.HasSuffix(filter.MimeType, "/*") {
+			conditions = append(conditions, "mime_type LIKE ? ESCAPE '\\'")
+			args = append(args, escapeLike(strings.TrimSuffix(filter.MimeType, "*"))+"%")
+		} else {
+			conditions = append(conditions, "(mime_type = ? OR mime_type LIKE ? ESCAPE '\\')")
+			args = append(args, filter.MimeType, escapeLike(filter.MimeType)+";%")
+		}
+	}
+
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		conditions = append(conditions, "(original_name LIKE ? ESCAPE '\\' OR path LIKE ? ESCAPE '\\')")
+		pattern := "%" + escapeLike(query) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	if filter.Unreferenced {
+		conditions = append(conditions, "ref_count <= 0")
+	}
+
+	statement := "SELECT " + fileColumns + " FROM files"
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	switch filter.SortBy {
+	case "", "createdAt":
+		statement += " ORDER BY created_at DESC, path"
+	case "takenAt":
+		// Photos without a capture time fall back to when they were added.
+		statement += " ORDER BY COALESCE(taken_at, created_at) ASC, path"
+	default:
+		return nil, fmt.Errorf("unknown file sort '%s'", filter.SortBy)
+	}
+
+	if filter.Limit > 0 {
+		statement += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := DB.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []FileEntry{}
+	var paths []string
+	for rows.Next() {
+		entry, err := scanFileEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		paths = append(paths, entry.Path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	owners, err := fileOwners(paths)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Owners = owners[entries[i].Path]
+		if entries[i].Owners == nil {
+			entries[i].Owners = []FileOwner{}
+		}
+	}
+
+	return entries, nil
+}
+
+func fileOwners(paths []string) (map[string][]FileOwner, error) {
+	owners := make(map[string][]FileOwner)
+	if len(paths) == 0 {
+		return owners, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(paths)), ",")
+	args := make([]interface{}, len(paths))
+	for i, path := range paths {
+		args[i] = path
+	}
+
+	rows, err := DB.Query(
+		"SELECT path, record_id, dataset_id, field FROM file_owners WHERE path IN ("+placeholders+") ORDER BY dataset_id, record_id, field",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var path string
+		var owner FileOwner
+		if err := rows.Scan(&path, &owner.RecordID, &owner.DatasetID, &owner.Field); err != nil {
+			return nil, err
+		}
+		owners[path] = append(owners[path], owner)
+	}
+
+	return owners, rows.Err()
+}
+
+// ReleaseFile forgets a file once nothing references it any more, the undo
+// history included. It reports whether the caller should remove the blob from
+// disk.
+func ReleaseFile(path string) (bool, error) {
+	result, err := DB.Exec("DELETE FROM files WHERE path = ? AND ref_count <= 0 AND path NOT IN (SELECT path FROM journal_files)", path)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func ListUnreferencedFiles(before time.Time) ([]string, error) {
+	return queryPaths("SELECT path FROM files WHERE ref_count <= 0 AND last_modified < ?", before)
+}
+
+func ListFilesMissingMetadata() ([]string, error) {
+	return queryPaths("SELECT path FROM files WHERE sha256 = '' OR mime_type = '' OR (mime_type LIKE 'image/%' AND image_metadata = '')")
+}
+
+func queryPaths(query string, args ...interface{}) ([]string, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, rows.Err()
+}
+
+type fieldReference struct {
+	field string
+	path  string
+}
+
+func fileReferences(data json.RawMessage) []fieldReference {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		// Damaged data keeps its files, without a field to attribute them to.
+		var references []fieldReference
+		for _, path := range file.ScanReferences(data) {
+			if _, err := file.CleanPath(path); err == nil {
+				references = append(references, fieldReference{path: path})
+			}
+		}
+		return references
+	}
+
+	keys := make([]string, 0, len(decoded))
+	for key := range decoded {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var references []fieldReference
+	for _, key := range keys {
+		for _, path := range file.CollectReferences(decoded[key]) {
+			if _, err := file.CleanPath(path); err != nil {
+				continue
+			}
+			references = append(references, fieldReference{field: key, path: path})
+		}
+	}
+
+	return references
+}
+
+func syncFileReferences(q Querier, record DataRecord, previous json.RawMessage, current json.RawMessage) error {
+	deltas := make(map[string]int)
+	for _, reference := range fileReferences(previous) {
+		deltas[reference.path]--
+	}
+	currentReferences := fileReferences(current)
+	for _, reference := range currentReferences {
+		deltas[reference.path]++
+	}
+
+	now := time.Now()
+	for path, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+
+		_, err := q.Exec(
+			`INSERT INTO files (path, ref_count, created_at, last_modified)
+             VALUES (?, MAX(?, 0), ?, ?)
+             ON CONFLICT(path) DO UPDATE SET ref_count = MAX(files.ref_count + ?, 0), last_modified = ?`,
+			path, delta, now, now, delta, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := q.Exec("DELETE FROM file_owners WHERE record_id = ?", record.ID)
+	if err != nil {
+		return err
+	}
+
+	return insertFileOwners(q, record, currentReferences)
+}
+
+func insertFileOwners(q Querier, record DataRecord, references []fieldReference) error {
+	for _, reference := range references {
+		_, err := q.Exec(
+			`INSERT OR IGNORE INTO file_owners (path, record_id, dataset_id, field) VALUES (?, ?, ?, ?)`,
+			reference.path, record.ID, record.DatasetID, reference.field,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildFileReferences recounts every reference from the stored records,
+// quarantined ones included, so counts survive crashes and manual edits.
+func RebuildFileReferences() error {
+	return WithTx(func(tx *sql.Tx) error {
+		counts := make(map[string]int)
+
+		_, err := tx.Exec("DELETE FROM file_owners")
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{"data_records", "quarantined_records"} {
+			rows, err := tx.Query("SELECT id, dataset_id, data FROM " + table)
+			if err != nil {
+				return err
+			}
+
+			var owned []DataRecord
+			for rows.Next() {
+				var record DataRecord
+				var data []byte
+				if err := rows.Scan(&record.ID, &record.DatasetID, &data); err != nil {
+					rows.Close()
+					return err
+				}
+				record.Data = data
+
+				references := fileReferences(record.Data)
+				for _, reference := range references {
+					counts[reference.path]++
+				}
+				if table == "data_records" && len(references) > 0 {
+					owned = append(owned, record)
+				}
+			}
+			rows.Close()
+
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			for _, record := range owned {
+				if err := insertFileOwners(tx, record, fileReferences(record.Data)); err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err = tx.Exec("UPDATE files SET ref_count = 0")
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for path, count := range counts {
+			_, err := tx.Exec(
+				`INSERT INTO files (path, ref_count, created_at, last_modified)
+                 VALUES (?, ?, ?, ?)
+                 ON CONFLICT(path) DO UPDATE SET ref_count = excluded.ref_count`,
+				path, count, now, now,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,351 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A filter is a small boolean expression over a dataset's fields, e.g.
+//
+//	date >= 2024-01-01 and category in ("food", "rent") and amount > 50 and tags contains "travel"
+//
+// Comparisons are field, operator, value. Operators are = != < <= > >=,
+// in (...), not in (...), contains, is empty and is not empty; they combine
+// with and, or, not and parentheses. Values are quoted strings, numbers,
+// true/false, dates, today, now, or bare words.
+
+type filterNode interface{}
+
+type filterAnd struct{ left, right filterNode }
+
+type filterOr struct{ left, right filterNode }
+
+type filterNot struct{ node filterNode }
+
+type filterComparison struct {
+	field    string
+	operator string
+	values   []filterValue
+	position int
+}
+
+const (
+	filterString = "string"
+	filterNumber = "number"
+	filterBool   = "bool"
+	filterWord   = "word"
+	filterToday  = "today"
+	filterNow    = "now"
+)
+
+type filterValue struct {
+	kind     string
+	text     string
+	number   float64
+	boolean  bool
+	position int
+}
+
+type filterToken struct {
+	kind     string // word, string, symbol, end
+	text     string
+	position int
+}
+
+func lexFilter(source string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, filterToken{"symbol", string(r), i + 1})
+			i++
+
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			start := i
+			i++
+			if i < len(runes) && (runes[i] == '=' || (r == '<' && runes[i] == '>')) {
+				i++
+			}
+			operator := string(runes[start:i])
+			if operator == "!" {
+				return nil, fmt.Errorf("filter: unexpected '!' at position %d", start+1)
+			}
+			tokens = append(tokens, filterToken{"symbol", operator, start + 1})
+
+		case r == '"' || r == '\'':
+			start := i
+			var text strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("filter: unterminated string at position %d", start+1)
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					text.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == r {
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, filterToken{"string", text.String(), start + 1})
+
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()=!<>,\"'", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{"word", string(runes[start:i]), start + 1})
+		}
+	}
+
+	return append(tokens, filterToken{"end", "", len(runes) + 1}), nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	next   int
+}
+
+func parseFilter(source string) (filterNode, error) {
+	tokens, err := lexFilter(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != "end" {
+		return nil, fmt.Errorf("filter: unexpected %q at position %d", token.text, token.position)
+	}
+
+	return node, nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) take() filterToken {
+	token := p.tokens[p.next]
+	if token.kind != "end" {
+		p.next++
+	}
+	return token
+}
+
+func (p *filterParser) keyword(word string) bool {
+	token := p.peek()
+	if token.kind == "word" && strings.EqualFold(token.text, word) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) symbol(symbol string) bool {
+	token := p.peek()
+	if token.kind == "symbol" && token.text == symbol {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.keyword("not") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{node}, nil
+	}
+
+	if p.symbol("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token := p.peek(); !p.symbol(")") {
+			return nil, fmt.Errorf("filter: expected ')' at position %d", token.position)
+		}
+		return node, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	field := p.take()
+	if field.kind != "word" || isFilterKeyword(field.text) {
+		return nil, fmt.Errorf("filter: expected a field name at position %d", field.position)
+	}
+	comparison := filterComparison{field: field.text, position: field.position}
+
+	operator := p.peek()
+	switch {
+	case operator.kind == "symbol" && operator.text != "(" && operator.text != ")" && operator.text != ",":
+		p.next++
+		comparison.operator = map[string]string{"==": "=", "<>": "!="}[operator.text]
+		if comparison.operator == "" {
+			comparison.operator = operator.text
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		comparison.values = []filterValue{value}
+
+	case p.keyword("contains"):
+		comparison.operator = "contains"
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		comparison.values = []filterValue{value}
+
+	case p.keyword("in"):
+		comparison.operator = "in"
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		comparison.values = values
+
+	case p.keyword("not"):
+		if !p.keyword("in") {
+			return nil, fmt.Errorf("filter: expected 'in' after 'not' at position %d", p.peek().position)
+		}
+		comparison.operator = "not in"
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		comparison.values = values
+
+	case p.keyword("is"):
+		comparison.operator = "is empty"
+		if p.keyword("not") {
+			comparison.operator = "is not empty"
+		}
+		if !p.keyword("empty") && !p.keyword("null") {
+			return nil, fmt.Errorf("filter: expected 'empty' at position %d", p.peek().position)
+		}
+
+	default:
+		return nil, fmt.Errorf("filter: expected an operator after %q at position %d", field.text, operator.position)
+	}
+
+	return comparison, nil
+}
+
+func (p *filterParser) parseList() ([]filterValue, error) {
+	if token := p.peek(); !p.symbol("(") {
+		return nil, fmt.Errorf("filter: expected '(' at position %d", token.position)
+	}
+
+	var values []filterValue
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if p.symbol(",") {
+			continue
+		}
+		if token := p.peek(); !p.symbol(")") {
+			return nil, fmt.Errorf("filter: expected ',' or ')' at position %d", token.position)
+		}
+		return values, nil
+	}
+}
+
+func (p *filterParser) parseValue() (filterValue, error) {
+	token := p.take()
+	value := filterValue{text: token.text, position: token.position}
+
+	switch token.kind {
+	case "string":
+		value.kind = filterString
+		return value, nil
+	case "word":
+	default:
+		return filterValue{}, fmt.Errorf("filter: expected a value at position %d", token.position)
+	}
+
+	lower := strings.ToLower(token.text)
+	switch {
+	case lower == "true" || lower == "false":
+		value.kind = filterBool
+		value.boolean = lower == "true"
+	case lower == "today":
+		value.kind = filterToday
+	case lower == "now":
+		value.kind = filterNow
+	case isFilterKeyword(lower):
+		return filterValue{}, fmt.Errorf("filter: expected a value at position %d", token.position)
+	default:
+		value.kind = filterWord
+		if number, err := strconv.ParseFloat(token.text, 64); err == nil {
+			value.kind = filterNumber
+			value.number = number
+		}
+	}
+
+	return value, nil
+}
+
+func isFilterKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not", "in", "contains", "is", "empty":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CompiledFilter is a filter turned into a parameterized condition on
+// data_records. SQL is empty when the filter was.
+type CompiledFilter struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Where returns the condition ready to append after another WHERE term.
+func (f CompiledFilter) Where() string {
+	if f.SQL == "" {
+		return ""
+	}
+	return " AND (" + f.SQL + ")"
+}
+
+const filterDateLayout = "2006-01-02T15:04:05.000"
+
+// CompileFilter parses a filter and checks it against the dataset's fields.
+// Field reads go through FieldExpression, so indexed fields use their index.
+func CompileFilter(dataset Dataset, source string) (CompiledFilter, error) {
+	if strings.TrimSpace(source) == "" {
+		return CompiledFilter{}, nil
+	}
+
+	node, err := parseFilter(source)
+	if err != nil {
+		return CompiledFilter{}, err
+	}
+
+	fields := make(map[string]FieldDefinition)
+	for _, field := range dataset.Fields {
+		fields[field.Key] = field
+	}
+
+	c := &filterCompiler{fields: fields, dataset: dataset.ID, now: time.Now()}
+	sql, err := c.compile(node)
+	if err != nil {
+		return CompiledFilter{}, err
+	}
+
+	return CompiledFilter{SQL: sql, Args: c.args}, nil
+}
+
+type filterCompiler struct {
+	fields  map[string]FieldDefinition
+	dataset string
+	now     time.Time
+	args    []interface{}
+}
+
+func (c *filterCompiler) compile(node filterNode) (string, error) {
+	switch n := node.(type) {
+	case filterAnd:
+		return c.join(n.left, n.right, "AND")
+	case filterOr:
+		return c.join(n.left, n.right, "OR")
+	case filterNot:
+		inner, err := c.compile(n.node)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case filterComparison:
+		return c.comparison(n)
+	}
+	return "", fmt.Errorf("filter: unknown expression %T", node)
+}
+
+func (c *filterCompiler) join(left filterNode, right filterNode, operator string) (string, error) {
+	l, err := c.compile(left)
+	if err != nil {
+		return "", err
+	}
+	r, err := c.compile(right)
+	if err != nil {
+		return "", err
+	}
+	return "(" + l + " " + operator + " " + r + ")", nil
+}
+
+func (c *filterCompiler) comparison(n filterComparison) (string, error) {
+	field, ok := c.fields[n.field]
+	if !ok || !indexableKey.MatchString(n.field) {
+		return "", fmt.Errorf("filter: dataset %s has no field %q (position %d)", c.dataset, n.field, n.position)
+	}
+
+	raw := fmt.Sprintf("json_extract(data, '$.%s')", field.Key)
+	switch n.operator {
+	case "is empty":
+		return fmt.Sprintf("(%s IS NULL OR %s IN ('', '[]', '{}'))", raw, raw), nil
+	case "is not empty":
+		return fmt.Sprintf("(%s IS NOT NULL AND %s NOT IN ('', '[]', '{}'))", raw, raw), nil
+	}
+
+	switch {
+	case field.Type == FieldTypeFile || field.Type == FieldTypeFileMultiple:
+		return "", c.unsupported(n, field)
+	case field.Type == FieldTypeJSON:
+		if n.operator != "contains" {
+			return "", c.unsupported(n, field)
+		}
+		value := n.values[0]
+		c.args = append(c.args, value.text)
+		if value.kind == filterNumber {
+			c.args[len(c.args)-1] = value.number
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(data, '$.%s') WHERE json_each.value = ?)", field.Key), nil
+	case field.Type == FieldTypeDate && !field.IsRelation:
+		return c.dateComparison(n, field)
+	case field.Type == FieldTypeTags:
+		return c.tagComparison(n, field)
+	}
+
+	expression := FieldExpression(field)
+	if n.operator == "contains" {
+		if field.Type != FieldTypeText && field.Type != FieldTypeMarkdown {
+			return "", c.unsupported(n, field)
+		}
+		c.args = append(c.args, "%"+escapeLike(n.values[0].text)+"%")
+		return fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, expression), nil
+	}
+
+	if field.Type == FieldTypeBoolean && n.operator != "=" && n.operator != "!=" && n.operator != "in" && n.operator != "not in" {
+		return "", c.unsupported(n, field)
+	}
+
+	args := make([]interface{}, len(n.values))
+	for i, value := range n.values {
+		arg, err := c.value(value, field)
+		if err != nil {
+			return "", err
+		}
+		args[i] = arg
+	}
+	c.args = append(c.args, args...)
+
+	return comparisonSQL(expression, n.operator, len(args)), nil
+}
+
+func comparisonSQL(expression string, operator string, count int) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+	switch operator {
+	case "in":
+		return fmt.Sprintf("%s IN (%s)", expression, placeholders)
+	case "not in":
+		return fmt.Sprintf("(%s IS NULL OR %s NOT IN (%s))", expression, expression, placeholders)
+	case "!=":
+		// Records without the field count as different.
+		return fmt.Sprintf("(%s IS NULL OR %s != ?)", expression, expression)
+	}
+	return fmt.Sprintf("%s %s ?", expression, operator)
+}
+
+func (c *filterCompiler) value(value filterValue, field FieldDefinition) (interface{}, error) {
+	switch {
+	case field.IsRelation:
+		if value.kind == filterString || value.kind == filterWord {
+			return value.text, nil
+		}
+	case field.Type == FieldTypeNumber || field.Type == FieldTypePercentage:
+		if value.kind == filterNumber {
+			return value.number, nil
+		}
+	case field.Type == FieldTypeBoolean:
+		if value.kind == filterBool {
+			if value.boolean {
+				return 1, nil
+			}
+			return 0, nil
+		}
+	default:
+		if value.kind != filterBool {
+			return value.text, nil
+		}
+	}
+
+	return nil, fmt.Errorf("filter: %s field %q cannot be compared with %q (position %d)", field.Type, field.Key, value.text, value.position)
+}
+
+// Date-only values match the whole day: "date = 2024-01-31" includes entries
+// timestamped during that day, and "date > 2024-01-31" starts the next day.
+func (c *filterCompiler) dateComparison(n filterComparison, field FieldDefinition) (string, error) {
+	if n.operator == "contains" {
+		return "", c.unsupported(n, field)
+	}
+
+	expression := FieldExpression(field)
+	var parts []string
+	for _, value := range n.values {
+		start, wholeDay, err := c.date(value, field)
+		if err != nil {
+			return "", err
+		}
+		from := start.Format(filterDateLayout)
+
+		if !wholeDay {
+			operator := n.operator
+			if operator == "in" || operator == "not in" || operator == "!=" {
+				operator = "="
+			}
+			c.args = append(c.args, from)
+			parts = append(parts, fmt.Sprintf("%s %s ?", expression, operator))
+			continue
+		}
+
+		until := start.AddDate(0, 0, 1).Format(filterDateLayout)
+		switch n.operator {
+		case "=", "in", "not in", "!=":
+			c.args = append(c.args, from, until)
+			parts = append(parts, fmt.Sprintf("(%s >= ? AND %s < ?)", expression, expression))
+		case "<":
+			c.args = append(c.args, from)
+			parts = append(parts, expression+" < ?")
+		case "<=":
+			c.args = append(c.args, until)
+			parts = append(parts, expression+" < ?")
+		case ">":
+			c.args = append(c.args, until)
+			parts = append(parts, expression+" >= ?")
+		case ">=":
+			c.args = append(c.args, from)
+			parts = append(parts, expression+" >= ?")
+		}
+	}
+
+	condition := "(" + strings.Join(parts, " OR ") + ")"
+	if n.operator == "!=" || n.operator == "not in" {
+		return fmt.Sprintf("(%s IS NULL OR NOT %s)", expression, condition), nil
+	}
+	return condition, nil
+}
+
+// Tags match whole tag names, ignoring case: "tags contains travel" and
+// "tags in (travel, work)" find records with either tag, "not in" those with
+// neither.
+func (c *filterCompiler) tagComparison(n filterComparison, field FieldDefinition) (string, error) {
+	if n.operator != "contains" && n.operator != "in" && n.operator != "not in" {
+		return "", c.unsupported(n, field)
+	}
+
+	for _, value := range n.values {
+		if value.kind == filterBool {
+			return "", fmt.Errorf("filter: tags field %q cannot be compared with %q (position %d)", field.Key, value.text, value.position)
+		}
+		c.args = append(c.args, value.text)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(n.values)), ", ")
+	condition := fmt.Sprintf(
+		`EXISTS (SELECT 1 FROM record_tags rt JOIN tags t ON t.id = rt.tag_id
+         WHERE rt.record_id = data_records.id AND rt.field = '%s' AND t.name IN (%s))`,
+		field.Key, placeholders,
+	)
+	if n.operator == "not in" {
+		return "NOT " + condition, nil
+	}
+	return condition, nil
+}
+
+func (c *filterCompiler) date(value filterValue, field FieldDefinition) (time.Time, bool, error) {
+	switch value.kind {
+	case filterToday:
+		year, month, day := c.now.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), true, nil
+	case filterNow:
+		return c.now.UTC(), false, nil
+	case filterString, filterWord:
+		parsed, ok := ParseDateValue(value.text)
+		if ok {
+			wholeDay := !strings.ContainsAny(value.text, "T: ")
+			return parsed.UTC(), wholeDay, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("filter: date field %q cannot be compared with %q (position %d)", field.Key, value.text, value.position)
+}
+
+func (c *filterCompiler) unsupported(n filterComparison, field FieldDefinition) error {
+	return fmt.Errorf("filter: operator %q is not supported for %s field %q (position %d)", n.operator, field.Type, field.Key, n.position)
+}
@@ -0,0 +1,362 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rows made by the synthetic data generator are listed in generated_records
+// so they can be removed again without touching anything entered by hand.
+
+type GeneratedBatch struct {
+	ID          string          `json:"id"`
+	Seed        int64           `json:"seed"`
+	Options     json.RawMessage `json:"options"`
+	Counts      map[string]int  `json:"counts"`
+	RecordCount int             `json:"recordCount"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+type GeneratedCleanup struct {
+	Deleted   map[string]int `json:"deleted"`
+	Total     int            `json:"total"`
+	Kept      int            `json:"kept"`
+	KeptIDs   []string       `json:"keptIds"`
+	Batches   int            `json:"batches"`
+	Remaining int            `json:"remaining"`
+
+	// ReleasedFiles lists attachments the deleted rows referenced, so the
+	// caller can remove blobs that are no longer used.
+	ReleasedFiles []string `json:"-"`
+}
+
+func InitializeGenerated(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS generated_batches (
+			id TEXT PRIMARY KEY,
+			seed INTEGER NOT NULL,
+			options TEXT NOT NULL,
+			counts TEXT NOT NULL,
+			record_count INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS generated_records (
+			record_id TEXT PRIMARY KEY,
+			batch_id TEXT NOT NULL,
+			dataset_id TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_generated_records_batch ON generated_records(batch_id)
+	`)
+	return err
+}
+
+// InsertGeneratedRecords writes one generator run in a single transaction.
+// Rules are not applied, so the run produces exactly the rows it describes.
+func InsertGeneratedRecords(seed int64, options json.RawMessage, records []DataRecord) (GeneratedBatch, error) {
+	batch := GeneratedBatch{
+		ID:          uuid.New().String(),
+		Seed:        seed,
+		Options:     options,
+		Counts:      make(map[string]int),
+		RecordCount: len(records),
+		CreatedAt:   time.Now(),
+	}
+	for _, record := range records {
+		batch.Counts[record.DatasetID]++
+	}
+
+	counts, err := json.Marshal(batch.Counts)
+	if err != nil {
+		return GeneratedBatch{}, err
+	}
+
+	err = WithTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			"INSERT INTO generated_batches (id, seed, options, counts, record_count, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+			batch.ID, batch.Seed, string(batch.Options), string(counts), batch.RecordCount, batch.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		insertRecord, err := tx.Prepare(
+			`INSERT INTO data_records (id, dataset_id, data, created_at, last_modified) VALUES (?, ?, ?, ?, ?)`,
+		)
+		if err != nil {
+			return err
+		}
+		defer insertRecord.Close()
+
+		markRecord, err := tx.Prepare(
+			`INSERT INTO generated_records (record_id, batch_id, dataset_id) VALUES (?, ?, ?)`,
+		)
+		if err != nil {
+			return err
+		}
+		defer markRecord.Close()
+
+		resolver := newTagResolver(tx)
+		for _, record := range records {
+			tags, err := resolver.resolve(&record)
+			if err != nil {
+				return err
+			}
+
+			if record.CreatedAt.IsZero() {
+				record.CreatedAt = batch.CreatedAt
+			}
+			if record.LastModified.IsZero() {
+				record.LastModified = record.CreatedAt
+			}
+
+			_, err = insertRecord.Exec(record.ID, record.DatasetID, []byte(record.Data), record.CreatedAt, record.LastModified)
+			if err != nil {
+				return fmt.Errorf("failed to insert generated %s record: %w", record.DatasetID, err)
+			}
+
+			_, err = markRecord.Exec(record.ID, batch.ID, record.DatasetID)
+			if err != nil {
+				return err
+			}
+
+			err = writeRecordTags(tx, record, tags)
+			if err != nil {
+				return err
+			}
+
+			if len(fileReferences(record.Data)) > 0 {
+				err = syncFileReferences(tx, record, nil, record.Data)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return GeneratedBatch{}, err
+	}
+
+	return batch, nil
+}
+
+func ListGeneratedBatches() ([]GeneratedBatch, error) {
+	rows, err := DB.Query(
+		"SELECT id, seed, options, counts, record_count, created_at FROM generated_batches ORDER BY created_at DESC",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := []GeneratedBatch{}
+	for rows.Next() {
+		var batch GeneratedBatch
+		var options, counts string
+		if err := rows.Scan(&batch.ID, &batch.Seed, &options, &counts, &batch.RecordCount, &batch.CreatedAt); err != nil {
+			return nil, err
+		}
+		batch.Options = json.RawMessage(options)
+		if err := json.Unmarshal([]byte(counts), &batch.Counts); err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	return batches, rows.Err()
+}
+
+// DeleteGeneratedRecords removes generated rows. A generated record that a
+// hand-entered record still points at is kept, together with the generated
+// records it points at in turn, so no relation is left dangling.
+func DeleteGeneratedRecords() (GeneratedCleanup, error) {
+	result := GeneratedCleanup{Deleted: make(map[string]int), KeptIDs: []string{}}
+
+	datasets, err := ListDatasets()
+	if err != nil {
+		return result, err
+	}
+
+	err = WithTx(func(tx *sql.Tx) error {
+		generated := make(map[string]string)
+		rows, err := tx.Query("SELECT record_id, dataset_id FROM generated_records")
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id, datasetID string
+			if err := rows.Scan(&id, &datasetID); err != nil {
+				rows.Close()
+				return err
+			}
+			generated[id] = datasetID
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// child -> generated parents, for every relation field.
+		parents := make(map[string][]string)
+		var roots []string
+		for _, dataset := range datasets {
+			for _, field := range dataset.Fields {
+				if !field.IsRelation || field.RelatedDataset == "" {
+					continue
+				}
+
+				// The dataset id is inlined so the partial relation index applies.
+				edges, err := tx.Query(fmt.Sprintf(
+					`SELECT id, json_extract(data, '$.%s') FROM data_records
+                     WHERE dataset_id = '%s' AND json_extract(data, '$.%s') IN (SELECT record_id FROM generated_records)`,
+					field.Key, strings.ReplaceAll(dataset.ID, "'", "''"), field.Key,
+				))
+				if err != nil {
+					return err
+				}
+				for edges.Next() {
+					var child, parent string
+					if err := edges.Scan(&child, &parent); err != nil {
+						edges.Close()
+						return err
+					}
+					if _, isGenerated := generated[child]; isGenerated {
+						parents[child] = append(parents[child], parent)
+					} else {
+						roots = append(roots, parent)
+					}
+				}
+				edges.Close()
+				if err := edges.Err(); err != nil {
+					return err
+				}
+			}
+		}
+
+		kept := make(map[string]bool)
+		for len(roots) > 0 {
+			id := roots[len(roots)-1]
+			roots = roots[:len(roots)-1]
+			if kept[id] {
+				continue
+			}
+			kept[id] = true
+			roots = append(roots, parents[id]...)
+		}
+
+		var deleted []string
+		for id, datasetID := range generated {
+			if kept[id] {
+				result.KeptIDs = append(result.KeptIDs, id)
+				continue
+			}
+			deleted = append(deleted, id)
+			result.Deleted[datasetID]++
+		}
+		result.Kept = len(result.KeptIDs)
+		result.Total = len(deleted)
+
+		for start := 0; start < len(deleted); start += 500 {
+			end := min(start+500, len(deleted))
+			chunk := deleted[start:end]
+
+			placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ",")
+			args := make([]interface{}, len(chunk))
+			for i, id := range chunk {
+				args[i] = id
+			}
+
+			released, err := releaseRecordFiles(tx, placeholders, args)
+			if err != nil {
+				return err
+			}
+			result.ReleasedFiles = append(result.ReleasedFiles, released...)
+
+			for _, statement := range []string{
+				"DELETE FROM data_records WHERE id IN (" + placeholders + ")",
+				"DELETE FROM file_owners WHERE record_id IN (" + placeholders + ")",
+				"DELETE FROM generated_records WHERE record_id IN (" + placeholders + ")",
+				"DELETE FROM record_tags WHERE record_id IN (" + placeholders + ")",
+			} {
+				if _, err := tx.Exec(statement, args...); err != nil {
+					return err
+				}
+			}
+		}
+
+		batches, err := tx.Exec("DELETE FROM generated_batches WHERE id NOT IN (SELECT DISTINCT batch_id FROM generated_records)")
+		if err != nil {
+			return err
+		}
+		removedBatches, err := batches.RowsAffected()
+		if err != nil {
+			return err
+		}
+		result.Batches = int(removedBatches)
+
+		return tx.QueryRow("SELECT COUNT(*) FROM generated_records").Scan(&result.Remaining)
+	})
+	if err != nil {
+		return GeneratedCleanup{}, err
+	}
+
+	return result, nil
+}
+
+func releaseRecordFiles(tx *sql.Tx, placeholders string, args []interface{}) ([]string, error) {
+	rows, err := tx.Query("SELECT id, dataset_id, data FROM data_records WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []DataRecord
+	for rows.Next() {
+		var record DataRecord
+		var data []byte
+		if err := rows.Scan(&record.ID, &record.DatasetID, &data); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		record.Data = data
+		records = append(records, record)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var released []string
+	for _, record := range records {
+		references := fileReferences(record.Data)
+		if len(references) == 0 {
+			continue
+		}
+
+		if err := syncFileReferences(tx, record, record.Data, nil); err != nil {
+			return nil, err
+		}
+		for _, reference := range references {
+			released = append(released, reference.path)
+		}
+	}
+
+	return released, nil
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// Field indexes are partial expression indexes on data_records, one per
+// dataset and field. Their names carry the expression kind so that changing
+// a field's type replaces the index instead of leaving a stale one.
+const fieldIndexPrefix = "idx_field_"
+
+var indexableKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// FieldExpression returns the SQL expression that reads a field from
+// data_records.data. Queries that filter or sort with it can use the field's
+// index. Dates are normalized to UTC "YYYY-MM-DDTHH:MM:SS.SSS" so date-only
+// and full timestamps order correctly; values SQLite cannot parse become NULL.
+func FieldExpression(field FieldDefinition) string {
+	extract := fmt.Sprintf("json_extract(data, '$.%s')", field.Key)
+
+	switch fieldIndexKind(field) {
+	case "date":
+		return fmt.Sprintf("strftime('%%Y-%%m-%%dT%%H:%%M:%%f', %s)", extract)
+	case "num":
+		return fmt.Sprintf("CAST(%s AS REAL)", extract)
+	}
+	return extract
+}
+
+// DatasetLiteral quotes a dataset id for inlining into SQL. SQLite only uses
+// the partial field indexes when the dataset id is a literal, not a parameter.
+func DatasetLiteral(datasetID string) string {
+	return "'" + strings.ReplaceAll(datasetID, "'", "''") + "'"
+}
+
+func fieldIndexKind(field FieldDefinition) string {
+	if field.IsRelation {
+		return "rel"
+	}
+
+	switch field.Type {
+	case FieldTypeDate:
+		return "date"
+	case FieldTypeNumber, FieldTypePercentage:
+		return "num"
+	}
+	return "text"
+}
+
+func fieldIndexed(field FieldDefinition) bool {
+	if !indexableKey.MatchString(field.Key) {
+		return false
+	}
+	return field.IsIndexed || (field.IsRelation && field.RelatedDataset != "")
+}
+
+func fieldIndexName(datasetID string, field FieldDefinition) string {
+	return fmt.Sprintf("%s%s_%s_%s", fieldIndexPrefix, strings.ReplaceAll(datasetID, "-", "_"), field.Key, fieldIndexKind(field))
+}
+
+// SyncFieldIndexes creates the indexes for indexed and relation fields of
+// every dataset and drops the ones no definition asks for anymore.
+func SyncFieldIndexes() error {
+	return syncFieldIndexes(DB)
+}
+
+func syncFieldIndexes(q Querier) error {
+	datasets, err := ListDatasets()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]string)
+	for _, dataset := range datasets {
+		for _, field := range dataset.Fields {
+			if !fieldIndexed(field) {
+				continue
+			}
+
+			name := fieldIndexName(dataset.ID, field)
+			wanted[name] = fmt.Sprintf(
+				`CREATE INDEX IF NOT EXISTS %s ON data_records((%s)) WHERE dataset_id = %s`,
+				name, FieldExpression(field), DatasetLiteral(dataset.ID),
+			)
+		}
+	}
+
+	// Every expression index on data_records belongs to a field, including
+	// the relation indexes created before they were named idx_field_*.
+	rows, err := q.Query(
+		`SELECT name FROM sqlite_master
+         WHERE type = 'index' AND tbl_name = 'data_records' AND sql LIKE '%json_extract%'`,
+	)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for name := range existing {
+		if _, ok := wanted[name]; ok {
+			continue
+		}
+		if _, err := q.Exec(fmt.Sprintf(`DROP INDEX IF EXISTS %s`, name)); err != nil {
+			return fmt.Errorf("error dropping index %s: %w", name, err)
+		}
+		log.Printf("Dropped field index %s\n", name)
+	}
+
+	for name, statement := range wanted {
+		if existing[name] {
+			continue
+		}
+		if _, err := q.Exec(statement); err != nil {
+			return fmt.Errorf("error creating index %s: %w", name, err)
+		}
+		log.Printf("Created field index %s\n", name)
+	}
+
+	// Without statistics SQLite prefers the dataset_id index over the partial
+	// ones. optimize only analyzes what is new or has grown since last time,
+	// and analysis_limit keeps that cheap on large databases.
+	_, err = q.Exec(`PRAGMA analysis_limit = 400; PRAGMA optimize = 0x10002`)
+	if err != nil {
+		return fmt.Errorf("error updating index statistics: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,430 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// journalLimit is how many operations the undo history keeps.
+const journalLimit = 100
+
+// JournalEntry is one undoable operation, such as an edit, an import or a
+// bulk delete, with the number of record changes it made.
+type JournalEntry struct {
+	ID        int64     `json:"id"`
+	Label     string    `json:"label"`
+	Changes   int       `json:"changes"`
+	Undone    bool      `json:"undone"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RecordChange is a record before and after a write. Before is nil for a
+// created record and After is nil for a deleted one.
+type RecordChange struct {
+	Before *DataRecord `json:"before,omitempty"`
+	After  *DataRecord `json:"after,omitempty"`
+}
+
+func (c RecordChange) recordID() string {
+	if c.After != nil {
+		return c.After.ID
+	}
+	return c.Before.ID
+}
+
+// Operation collects the record changes of one App mutation. Only the
+// transactions of functions it is passed to are journaled; one operation runs
+// at a time.
+type Operation struct {
+	label   string
+	changes []RecordChange
+}
+
+var operationMu sync.Mutex
+
+type pendingChanges struct {
+	op      *Operation
+	changes []RecordChange
+}
+
+var journal = struct {
+	sync.Mutex
+	pending map[*sql.Tx]*pendingChanges
+}{pending: make(map[*sql.Tx]*pendingChanges)}
+
+func InitializeJournal(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS journal_entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			label TEXT NOT NULL,
+			changes TEXT NOT NULL,
+			change_count INTEGER NOT NULL,
+			undone INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS journal_files (
+			entry_id INTEGER NOT NULL,
+			path TEXT NOT NULL,
+			PRIMARY KEY (entry_id, path)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_journal_files_path ON journal_files(path)
+	`)
+	return err
+}
+
+// BeginOperation starts recording record changes. It waits for any other
+// operation, undo or redo to finish.
+func BeginOperation(label string) *Operation {
+	operationMu.Lock()
+	return &Operation{label: label}
+}
+
+// End stores the operation as the newest journal entry. That drops the redo
+// history and the entries beyond journalLimit; the attachments only they
+// were keeping are returned, for the caller to release.
+func (op *Operation) End() ([]string, error) {
+	defer operationMu.Unlock()
+
+	if len(op.changes) == 0 {
+		return nil, nil
+	}
+
+	changesJSON, err := json.Marshal(op.changes)
+	if err != nil {
+		return nil, err
+	}
+
+	var released []string
+	err = WithTx(func(tx *sql.Tx) error {
+		var dropped []int64
+		rows, err := tx.Query("SELECT id FROM journal_entries WHERE undone = 1")
+		if err != nil {
+			return err
+		}
+		dropped, err = scanEntryIDs(rows)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(
+			`INSERT INTO journal_entries (label, changes, change_count, undone, created_at) VALUES (?, ?, ?, 0, ?)`,
+			op.label, string(changesJSON), len(op.changes), time.Now(),
+		)
+		if err != nil {
+			return err
+		}
+		entryID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for _, change := range op.changes {
+			for _, record := range []*DataRecord{change.Before, change.After} {
+				if record == nil {
+					continue
+				}
+				for _, reference := range fileReferences(record.Data) {
+					_, err := tx.Exec("INSERT OR IGNORE INTO journal_files (entry_id, path) VALUES (?, ?)", entryID, reference.path)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		rows, err = tx.Query("SELECT id FROM journal_entries WHERE undone = 0 ORDER BY id DESC LIMIT -1 OFFSET ?", journalLimit)
+		if err != nil {
+			return err
+		}
+		expired, err := scanEntryIDs(rows)
+		if err != nil {
+			return err
+		}
+
+		released, err = dropJournalEntries(tx, append(dropped, expired...))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save undo history: %w", err)
+	}
+
+	return released, nil
+}
+
+func scanEntryIDs(rows *sql.Rows) ([]int64, error) {
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// dropJournalEntries deletes entries and returns the attachment paths no
+// remaining entry refers to.
+func dropJournalEntries(q Querier, ids []int64) ([]string, error) {
+	var released []string
+	for _, id := range ids {
+		rows, err := q.Query(
+			`SELECT path FROM journal_files WHERE entry_id = ?
+             AND path NOT IN (SELECT path FROM journal_files WHERE entry_id != ?)`,
+			id, id,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var path string
+			if err := rows.Scan(&path); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			released = append(released, path)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		if _, err := q.Exec("DELETE FROM journal_files WHERE entry_id = ?", id); err != nil {
+			return nil, err
+		}
+		if _, err := q.Exec("DELETE FROM journal_entries WHERE id = ?", id); err != nil {
+			return nil, err
+		}
+	}
+	return released, nil
+}
+
+// recordChange notes a write made in q. It is kept once q commits, and only
+// when q belongs to an operation.
+func recordChange(q Querier, before *DataRecord, after *DataRecord) {
+	tx, ok := q.(*sql.Tx)
+	if !ok {
+		return
+	}
+
+	journal.Lock()
+	defer journal.Unlock()
+	pending := journal.pending[tx]
+	if pending == nil {
+		return
+	}
+
+	change := RecordChange{}
+	if before != nil {
+		snapshot := *before
+		change.Before = &snapshot
+	}
+	if after != nil {
+		snapshot := *after
+		change.After = &snapshot
+	}
+	pending.changes = append(pending.changes, change)
+}
+
+func trackChanges(tx *sql.Tx, op *Operation) {
+	journal.Lock()
+	defer journal.Unlock()
+
+	journal.pending[tx] = &pendingChanges{op: op}
+}
+
+func keepChanges(tx *sql.Tx) {
+	journal.Lock()
+	defer journal.Unlock()
+
+	if pending := journal.pending[tx]; pending != nil {
+		pending.op.changes = append(pending.op.changes, pending.changes...)
+	}
+	delete(journal.pending, tx)
+}
+
+func discardChanges(tx *sql.Tx) {
+	journal.Lock()
+	defer journal.Unlock()
+
+	delete(journal.pending, tx)
+}
+
+func ListJournal() ([]JournalEntry, error) {
+	rows, err := DB.Query(
+		`SELECT id, label, change_count, undone, created_at FROM journal_entries ORDER BY id DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []JournalEntry{}
+	for rows.Next() {
+		var entry JournalEntry
+		if err := rows.Scan(&entry.ID, &entry.Label, &entry.Changes, &entry.Undone, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ListJournalFiles returns the attachments the undo history still needs.
+func ListJournalFiles() ([]string, error) {
+	return queryPaths("SELECT DISTINCT path FROM journal_files")
+}
+
+// Undo reverts the newest operation that is not undone yet, and returns nil
+// when there is none.
+func Undo() (*JournalEntry, error) {
+	return replayJournal(true)
+}
+
+// Redo applies the oldest undone operation again, and returns nil when there
+// is none.
+func Redo() (*JournalEntry, error) {
+	return replayJournal(false)
+}
+
+// replayJournal puts every record of an entry back to its state before (undo)
+// or after (redo) the operation. A record changed since then makes it fail
+// with a ConflictError, leaving everything as it was.
+func replayJournal(undo bool) (*JournalEntry, error) {
+	operationMu.Lock()
+	defer operationMu.Unlock()
+
+	query := `SELECT id, label, changes, change_count, undone, created_at FROM journal_entries WHERE undone = 0 ORDER BY id DESC LIMIT 1`
+	if !undo {
+		query = `SELECT id, label, changes, change_count, undone, created_at FROM journal_entries WHERE undone = 1 ORDER BY id LIMIT 1`
+	}
+
+	var entry JournalEntry
+	found := false
+	err := WithTx(func(tx *sql.Tx) error {
+		var changesJSON string
+		err := tx.QueryRow(query).Scan(&entry.ID, &entry.Label, &changesJSON, &entry.Changes, &entry.Undone, &entry.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		found = true
+
+		var changes []RecordChange
+		if err := json.Unmarshal([]byte(changesJSON), &changes); err != nil {
+			return fmt.Errorf("journal entry %d: %w", entry.ID, err)
+		}
+
+		if undo {
+			for i := len(changes) - 1; i >= 0; i-- {
+				err = restoreRecord(tx, entry, changes[i].recordID(), changes[i].After, changes[i].Before)
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			for _, change := range changes {
+				err = restoreRecord(tx, entry, change.recordID(), change.Before, change.After)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		entry.Undone = undo
+		_, err = tx.Exec("UPDATE journal_entries SET undone = ? WHERE id = ?", undo, entry.ID)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// restoreRecord moves a record from the expected state to the target one,
+// where nil means the record does not exist. Rules do not run, since their
+// own changes are part of the entry.
+func restoreRecord(q Querier, entry JournalEntry, id string, expected *DataRecord, target *DataRecord) error {
+	current, err := getDataRecord(q, id)
+	exists := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if exists != (expected != nil) || (exists && !sameData(current.Data, expected.Data)) {
+		return &ConflictError{
+			Resource: "record",
+			ID:       id,
+			Message:  fmt.Sprintf("record %s was changed after %q", id, entry.Label),
+		}
+	}
+
+	if target == nil {
+		return deleteRecord(q, current)
+	}
+
+	record := *target
+	if _, err := getDataset(q, record.DatasetID); err != nil {
+		return err
+	}
+
+	tags, err := resolveRecordTags(q, &record)
+	if err != nil {
+		return err
+	}
+
+	var previous json.RawMessage
+	if exists {
+		previous = current.Data
+		_, err = q.Exec(
+			"UPDATE data_records SET data = ?, last_modified = ? WHERE id = ?",
+			[]byte(record.Data), record.LastModified, record.ID,
+		)
+	} else {
+		_, err = q.Exec(
+			`INSERT INTO data_records (id, dataset_id, data, created_at, last_modified) VALUES (?, ?, ?, ?, ?)`,
+			record.ID, record.DatasetID, []byte(record.Data), record.CreatedAt, record.LastModified,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = writeRecordTags(q, record, tags)
+	if err != nil {
+		return err
+	}
+
+	return syncFileReferences(q, record, previous, record.Data)
+}
+
+func sameData(a json.RawMessage, b json.RawMessage) bool {
+	var left, right interface{}
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// One-time data migrations are recorded by name once they succeed, so
+// later startups skip them. A database restored from an older backup has
+// no record of them and runs them again.
+
+func InitializeMigrations(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS migrations (
+			name TEXT PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	return err
+}
+
+// RunMigrationOnce runs migrate unless a migration called name has already
+// been applied to this database.
+func RunMigrationOnce(name string, migrate func() error) error {
+	var applied int
+	err := DB.QueryRow("SELECT COUNT(*) FROM migrations WHERE name = ?", name).Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	if err := migrate(); err != nil {
+		return fmt.Errorf("migration %s: %w", name, err)
+	}
+
+	_, err = DB.Exec("INSERT INTO migrations (name, applied_at) VALUES (?, ?)", name, time.Now())
+	return err
+}
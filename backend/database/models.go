@@ -3,8 +3,6 @@ package database
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
-	"strings"
 	"time"
 )
 
@@ -35,6 +33,7 @@ const (
 	FieldTypeJSON         FieldType = "json"
 	FieldTypeFile         FieldType = "file"
 	FieldTypeFileMultiple FieldType = "file-multiple"
+	FieldTypeTags         FieldType = "tags"
 )
 
 type FieldDefinition struct {
@@ -46,6 +45,7 @@ type FieldDefinition struct {
 	IsSearchable bool      `json:"isSearchable,omitempty"`
 	IsOptional   bool      `json:"isOptional,omitempty"`
 	IsUnique     bool      `json:"isUnique,omitempty"`
+	IsIndexed    bool      `json:"isIndexed,omitempty"`
 
 	RelatedDataset            string `json:"relatedDataset,omitempty"`
 	RelatedField              string `json:"relatedField,omitempty"`
@@ -88,30 +88,7 @@ func InitializeRelationships(db *sql.DB) error {
 		return err
 	}
 
-	datasets, err := ListDatasets()
-	if err != nil {
-		return err
-	}
-
-	for _, dataset := range datasets {
-		for _, field := range dataset.Fields {
-			if field.IsRelation && field.RelatedDataset != "" {
-				indexName := fmt.Sprintf("idx_%s_%s", strings.ReplaceAll(dataset.ID, "-", "_"), field.Key)
-
-				indexSQL := fmt.Sprintf(
-					`CREATE INDEX IF NOT EXISTS %s ON data_records((json_extract(data, '$.%s'))) WHERE dataset_id = '%s'`,
-					indexName, field.Key, dataset.ID,
-				)
-
-				_, err = db.Exec(indexSQL)
-				if err != nil {
-					return err
-				}
-			}
-		}
-	}
-
-	return nil
+	return syncFieldIndexes(db)
 }
 
 func InitializeSchema(db *sql.DB) error {
@@ -149,5 +126,50 @@ func InitializeSchema(db *sql.DB) error {
 		return err
 	}
 
+	err = InitializeAPITokens(db)
+	if err != nil {
+		return err
+	}
+
+	err = InitializeRules(db)
+	if err != nil {
+		return err
+	}
+
+	err = InitializeQuarantine(db)
+	if err != nil {
+		return err
+	}
+
+	err = InitializeFiles(db)
+	if err != nil {
+		return err
+	}
+
+	err = InitializeGenerated(db)
+	if err != nil {
+		return err
+	}
+
+	err = InitializeViews(db)
+	if err != nil {
+		return err
+	}
+
+	err = InitializeTags(db)
+	if err != nil {
+		return err
+	}
+
+	err = InitializeJournal(db)
+	if err != nil {
+		return err
+	}
+
+	err = InitializeMigrations(db)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
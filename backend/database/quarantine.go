@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type QuarantinedRecord struct {
+	ID            string          `json:"id"`
+	DatasetID     string          `json:"datasetId"`
+	Data          json.RawMessage `json:"data"`
+	Reason        string          `json:"reason"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	LastModified  time.Time       `json:"lastModified"`
+	QuarantinedAt time.Time       `json:"quarantinedAt"`
+}
+
+func InitializeQuarantine(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS quarantined_records (
+			id TEXT PRIMARY KEY,
+			dataset_id TEXT NOT NULL,
+			data TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			last_modified TIMESTAMP NOT NULL,
+			quarantined_at TIMESTAMP NOT NULL
+		)
+	`)
+	return err
+}
+
+// QuarantineRecord moves a record out of data_records without running
+// validation or automation rules, so broken rows can be set aside intact.
+func QuarantineRecord(q Querier, id string, reason string) error {
+	result, err := q.Exec(
+		`INSERT OR REPLACE INTO quarantined_records (id, dataset_id, data, reason, created_at, last_modified, quarantined_at)
+         SELECT id, dataset_id, data, ?, created_at, last_modified, ? FROM data_records WHERE id = ?`,
+		reason, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to quarantine record %s: %w", id, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &NotFoundError{Resource: "record", ID: id}
+	}
+
+	_, err = q.Exec("DELETE FROM data_records WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec("DELETE FROM file_owners WHERE record_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec("DELETE FROM record_tags WHERE record_id = ?", id)
+	return err
+}
+
+func ListQuarantinedRecords() ([]QuarantinedRecord, error) {
+	rows, err := DB.Query(
+		`SELECT id, dataset_id, data, reason, created_at, last_modified, quarantined_at
+         FROM quarantined_records ORDER BY quarantined_at DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []QuarantinedRecord{}
+	for rows.Next() {
+		var record QuarantinedRecord
+		var data string
+		err := rows.Scan(&record.ID, &record.DatasetID, &data, &record.Reason, &record.CreatedAt, &record.LastModified, &record.QuarantinedAt)
+		if err != nil {
+			return nil, err
+		}
+		if json.Valid([]byte(data)) {
+			record.Data = json.RawMessage(data)
+		} else {
+			record.Data, _ = json.Marshal(data)
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// WriteRecordData replaces a record's data as-is, bypassing validation and
+// automation rules. It is meant for repairs, not regular edits.
+func WriteRecordData(q Querier, id string, data json.RawMessage) error {
+	previous, err := getDataRecord(q, id)
+	if err != nil {
+		return err
+	}
+
+	record := previous
+	record.Data = data
+	record.LastModified = time.Now()
+	tags, err := resolveRecordTags(q, &record)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(
+		"UPDATE data_records SET data = ?, last_modified = ? WHERE id = ?",
+		[]byte(record.Data), record.LastModified, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = writeRecordTags(q, record, tags)
+	if err != nil {
+		return err
+	}
+
+	err = syncFileReferences(q, previous, previous.Data, record.Data)
+	if err != nil {
+		return err
+	}
+	recordChange(q, &previous, &record)
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RecordQuery selects records of one dataset. Filter uses the filter
+// language; without Sort records come newest first.
+type RecordQuery struct {
+	Filter     string `json:"filter,omitempty"`
+	Sort       string `json:"sort,omitempty"`
+	Descending bool   `json:"descending,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+	Offset     int    `json:"offset,omitempty"`
+}
+
+func QueryDataRecords(datasetID string, query RecordQuery) ([]DataRecord, error) {
+	return queryDataRecords(DB, datasetID, query)
+}
+
+func queryDataRecords(q Querier, datasetID string, query RecordQuery) ([]DataRecord, error) {
+	dataset, err := getDataset(q, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := CompileFilter(dataset, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	order := "created_at DESC"
+	if query.Sort != "" {
+		var field *FieldDefinition
+		for i := range dataset.Fields {
+			if dataset.Fields[i].Key == query.Sort {
+				field = &dataset.Fields[i]
+			}
+		}
+		if field == nil || !indexableKey.MatchString(query.Sort) {
+			return nil, fmt.Errorf("dataset %s has no field %q", datasetID, query.Sort)
+		}
+
+		direction := "ASC"
+		if query.Descending {
+			direction = "DESC"
+		}
+		order = fmt.Sprintf("%s %s, created_at %s", FieldExpression(*field), direction, direction)
+	}
+
+	// The dataset id is inlined so the partial field indexes apply.
+	statement := fmt.Sprintf(
+		`SELECT id, dataset_id, data, created_at, last_modified
+         FROM data_records WHERE dataset_id = %s%s ORDER BY %s`,
+		DatasetLiteral(datasetID), filter.Where(), order,
+	)
+	args := filter.Args
+	if query.Limit > 0 || query.Offset > 0 {
+		limit := query.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		statement += " LIMIT ? OFFSET ?"
+		args = append(args, limit, query.Offset)
+	}
+
+	rows, err := q.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDataRecords(rows)
+}
+
+// GetDataRecordsSorted lists a dataset's records ordered by one field, using
+// the field's index when it has one.
+func GetDataRecordsSorted(datasetID string, sortKey string, descending bool) ([]DataRecord, error) {
+	return QueryDataRecords(datasetID, RecordQuery{Sort: sortKey, Descending: descending})
+}
+
+func scanDataRecords(rows *sql.Rows) ([]DataRecord, error) {
+	var records []DataRecord
+	for rows.Next() {
+		var record DataRecord
+		err := rows.Scan(&record.ID, &record.DatasetID, &record.Data, &record.CreatedAt, &record.LastModified)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// RequireFilter rejects a blank filter for bulk changes, where it would
+// otherwise match every record of the dataset.
+func RequireFilter(filter string) error {
+	if strings.TrimSpace(filter) == "" {
+		return invalid("filter", "a filter is required to change records in bulk")
+	}
+	return nil
+}
+
+// UpdateDataRecordsMatching sets the given fields on every record the filter
+// matches, in one transaction. Rules run for each updated record as usual.
+func UpdateDataRecordsMatching(op *Operation, datasetID string, filter string, changes map[string]interface{}) (int, error) {
+	if err := RequireFilter(filter); err != nil {
+		return 0, err
+	}
+
+	dataset, err := GetDataset(datasetID)
+	if err != nil {
+		return 0, err
+	}
+
+	fields := make(map[string]FieldDefinition)
+	for _, field := range dataset.Fields {
+		fields[field.Key] = field
+	}
+	for key := range changes {
+		field, ok := fields[key]
+		if !ok {
+			return 0, fmt.Errorf("dataset %s has no field %q", datasetID, key)
+		}
+		if field.Type == FieldTypeFile || field.Type == FieldTypeFileMultiple {
+			return 0, fmt.Errorf("attachments in %q cannot be changed in bulk", key)
+		}
+	}
+
+	updated := 0
+	err = withOperationTx(op, func(tx *sql.Tx) error {
+		records, err := queryDataRecords(tx, datasetID, RecordQuery{Filter: filter})
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			var data map[string]interface{}
+			if err := json.Unmarshal(record.Data, &data); err != nil {
+				return fmt.Errorf("record %s: %w", record.ID, err)
+			}
+			for key, value := range changes {
+				data[key] = value
+			}
+
+			record.Data, err = json.Marshal(data)
+			if err != nil {
+				return err
+			}
+			if err := updateDataRecord(tx, record, 0); err != nil {
+				return fmt.Errorf("record %s: %w", record.ID, err)
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, nil
+}
@@ -1,11 +1,14 @@
 package database
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,6 +21,15 @@ func CreateDataset(dataset Dataset) error {
 		return err
 	}
 
+	var existing string
+	err = DB.QueryRow("SELECT id FROM datasets WHERE id = ?", dataset.ID).Scan(&existing)
+	if err == nil {
+		return &ConflictError{Resource: "dataset", ID: dataset.ID, ConflictingID: existing, Message: fmt.Sprintf("dataset %s already exists", dataset.ID)}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
 	now := time.Now()
 	dataset.CreatedAt = now
 	dataset.LastModified = now
@@ -36,15 +48,19 @@ func CreateDataset(dataset Dataset) error {
 }
 
 func GetDataset(id string) (Dataset, error) {
+	return getDataset(DB, id)
+}
+
+func getDataset(q Querier, id string) (Dataset, error) {
 	var dataset Dataset
 	var fieldsJSON string
 
-	err := DB.QueryRow(
+	err := q.QueryRow(
 		`SELECT id, name, description, type, fields, created_at, last_modified 
          FROM datasets WHERE id = ?`, id,
 	).Scan(&dataset.ID, &dataset.Name, &dataset.Description, &dataset.Type, &fieldsJSON, &dataset.CreatedAt, &dataset.LastModified)
 	if err != nil {
-		return Dataset{}, err
+		return Dataset{}, notFound("dataset", id, err)
 	}
 
 	err = json.Unmarshal([]byte(fieldsJSON), &dataset.Fields)
@@ -62,6 +78,11 @@ func UpdateDataset(dataset Dataset) error {
 		return err
 	}
 
+	previous, err := GetDataset(dataset.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
 	dataset.LastModified = time.Now()
 
 	fieldsJSON, err := json.Marshal(dataset.Fields)
@@ -83,65 +104,101 @@ func UpdateDataset(dataset Dataset) error {
 		return err
 	}
 	if rows == 0 {
-		return errors.New("dataset not found")
+		return &NotFoundError{Resource: "dataset", ID: dataset.ID}
+	}
+
+	// Records already holding values in a field that became a tag field are
+	// indexed now rather than waiting for the next write to each record.
+	if !slices.Equal(tagFields(previous), tagFields(dataset)) {
+		return RebuildRecordTags()
 	}
 
 	return nil
 }
 
-func DeleteDataset(id string) error {
-
+// DeleteDataset deletes a dataset with its records and views, and returns the
+// deleted records so their attachments can be released.
+func DeleteDataset(id string) ([]DataRecord, error) {
 	recordRows, err := DB.Query("SELECT id FROM data_records WHERE dataset_id = ?", id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer recordRows.Close()
 
+	var references []Reference
 	for recordRows.Next() {
 		var recordID string
 		if err := recordRows.Scan(&recordID); err != nil {
-			return err
+			return nil, err
 		}
 
-		isReferenced, err := IsRecordReferenced(recordID, id)
+		found, err := RecordReferences(recordID, id)
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, found...)
+	}
+	if len(references) > 0 {
+		return nil, &ReferencedError{Resource: "dataset", ID: id, References: references}
+	}
+
+	var deleted []DataRecord
+	err = WithTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query(
+			`SELECT id, dataset_id, data, created_at, last_modified
+             FROM data_records WHERE dataset_id = ?`,
+			id,
+		)
+		if err != nil {
+			return err
+		}
+		records, err := scanDataRecords(rows)
+		rows.Close()
 		if err != nil {
 			return err
 		}
 
-		if isReferenced {
-			return fmt.Errorf("cannot delete dataset because record %s is referenced by other records", recordID)
+		// Records go one by one so their file references are released.
+		for _, record := range records {
+			if err := deleteRecord(tx, record); err != nil {
+				return fmt.Errorf("failed to delete record %s: %w", record.ID, err)
+			}
 		}
-	}
 
-	tx, err := DB.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
+		_, err = tx.Exec("DELETE FROM saved_views WHERE dataset_id = ?", id)
+		if err != nil {
+			return err
+		}
 
-	_, err = tx.Exec("DELETE FROM data_records WHERE dataset_id = ?", id)
-	if err != nil {
-		return err
-	}
+		result, err := tx.Exec("DELETE FROM datasets WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
 
-	result, err := tx.Exec("DELETE FROM datasets WHERE id = ?", id)
-	if err != nil {
-		return err
-	}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return &NotFoundError{Resource: "dataset", ID: id}
+		}
 
-	rowsAffected, err := result.RowsAffected()
+		deleted = records
+		return nil
+	})
 	if err != nil {
-		return err
-	}
-	if rowsAffected == 0 {
-		return errors.New("dataset not found")
+		return nil, err
 	}
 
-	return tx.Commit()
+	return deleted, nil
 }
 
 func ListDatasets() ([]Dataset, error) {
-	rows, err := DB.Query(
+	return listDatasets(DB)
+}
+
+func listDatasets(q Querier) ([]Dataset, error) {
+	rows, err := q.Query(
 		`SELECT id, name, description, type, fields, created_at, last_modified 
          FROM datasets ORDER BY name`,
 	)
@@ -171,12 +228,23 @@ func ListDatasets() ([]Dataset, error) {
 	return datasets, nil
 }
 
-func AddDataRecord(record DataRecord) error {
+func AddDataRecord(op *Operation, record DataRecord) error {
+	return withOperationTx(op, func(tx *sql.Tx) error {
+		return addDataRecord(tx, record, 0)
+	})
+}
+
+func addDataRecord(q Querier, record DataRecord, depth int) error {
 	if record.ID == "" {
 		record.ID = uuid.New().String()
 	}
 
-	err := validateUniqueConstraints(record, "")
+	err := validateUniqueConstraints(q, record, "")
+	if err != nil {
+		return err
+	}
+
+	tags, err := resolveRecordTags(q, &record)
 	if err != nil {
 		return err
 	}
@@ -185,7 +253,7 @@ func AddDataRecord(record DataRecord) error {
 	record.CreatedAt = now
 	record.LastModified = now
 
-	_, err = DB.Exec(
+	_, err = q.Exec(
 		`INSERT INTO data_records (id, dataset_id, data, created_at, last_modified) 
          VALUES (?, ?, ?, ?, ?)`,
 		record.ID, record.DatasetID, record.Data, record.CreatedAt, record.LastModified,
@@ -194,16 +262,22 @@ func AddDataRecord(record DataRecord) error {
 		return err
 	}
 
-	return nil
+	err = writeRecordTags(q, record, tags)
+	if err != nil {
+		return err
+	}
+
+	err = syncFileReferences(q, record, nil, record.Data)
+	if err != nil {
+		return err
+	}
+	recordChange(q, nil, &record)
+
+	return applyRules(q, RuleEventCreate, record, nil, depth)
 }
 
 func GetDataRecord(id string, fetchRelatedData bool) (DataRecord, error) {
-	var record DataRecord
-
-	err := DB.QueryRow(
-		`SELECT id, dataset_id, data, created_at, last_modified 
-         FROM data_records WHERE id = ?`, id,
-	).Scan(&record.ID, &record.DatasetID, &record.Data, &record.CreatedAt, &record.LastModified)
+	record, err := getDataRecord(DB, id)
 	if err != nil {
 		return DataRecord{}, err
 	}
@@ -218,15 +292,46 @@ func GetDataRecord(id string, fetchRelatedData bool) (DataRecord, error) {
 	return record, nil
 }
 
-func UpdateDataRecord(record DataRecord) error {
-	err := validateUniqueConstraints(record, record.ID)
+func getDataRecord(q Querier, id string) (DataRecord, error) {
+	var record DataRecord
+
+	err := q.QueryRow(
+		`SELECT id, dataset_id, data, created_at, last_modified 
+         FROM data_records WHERE id = ?`, id,
+	).Scan(&record.ID, &record.DatasetID, &record.Data, &record.CreatedAt, &record.LastModified)
+	if err != nil {
+		return DataRecord{}, notFound("record", id, err)
+	}
+
+	return record, nil
+}
+
+func UpdateDataRecord(op *Operation, record DataRecord) error {
+	return withOperationTx(op, func(tx *sql.Tx) error {
+		return updateDataRecord(tx, record, 0)
+	})
+}
+
+func updateDataRecord(q Querier, record DataRecord, depth int) error {
+	previous, err := getDataRecord(q, record.ID)
 	if err != nil {
 		return err
 	}
 
+	err = validateUniqueConstraints(q, record, record.ID)
+	if err != nil {
+		return err
+	}
+
+	tags, err := resolveRecordTags(q, &record)
+	if err != nil {
+		return err
+	}
+
+	record.CreatedAt = previous.CreatedAt
 	record.LastModified = time.Now()
 
-	result, err := DB.Exec(
+	result, err := q.Exec(
 		`UPDATE data_records SET data = ?, last_modified = ? 
          WHERE id = ? AND dataset_id = ?`,
 		record.Data, record.LastModified, record.ID, record.DatasetID,
@@ -240,10 +345,21 @@ func UpdateDataRecord(record DataRecord) error {
 		return err
 	}
 	if rows == 0 {
-		return errors.New("record not found")
+		return &NotFoundError{Resource: "record", ID: record.ID}
 	}
 
-	return nil
+	err = writeRecordTags(q, record, tags)
+	if err != nil {
+		return err
+	}
+
+	err = syncFileReferences(q, record, previous.Data, record.Data)
+	if err != nil {
+		return err
+	}
+	recordChange(q, &previous, &record)
+
+	return applyRules(q, RuleEventUpdate, record, previous.Data, depth)
 }
 
 func loadRelatedData(record DataRecord) (DataRecord, error) {
@@ -298,42 +414,64 @@ func loadRelatedData(record DataRecord) (DataRecord, error) {
 	return record, nil
 }
 
-func DeleteDataRecord(id string) (json.RawMessage, error) {
-	record, err := GetDataRecord(id, false)
+// DeleteDataRecord deletes a record together with the records that cascade
+// from it, in one transaction. It returns every deleted record, the requested
+// one first, so their attachments can be released.
+func DeleteDataRecord(op *Operation, id string) ([]DataRecord, error) {
+	var deleted []DataRecord
+	err := withOperationTx(op, func(tx *sql.Tx) error {
+		plan, err := planDelete(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(plan.impact.Blockers) > 0 {
+			return &ReferencedError{Resource: "record", ID: id, References: plan.impact.Blockers}
+		}
+
+		// Children go first, so nothing is left pointing at a deleted parent
+		// part way through.
+		for i := len(plan.records) - 1; i >= 0; i-- {
+			if err := deleteRecord(tx, plan.records[i]); err != nil {
+				return fmt.Errorf("failed to delete record %s: %w", plan.records[i].ID, err)
+			}
+		}
+
+		deleted = plan.records
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	err = cascadeDeleteReferencedRecords(id, record.DatasetID)
+	return deleted, nil
+}
+
+func deleteRecord(q Querier, record DataRecord) error {
+	result, err := q.Exec("DELETE FROM data_records WHERE id = ?", record.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	isReferenced, err := IsRecordReferenced(id, record.DatasetID)
+	rows, err := result.RowsAffected()
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	if isReferenced {
-		return nil, errors.New("cannot delete record because it is referenced by other records")
+	if rows == 0 {
+		return &NotFoundError{Resource: "record", ID: record.ID}
 	}
 
-	recordData := record.Data
-
-	result, err := DB.Exec("DELETE FROM data_records WHERE id = ?", id)
+	_, err = q.Exec("DELETE FROM record_tags WHERE record_id = ?", record.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	rows, err := result.RowsAffected()
+	err = syncFileReferences(q, record, record.Data, nil)
 	if err != nil {
-		return nil, err
-	}
-	if rows == 0 {
-		return nil, errors.New("record not found")
+		return err
 	}
+	recordChange(q, &record, nil)
 
-	return recordData, nil
+	return nil
 }
 
 func GetDataRecords(datasetID string) ([]DataRecord, error) {
@@ -362,41 +500,59 @@ func GetDataRecords(datasetID string) ([]DataRecord, error) {
 	return records, nil
 }
 
-func ImportRecords(records []DataRecord) error {
-	tx, err := DB.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	stmt, err := tx.Prepare(
-		`INSERT INTO data_records (id, dataset_id, data, created_at, last_modified) 
+func ImportRecords(op *Operation, records []DataRecord) error {
+	return withOperationTx(op, func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(
+			`INSERT INTO data_records (id, dataset_id, data, created_at, last_modified) 
          VALUES (?, ?, ?, ?, ?)`,
-	)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	now := time.Now()
-	for i := range records {
-		if records[i].ID == "" {
-			records[i].ID = uuid.New().String()
-		}
-
-		records[i].CreatedAt = now
-		records[i].LastModified = now
-
-		_, err = stmt.Exec(
-			records[i].ID, records[i].DatasetID, records[i].Data,
-			records[i].CreatedAt, records[i].LastModified,
 		)
 		if err != nil {
 			return err
 		}
-	}
+		defer stmt.Close()
+
+		resolver := newTagResolver(tx)
+		now := time.Now()
+		for i := range records {
+			if records[i].ID == "" {
+				records[i].ID = uuid.New().String()
+			}
+
+			tags, err := resolver.resolve(&records[i])
+			if err != nil {
+				return err
+			}
+
+			records[i].CreatedAt = now
+			records[i].LastModified = now
+
+			_, err = stmt.Exec(
+				records[i].ID, records[i].DatasetID, records[i].Data,
+				records[i].CreatedAt, records[i].LastModified,
+			)
+			if err != nil {
+				return err
+			}
 
-	return tx.Commit()
+			err = writeRecordTags(tx, records[i], tags)
+			if err != nil {
+				return err
+			}
+
+			err = syncFileReferences(tx, records[i], nil, records[i].Data)
+			if err != nil {
+				return err
+			}
+			recordChange(tx, nil, &records[i])
+
+			err = applyRules(tx, RuleEventCreate, records[i], nil, 0)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func GetDataRecordsWithRelations(datasetID string, relations map[string]string) ([]map[string]interface{}, error) {
@@ -450,7 +606,7 @@ func GetDataRecordsWithRelations(datasetID string, relations map[string]string)
 				if relID != "" && isValidID(relID) {
 					relatedRecord, err := GetDataRecord(relID, true)
 					if err != nil {
-						fmt.Printf("Error fetching related record for field %s with ID %s: %v\n",
+						log.Printf("Error fetching related record for field %s with ID %s: %v\n",
 							field.Key, relID, err)
 						continue
 					}
@@ -482,55 +638,65 @@ func isValidID(id string) bool {
 	return len(id) > 8 && !strings.Contains(id, "/")
 }
 
-func IsRecordReferenced(id string, datasetID string) (bool, error) {
-
+// RecordReferences lists the records whose relation fields with
+// PreventDeleteIfReferenced point at the record.
+func RecordReferences(id string, datasetID string) ([]Reference, error) {
 	_, err := GetDataset(datasetID)
 	if err != nil {
-		return false, fmt.Errorf("failed to get dataset: %w", err)
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
 	}
 
 	datasets, err := ListDatasets()
 	if err != nil {
-		return false, fmt.Errorf("failed to list datasets: %w", err)
+		return nil, fmt.Errorf("failed to list datasets: %w", err)
 	}
 
+	var references []Reference
 	for _, otherDataset := range datasets {
 		for _, field := range otherDataset.Fields {
 
 			if field.IsRelation && field.RelatedDataset == datasetID && field.PreventDeleteIfReferenced {
 
 				query := fmt.Sprintf(
-					`SELECT COUNT(*) FROM data_records 
+					`SELECT id FROM data_records 
                      WHERE dataset_id = ? AND json_extract(data, '$.%s') = ?`,
 					field.Key)
 
-				var count int
-				err := DB.QueryRow(query, otherDataset.ID, id).Scan(&count)
+				rows, err := DB.Query(query, otherDataset.ID, id)
 				if err != nil {
-					return false, fmt.Errorf("error checking references: %w", err)
+					return nil, fmt.Errorf("error checking references: %w", err)
 				}
 
-				if count > 0 {
-					return true, nil
+				for rows.Next() {
+					reference := Reference{DatasetID: otherDataset.ID, Field: field.Key}
+					if err := rows.Scan(&reference.RecordID); err != nil {
+						rows.Close()
+						return nil, fmt.Errorf("error checking references: %w", err)
+					}
+					references = append(references, reference)
+				}
+				rows.Close()
+				if err := rows.Err(); err != nil {
+					return nil, fmt.Errorf("error checking references: %w", err)
 				}
 			}
 		}
 	}
 
-	return false, nil
+	return references, nil
 }
 
 func validateDatasetFields(fields []FieldDefinition) error {
 	for _, field := range fields {
 		if field.PreventDeleteIfReferenced && field.CascadeDeleteIfReferenced {
-			return fmt.Errorf("field '%s' cannot have both PreventDeleteIfReferenced and CascadeDeleteIfReferenced set to true", field.Key)
+			return invalid(field.Key, "field '%s' cannot have both PreventDeleteIfReferenced and CascadeDeleteIfReferenced set to true", field.Key)
 		}
 	}
 	return nil
 }
 
-func validateUniqueConstraints(record DataRecord, excludeRecordID string) error {
-	dataset, err := GetDataset(record.DatasetID)
+func validateUniqueConstraints(q Querier, record DataRecord, excludeRecordID string) error {
+	dataset, err := getDataset(q, record.DatasetID)
 	if err != nil {
 		return fmt.Errorf("failed to get dataset: %w", err)
 	}
@@ -573,77 +739,75 @@ func validateUniqueConstraints(record DataRecord, excludeRecordID string) error
 			field.Key)
 
 		var existingRecordID string
-		err := DB.QueryRow(query, record.DatasetID, fieldValueStr).Scan(&existingRecordID)
+		err := q.QueryRow(query, record.DatasetID, fieldValueStr).Scan(&existingRecordID)
 
 		if err == nil && existingRecordID != excludeRecordID {
-			return fmt.Errorf("field '%s' must be unique. Value '%s' already exists in another record", field.DisplayName, fieldValueStr)
+			return &UniqueViolationError{
+				DatasetID:     record.DatasetID,
+				Field:         field.Key,
+				FieldName:     field.DisplayName,
+				Value:         fieldValueStr,
+				ConflictingID: existingRecordID,
+			}
 		}
 	}
 
 	return nil
 }
 
-func cascadeDeleteReferencedRecords(id string, datasetID string) error {
-	_, err := GetDataset(datasetID)
+func ResetAllData(appDataDir string) error {
+	tx, err := DB.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to get dataset: %w", err)
+		return err
 	}
+	defer tx.Rollback()
 
-	datasets, err := ListDatasets()
+	_, err = tx.Exec("DELETE FROM data_records")
 	if err != nil {
-		return fmt.Errorf("failed to list datasets: %w", err)
+		return err
 	}
 
-	var recordsToDelete []string
+	_, err = tx.Exec("DELETE FROM datasets")
+	if err != nil {
+		return err
+	}
 
-	for _, otherDataset := range datasets {
-		for _, field := range otherDataset.Fields {
-			if field.IsRelation && field.RelatedDataset == datasetID && field.CascadeDeleteIfReferenced {
-				query := fmt.Sprintf(
-					`SELECT id FROM data_records 
-					 WHERE dataset_id = ? AND json_extract(data, '$.%s') = ?`,
-					field.Key)
+	_, err = tx.Exec("DELETE FROM file_owners")
+	if err != nil {
+		return err
+	}
 
-				rows, err := DB.Query(query, otherDataset.ID, id)
-				if err != nil {
-					return fmt.Errorf("error finding records to cascade delete: %w", err)
-				}
-				defer rows.Close()
+	_, err = tx.Exec("DELETE FROM generated_records")
+	if err != nil {
+		return err
+	}
 
-				for rows.Next() {
-					var recordID string
-					if err := rows.Scan(&recordID); err != nil {
-						return fmt.Errorf("error scanning record ID: %w", err)
-					}
-					recordsToDelete = append(recordsToDelete, recordID)
-				}
-			}
-		}
+	_, err = tx.Exec("DELETE FROM generated_batches")
+	if err != nil {
+		return err
 	}
 
-	for _, recordID := range recordsToDelete {
-		_, err := DeleteDataRecord(recordID)
-		if err != nil {
-			return fmt.Errorf("error cascade deleting record %s: %w", recordID, err)
-		}
+	_, err = tx.Exec("DELETE FROM files")
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
+	_, err = tx.Exec("DELETE FROM record_tags")
+	if err != nil {
+		return err
+	}
 
-func ResetAllData(appDataDir string) error {
-	tx, err := DB.Begin()
+	_, err = tx.Exec("DELETE FROM tags")
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
 
-	_, err = tx.Exec("DELETE FROM data_records")
+	_, err = tx.Exec("DELETE FROM journal_files")
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec("DELETE FROM datasets")
+	_, err = tx.Exec("DELETE FROM journal_entries")
 	if err != nil {
 		return err
 	}
@@ -683,3 +847,129 @@ func clearAllFiles(appDataDir string) error {
 
 	return nil
 }
+
+func SearchRecords(query string, datasetIDs []string, limit int) ([]DataRecord, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []DataRecord{}, nil
+	}
+
+	datasets, err := ListDatasets()
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]bool)
+	for _, id := range datasetIDs {
+		requested[id] = true
+	}
+
+	pattern := "%" + escapeLike(query) + "%"
+
+	results := []DataRecord{}
+	for _, dataset := range datasets {
+		if len(requested) > 0 && !requested[dataset.ID] {
+			continue
+		}
+
+		var conditions []string
+		args := []interface{}{dataset.ID}
+		for _, field := range dataset.Fields {
+			if field.IsSearchable {
+				conditions = append(conditions, fmt.Sprintf(`json_extract(data, '$.%s') LIKE ? ESCAPE '\'`, field.Key))
+				args = append(args, pattern)
+			}
+		}
+		if len(conditions) == 0 {
+			continue
+		}
+
+		rows, err := DB.Query(
+			`SELECT id, dataset_id, data, created_at, last_modified 
+             FROM data_records WHERE dataset_id = ? AND (`+strings.Join(conditions, " OR ")+`) ORDER BY created_at DESC`,
+			args...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error searching dataset %s: %w", dataset.ID, err)
+		}
+
+		for rows.Next() {
+			var record DataRecord
+			err := rows.Scan(&record.ID, &record.DatasetID, &record.Data, &record.CreatedAt, &record.LastModified)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			results = append(results, record)
+		}
+		rows.Close()
+
+		if limit > 0 && len(results) >= limit {
+			return results[:limit], nil
+		}
+	}
+
+	return results, nil
+}
+
+func FindRecordsReferencingFile(relativePath string) ([]DataRecord, error) {
+	needle, err := json.Marshal(relativePath)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := DB.Query(
+		`SELECT id, dataset_id, data, created_at, last_modified 
+         FROM data_records WHERE instr(data, ?) > 0`,
+		string(needle),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []DataRecord
+	for rows.Next() {
+		var record DataRecord
+		var data []byte
+		err := rows.Scan(&record.ID, &record.DatasetID, &data, &record.CreatedAt, &record.LastModified)
+		if err != nil {
+			return nil, err
+		}
+		record.Data = data
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+func GetAllDataRecords() ([]DataRecord, error) {
+	rows, err := DB.Query(
+		`SELECT id, dataset_id, data, created_at, last_modified 
+         FROM data_records ORDER BY dataset_id, created_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []DataRecord
+	for rows.Next() {
+		var record DataRecord
+		var data []byte
+		err := rows.Scan(&record.ID, &record.DatasetID, &data, &record.CreatedAt, &record.LastModified)
+		if err != nil {
+			return nil, err
+		}
+		record.Data = data
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
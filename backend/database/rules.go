@@ -0,0 +1,685 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RuleEventCreate = "create"
+	RuleEventUpdate = "update"
+)
+
+const (
+	RuleActionCreate = "create"
+	RuleActionUpdate = "update"
+)
+
+const (
+	RuleOperatorEquals      = "equals"
+	RuleOperatorNotEquals   = "not_equals"
+	RuleOperatorGreaterThan = "greater_than"
+	RuleOperatorLessThan    = "less_than"
+	RuleOperatorContains    = "contains"
+	RuleOperatorIsSet       = "is_set"
+	RuleOperatorIsEmpty     = "is_empty"
+	RuleOperatorChanged     = "changed"
+)
+
+const (
+	RuleSourceRecordID = "$id"
+	RuleSourceToday    = "$today"
+	RuleSourceNow      = "$now"
+)
+
+const maxRuleDepth = 5
+
+type RuleCondition struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value,omitempty"`
+}
+
+type RuleFieldMapping struct {
+	Target string      `json:"target"`
+	Source string      `json:"source,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
+type RuleAction struct {
+	Type              string             `json:"type"`
+	TargetDataset     string             `json:"targetDataset"`
+	TargetRecordField string             `json:"targetRecordField,omitempty"`
+	TargetConditions  []RuleCondition    `json:"targetConditions,omitempty"`
+	TargetFilter      string             `json:"targetFilter,omitempty"`
+	Mappings          []RuleFieldMapping `json:"mappings"`
+}
+
+type Rule struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	Enabled       bool            `json:"enabled"`
+	SourceDataset string          `json:"sourceDataset"`
+	Events        []string        `json:"events"`
+	Conditions    []RuleCondition `json:"conditions"`
+	Filter        string          `json:"filter,omitempty"`
+	Action        RuleAction      `json:"action"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	LastModified  time.Time       `json:"lastModified"`
+}
+
+func InitializeRules(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS automation_rules (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			description TEXT,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			source_dataset TEXT NOT NULL,
+			events TEXT NOT NULL,
+			conditions TEXT NOT NULL,
+			action TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			last_modified TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_automation_rules_source ON automation_rules(source_dataset, enabled)
+	`)
+	if err != nil {
+		return err
+	}
+
+	return addColumnIfMissing(db, "automation_rules", "filter", "TEXT NOT NULL DEFAULT ''")
+}
+
+func CreateRule(rule Rule) (Rule, error) {
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+
+	err := validateRule(rule)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.LastModified = now
+
+	events, conditions, action, err := marshalRule(rule)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	_, err = DB.Exec(
+		`INSERT INTO automation_rules (id, name, description, enabled, source_dataset, events, conditions, filter, action, created_at, last_modified)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Name, rule.Description, rule.Enabled, rule.SourceDataset, events, conditions, rule.Filter, action, rule.CreatedAt, rule.LastModified,
+	)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	return rule, nil
+}
+
+func UpdateRule(rule Rule) (Rule, error) {
+	err := validateRule(rule)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	rule.LastModified = time.Now()
+
+	events, conditions, action, err := marshalRule(rule)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	result, err := DB.Exec(
+		`UPDATE automation_rules SET name = ?, description = ?, enabled = ?, source_dataset = ?, events = ?, conditions = ?, filter = ?, action = ?, last_modified = ?
+         WHERE id = ?`,
+		rule.Name, rule.Description, rule.Enabled, rule.SourceDataset, events, conditions, rule.Filter, action, rule.LastModified, rule.ID,
+	)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return Rule{}, err
+	}
+	if rows == 0 {
+		return Rule{}, &NotFoundError{Resource: "rule", ID: rule.ID}
+	}
+
+	return GetRule(rule.ID)
+}
+
+func DeleteRule(id string) error {
+	result, err := DB.Exec("DELETE FROM automation_rules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &NotFoundError{Resource: "rule", ID: id}
+	}
+
+	return nil
+}
+
+func GetRule(id string) (Rule, error) {
+	rules, err := queryRules(DB, "WHERE id = ?", id)
+	if err != nil {
+		return Rule{}, err
+	}
+	if len(rules) == 0 {
+		return Rule{}, &NotFoundError{Resource: "rule", ID: id}
+	}
+
+	return rules[0], nil
+}
+
+func ListRules() ([]Rule, error) {
+	return queryRules(DB, "ORDER BY name")
+}
+
+func SyncDefaultRules() error {
+	for _, rule := range GetDefaultRules() {
+		var exists int
+		err := DB.QueryRow("SELECT COUNT(*) FROM automation_rules WHERE id = ?", rule.ID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			continue
+		}
+
+		_, err = CreateRule(rule)
+		if err != nil {
+			return fmt.Errorf("failed to create default rule %s: %w", rule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func GetDefaultRules() []Rule {
+	return []Rule{
+		{
+			ID:            "default-metric-last-occurrence",
+			Name:          "Track last occurrence of interval metrics",
+			Description:   "When a daily log is created for a metric on an interval schedule, store the log date as the metric's last occurrence",
+			Enabled:       true,
+			SourceDataset: DatasetIDDailyLog,
+			Events:        []string{RuleEventCreate},
+			Conditions: []RuleCondition{
+				{Field: "metric_id", Operator: RuleOperatorIsSet},
+				{Field: "date", Operator: RuleOperatorIsSet},
+			},
+			Action: RuleAction{
+				Type:              RuleActionUpdate,
+				TargetDataset:     DatasetIDMetric,
+				TargetRecordField: "metric_id",
+				TargetConditions: []RuleCondition{
+					{Field: "schedule_frequency", Operator: RuleOperatorEquals, Value: "interval"},
+				},
+				Mappings: []RuleFieldMapping{
+					{Target: "schedule_last_occurrence", Source: "date"},
+				},
+			},
+		},
+		{
+			ID:            "default-todo-completion-log",
+			Name:          "Log related metric when a todo is completed",
+			Description:   "When a todo with a related metric is marked complete, add a daily log for that metric",
+			Enabled:       false,
+			SourceDataset: DatasetIDTodos,
+			Events:        []string{RuleEventUpdate},
+			Conditions: []RuleCondition{
+				{Field: "is_complete", Operator: RuleOperatorEquals, Value: true},
+				{Field: "is_complete", Operator: RuleOperatorChanged},
+				{Field: "related_metric_id", Operator: RuleOperatorIsSet},
+			},
+			Action: RuleAction{
+				Type:          RuleActionCreate,
+				TargetDataset: DatasetIDDailyLog,
+				Mappings: []RuleFieldMapping{
+					{Target: "date", Source: RuleSourceToday},
+					{Target: "metric_id", Source: "related_metric_id"},
+					{Target: "value", Value: "true"},
+					{Target: "notes", Source: "title"},
+				},
+			},
+		},
+		{
+			ID:            "default-meeting-follow-up-todo",
+			Name:          "Create a todo for meeting follow-ups",
+			Description:   "When a meeting needs a follow-up, add a todo due on the follow-up date",
+			Enabled:       false,
+			SourceDataset: DatasetIDMeetings,
+			Events:        []string{RuleEventCreate, RuleEventUpdate},
+			Conditions: []RuleCondition{
+				{Field: "follow_up_needed", Operator: RuleOperatorEquals, Value: true},
+				{Field: "follow_up_needed", Operator: RuleOperatorChanged},
+			},
+			Action: RuleAction{
+				Type:          RuleActionCreate,
+				TargetDataset: DatasetIDTodos,
+				Mappings: []RuleFieldMapping{
+					{Target: "title", Value: "Follow up on meeting"},
+					{Target: "description", Source: "description"},
+					{Target: "deadline", Source: "follow_up_date"},
+					{Target: "priority", Value: "medium"},
+					{Target: "tags", Source: "tags"},
+					{Target: "is_complete", Value: false},
+					{Target: "private", Source: "private"},
+				},
+			},
+		},
+	}
+}
+
+func validateRule(rule Rule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return invalid("name", "rule name is required")
+	}
+
+	source, err := GetDataset(rule.SourceDataset)
+	if err != nil {
+		return invalid("sourceDataset", "unknown source dataset '%s'", rule.SourceDataset)
+	}
+	if _, err := CompileFilter(source, rule.Filter); err != nil {
+		return invalid("filter", "%v", err)
+	}
+
+	if len(rule.Events) == 0 {
+		return invalid("events", "rule needs at least one event")
+	}
+	for _, event := range rule.Events {
+		if event != RuleEventCreate && event != RuleEventUpdate {
+			return invalid("events", "unknown rule event '%s'", event)
+		}
+	}
+
+	for _, condition := range append(rule.Conditions, rule.Action.TargetConditions...) {
+		if condition.Field == "" {
+			return invalid("conditions", "rule condition needs a field")
+		}
+		if !isKnownRuleOperator(condition.Operator) {
+			return invalid("conditions", "unknown rule operator '%s'", condition.Operator)
+		}
+	}
+	for _, condition := range rule.Action.TargetConditions {
+		// The target record is not being changed, so it has no earlier value.
+		if condition.Operator == RuleOperatorChanged {
+			return invalid("action.targetConditions", "target conditions cannot use '%s'", RuleOperatorChanged)
+		}
+	}
+
+	target, err := GetDataset(rule.Action.TargetDataset)
+	if err != nil {
+		return invalid("action.targetDataset", "unknown target dataset '%s'", rule.Action.TargetDataset)
+	}
+	if _, err := CompileFilter(target, rule.Action.TargetFilter); err != nil {
+		return invalid("action.targetFilter", "target %v", err)
+	}
+
+	switch rule.Action.Type {
+	case RuleActionCreate:
+	case RuleActionUpdate:
+		if rule.Action.TargetRecordField == "" {
+			return invalid("action.targetRecordField", "update rules need the field that holds the target record ID")
+		}
+	default:
+		return invalid("action.type", "unknown rule action '%s'", rule.Action.Type)
+	}
+
+	if len(rule.Action.Mappings) == 0 {
+		return invalid("action.mappings", "rule action needs at least one field mapping")
+	}
+	for _, mapping := range rule.Action.Mappings {
+		if mapping.Target == "" {
+			return invalid("action.mappings", "field mapping needs a target field")
+		}
+	}
+
+	return nil
+}
+
+func isKnownRuleOperator(operator string) bool {
+	switch operator {
+	case RuleOperatorEquals, RuleOperatorNotEquals, RuleOperatorGreaterThan, RuleOperatorLessThan,
+		RuleOperatorContains, RuleOperatorIsSet, RuleOperatorIsEmpty, RuleOperatorChanged:
+		return true
+	}
+	return false
+}
+
+func marshalRule(rule Rule) (string, string, string, error) {
+	events, err := json.Marshal(rule.Events)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	action, err := json.Marshal(rule.Action)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return string(events), string(conditions), string(action), nil
+}
+
+func queryRules(q Querier, where string, args ...interface{}) ([]Rule, error) {
+	rows, err := q.Query(
+		`SELECT id, name, description, enabled, source_dataset, events, conditions, filter, action, created_at, last_modified
+         FROM automation_rules `+where,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []Rule{}
+	for rows.Next() {
+		var rule Rule
+		var description sql.NullString
+		var events, conditions, action string
+
+		err := rows.Scan(&rule.ID, &rule.Name, &description, &rule.Enabled, &rule.SourceDataset, &events, &conditions, &rule.Filter, &action, &rule.CreatedAt, &rule.LastModified)
+		if err != nil {
+			return nil, err
+		}
+		rule.Description = description.String
+
+		if err := json.Unmarshal([]byte(events), &rule.Events); err != nil {
+			return nil, fmt.Errorf("invalid events for rule %s: %w", rule.ID, err)
+		}
+		if err := json.Unmarshal([]byte(conditions), &rule.Conditions); err != nil {
+			return nil, fmt.Errorf("invalid conditions for rule %s: %w", rule.ID, err)
+		}
+		if err := json.Unmarshal([]byte(action), &rule.Action); err != nil {
+			return nil, fmt.Errorf("invalid action for rule %s: %w", rule.ID, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func applyRules(q Querier, event string, record DataRecord, previousData json.RawMessage, depth int) error {
+	rules, err := queryRules(q, "WHERE enabled = 1 AND source_dataset = ?", record.DatasetID)
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	if depth >= maxRuleDepth {
+		return fmt.Errorf("rules triggered each other more than %d times, check for loops", maxRuleDepth)
+	}
+
+	var data map[string]interface{}
+	err = json.Unmarshal(record.Data, &data)
+	if err != nil {
+		return err
+	}
+
+	var previous map[string]interface{}
+	if previousData != nil {
+		err = json.Unmarshal(previousData, &previous)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range rules {
+		if !containsString(rule.Events, event) || !ruleConditionsMatch(rule.Conditions, data, previous) {
+			continue
+		}
+
+		matches, err := recordMatchesFilter(q, record.ID, rule.SourceDataset, rule.Filter)
+		if err != nil {
+			return fmt.Errorf("rule '%s' failed: %w", rule.Name, err)
+		}
+		if !matches {
+			continue
+		}
+
+		err = runRuleAction(q, rule, record.ID, data, depth)
+		if err != nil {
+			return fmt.Errorf("rule '%s' failed: %w", rule.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func runRuleAction(q Querier, rule Rule, sourceID string, source map[string]interface{}, depth int) error {
+	mapped := make(map[string]interface{})
+	for _, mapping := range rule.Action.Mappings {
+		value := resolveRuleMapping(mapping, sourceID, source)
+		if value != nil {
+			mapped[mapping.Target] = value
+		}
+	}
+
+	switch rule.Action.Type {
+	case RuleActionCreate:
+		data, err := json.Marshal(mapped)
+		if err != nil {
+			return err
+		}
+
+		return addDataRecord(q, DataRecord{
+			ID:        uuid.New().String(),
+			DatasetID: rule.Action.TargetDataset,
+			Data:      data,
+		}, depth+1)
+
+	case RuleActionUpdate:
+		targetID, ok := source[rule.Action.TargetRecordField].(string)
+		if !ok || targetID == "" {
+			return nil
+		}
+
+		target, err := getDataRecord(q, targetID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		if target.DatasetID != rule.Action.TargetDataset {
+			return nil
+		}
+
+		var targetData map[string]interface{}
+		err = json.Unmarshal(target.Data, &targetData)
+		if err != nil {
+			return err
+		}
+
+		if !ruleConditionsMatch(rule.Action.TargetConditions, targetData, targetData) {
+			return nil
+		}
+
+		matches, err := recordMatchesFilter(q, target.ID, target.DatasetID, rule.Action.TargetFilter)
+		if err != nil || !matches {
+			return err
+		}
+
+		for key, value := range mapped {
+			targetData[key] = value
+		}
+
+		target.Data, err = json.Marshal(targetData)
+		if err != nil {
+			return err
+		}
+
+		return updateDataRecord(q, target, depth+1)
+	}
+
+	return fmt.Errorf("unknown rule action '%s'", rule.Action.Type)
+}
+
+// recordMatchesFilter checks a stored record against a filter, so rules see
+// the record as it was just written inside the same transaction.
+func recordMatchesFilter(q Querier, recordID string, datasetID string, filter string) (bool, error) {
+	if filter == "" {
+		return true, nil
+	}
+
+	dataset, err := getDataset(q, datasetID)
+	if err != nil {
+		return false, err
+	}
+	compiled, err := CompileFilter(dataset, filter)
+	if err != nil {
+		return false, err
+	}
+
+	var matches bool
+	err = q.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM data_records WHERE id = ?`+compiled.Where()+`)`,
+		append([]interface{}{recordID}, compiled.Args...)...,
+	).Scan(&matches)
+	return matches, err
+}
+
+func resolveRuleMapping(mapping RuleFieldMapping, sourceID string, source map[string]interface{}) interface{} {
+	switch mapping.Source {
+	case "":
+		return mapping.Value
+	case RuleSourceRecordID:
+		return sourceID
+	case RuleSourceToday:
+		return time.Now().Format("2006-01-02")
+	case RuleSourceNow:
+		return time.Now().Format(time.RFC3339)
+	default:
+		return source[mapping.Source]
+	}
+}
+
+func ruleConditionsMatch(conditions []RuleCondition, data map[string]interface{}, previous map[string]interface{}) bool {
+	for _, condition := range conditions {
+		value, exists := data[condition.Field]
+
+		switch condition.Operator {
+		case RuleOperatorIsSet:
+			if !exists || isEmptyRuleValue(value) {
+				return false
+			}
+		case RuleOperatorIsEmpty:
+			if exists && !isEmptyRuleValue(value) {
+				return false
+			}
+		case RuleOperatorChanged:
+			if previous != nil && ruleValuesEqual(value, previous[condition.Field]) {
+				return false
+			}
+		case RuleOperatorEquals:
+			if !ruleValuesEqual(value, condition.Value) {
+				return false
+			}
+		case RuleOperatorNotEquals:
+			if ruleValuesEqual(value, condition.Value) {
+				return false
+			}
+		case RuleOperatorGreaterThan, RuleOperatorLessThan:
+			left, leftOK := ruleNumber(value)
+			right, rightOK := ruleNumber(condition.Value)
+			if !leftOK || !rightOK {
+				return false
+			}
+			if condition.Operator == RuleOperatorGreaterThan && !(left > right) {
+				return false
+			}
+			if condition.Operator == RuleOperatorLessThan && !(left < right) {
+				return false
+			}
+		case RuleOperatorContains:
+			if !strings.Contains(strings.ToLower(fmt.Sprintf("%v", value)), strings.ToLower(fmt.Sprintf("%v", condition.Value))) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func ruleValuesEqual(a interface{}, b interface{}) bool {
+	if isEmptyRuleValue(a) && isEmptyRuleValue(b) {
+		return true
+	}
+
+	if left, ok := ruleNumber(a); ok {
+		if right, ok := ruleNumber(b); ok {
+			return left == right
+		}
+	}
+
+	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+func ruleNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+func isEmptyRuleValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return s == ""
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
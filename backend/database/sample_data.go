@@ -920,7 +920,7 @@ func addSampleRecords(datasetID string, samples []map[string]interface{}) error
 			Data:      dataJSON,
 		}
 
-		if err := AddDataRecord(record); err != nil {
+		if err := AddDataRecord(nil, record); err != nil {
 			return err
 		}
 	}
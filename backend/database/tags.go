@@ -0,0 +1,504 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag fields keep their value as a comma-separated string, which is what the
+// forms edit. Every name in it refers to a row in tags, and record_tags
+// mirrors which records use which tag so tags can be counted and changed
+// across all datasets at once.
+
+type Tag struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Usage     int       `json:"usage"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const tagSeparator = ", "
+
+func InitializeTags(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			created_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS record_tags (
+			record_id TEXT NOT NULL,
+			dataset_id TEXT NOT NULL,
+			field TEXT NOT NULL,
+			tag_id TEXT NOT NULL,
+			PRIMARY KEY (record_id, field, tag_id)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_record_tags_tag ON record_tags(tag_id)
+	`)
+	return err
+}
+
+// SplitTags reads a tag value, either a comma-separated string or a list,
+// into trimmed names without blanks or case-insensitive duplicates.
+func SplitTags(value interface{}) []string {
+	var parts []string
+	switch v := value.(type) {
+	case string:
+		parts = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+	case []string:
+		parts = v
+	}
+
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, part := range parts {
+		name := strings.TrimSpace(part)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func JoinTags(names []string) string {
+	return strings.Join(names, tagSeparator)
+}
+
+func tagFields(dataset Dataset) []string {
+	var keys []string
+	for _, field := range dataset.Fields {
+		if field.Type == FieldTypeTags {
+			keys = append(keys, field.Key)
+		}
+	}
+	return keys
+}
+
+type recordTag struct {
+	field string
+	tagID string
+}
+
+// tagResolver maps names to tags, creating the ones that do not exist yet.
+// It caches for the length of one operation.
+type tagResolver struct {
+	q        Querier
+	byName   map[string]Tag
+	datasets map[string][]string
+}
+
+func newTagResolver(q Querier) *tagResolver {
+	return &tagResolver{q: q, byName: make(map[string]Tag), datasets: make(map[string][]string)}
+}
+
+func (r *tagResolver) fields(datasetID string) ([]string, error) {
+	if keys, ok := r.datasets[datasetID]; ok {
+		return keys, nil
+	}
+
+	dataset, err := getDataset(r.q, datasetID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	keys := tagFields(dataset)
+	r.datasets[datasetID] = keys
+	return keys, nil
+}
+
+func (r *tagResolver) tag(name string) (Tag, error) {
+	key := strings.ToLower(name)
+	if tag, ok := r.byName[key]; ok {
+		return tag, nil
+	}
+
+	var tag Tag
+	err := r.q.QueryRow(`SELECT id, name, created_at FROM tags WHERE name = ?`, name).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		tag = Tag{ID: uuid.New().String(), Name: name, CreatedAt: time.Now()}
+		_, err = r.q.Exec(`INSERT INTO tags (id, name, created_at) VALUES (?, ?, ?)`, tag.ID, tag.Name, tag.CreatedAt)
+	}
+	if err != nil {
+		return Tag{}, err
+	}
+
+	r.byName[key] = tag
+	return tag, nil
+}
+
+// resolve rewrites the record's tag fields with the stored spelling of each
+// tag and returns the tags it uses. Data without tag fields is left as is.
+func (r *tagResolver) resolve(record *DataRecord) ([]recordTag, error) {
+	keys, err := r.fields(record.DatasetID)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(record.Data, &data); err != nil {
+		return nil, err
+	}
+
+	var tags []recordTag
+	changed := false
+	for _, key := range keys {
+		value, exists := data[key]
+		if !exists || value == nil {
+			continue
+		}
+
+		var names []string
+		for _, name := range SplitTags(value) {
+			tag, err := r.tag(name)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, tag.Name)
+			tags = append(tags, recordTag{field: key, tagID: tag.ID})
+		}
+
+		if joined := JoinTags(names); value != joined {
+			data[key] = joined
+			changed = true
+		}
+	}
+
+	if changed {
+		record.Data, err = json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
+
+// resolveRecordTags is resolve for a single record write.
+func resolveRecordTags(q Querier, record *DataRecord) ([]recordTag, error) {
+	return newTagResolver(q).resolve(record)
+}
+
+func writeRecordTags(q Querier, record DataRecord, tags []recordTag) error {
+	_, err := q.Exec("DELETE FROM record_tags WHERE record_id = ?", record.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err := q.Exec(
+			`INSERT OR IGNORE INTO record_tags (record_id, dataset_id, field, tag_id) VALUES (?, ?, ?, ?)`,
+			record.ID, record.DatasetID, tag.field, tag.tagID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildRecordTags brings every tag field in line with the tags table and
+// rebuilds record_tags. On the first run it turns the old free-text tag
+// strings into tags.
+func RebuildRecordTags() error {
+	datasets, err := ListDatasets()
+	if err != nil {
+		return err
+	}
+
+	return WithTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec("DELETE FROM record_tags")
+		if err != nil {
+			return err
+		}
+
+		resolver := newTagResolver(tx)
+		rewritten := 0
+		for _, dataset := range datasets {
+			if len(tagFields(dataset)) == 0 {
+				continue
+			}
+
+			records, err := queryDataRecords(tx, dataset.ID, RecordQuery{})
+			if err != nil {
+				return err
+			}
+
+			for _, record := range records {
+				original := string(record.Data)
+				tags, err := resolver.resolve(&record)
+				if err != nil {
+					return fmt.Errorf("record %s: %w", record.ID, err)
+				}
+
+				if string(record.Data) != original {
+					_, err = tx.Exec("UPDATE data_records SET data = ? WHERE id = ?", []byte(record.Data), record.ID)
+					if err != nil {
+						return err
+					}
+					rewritten++
+				}
+
+				if err := writeRecordTags(tx, record, tags); err != nil {
+					return err
+				}
+			}
+		}
+
+		if rewritten > 0 {
+			log.Printf("Normalized tags in %d record(s)\n", rewritten)
+		}
+		return nil
+	})
+}
+
+func ListTags() ([]Tag, error) {
+	rows, err := DB.Query(`
+		SELECT t.id, t.name, t.created_at, COUNT(DISTINCT r.id)
+		FROM tags t
+		LEFT JOIN record_tags rt ON rt.tag_id = t.id
+		LEFT JOIN data_records r ON r.id = rt.record_id
+		GROUP BY t.id
+		ORDER BY t.name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.Usage); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func getTag(q Querier, id string) (Tag, error) {
+	var tag Tag
+	err := q.QueryRow(`SELECT id, name, created_at FROM tags WHERE id = ?`, id).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	return tag, notFound("tag", id, err)
+}
+
+// RenameTag renames a tag in every record that uses it. Renaming onto the
+// name of another tag is refused; merge the two instead.
+func RenameTag(op *Operation, id string, name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, ",") {
+		return 0, invalid("name", "tag names cannot be empty or contain commas")
+	}
+
+	updated := 0
+	err := withOperationTx(op, func(tx *sql.Tx) error {
+		tag, err := getTag(tx, id)
+		if err != nil {
+			return err
+		}
+
+		var other string
+		err = tx.QueryRow(`SELECT id FROM tags WHERE name = ? AND id != ?`, name, id).Scan(&other)
+		if err == nil {
+			return &ConflictError{Resource: "tag", ID: id, ConflictingID: other, Message: fmt.Sprintf("a tag named %q already exists, merge the tags instead", name)}
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE tags SET name = ? WHERE id = ?`, name, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err = rewriteTaggedRecords(tx, []string{id}, func(current string) string {
+			if strings.EqualFold(current, tag.Name) {
+				return name
+			}
+			return current
+		})
+		return err
+	})
+
+	return updated, err
+}
+
+// MergeTags replaces the source tags with the target tag in every record and
+// deletes the sources.
+func MergeTags(op *Operation, targetID string, sourceIDs []string) (int, error) {
+	updated := 0
+	err := withOperationTx(op, func(tx *sql.Tx) error {
+		target, err := getTag(tx, targetID)
+		if err != nil {
+			return err
+		}
+
+		sources := make(map[string]bool)
+		var ids []string
+		for _, id := range sourceIDs {
+			if id == targetID {
+				continue
+			}
+			source, err := getTag(tx, id)
+			if err != nil {
+				return err
+			}
+			sources[strings.ToLower(source.Name)] = true
+			ids = append(ids, id)
+		}
+		if len(ids) == 0 {
+			return invalid("sourceIds", "no tags to merge")
+		}
+
+		updated, err = rewriteTaggedRecords(tx, ids, func(current string) string {
+			if sources[strings.ToLower(current)] {
+				return target.Name
+			}
+			return current
+		})
+		if err != nil {
+			return err
+		}
+
+		return deleteTags(tx, ids)
+	})
+
+	return updated, err
+}
+
+// DeleteTag removes a tag from every record and deletes it.
+func DeleteTag(op *Operation, id string) (int, error) {
+	updated := 0
+	err := withOperationTx(op, func(tx *sql.Tx) error {
+		tag, err := getTag(tx, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err = rewriteTaggedRecords(tx, []string{id}, func(current string) string {
+			if strings.EqualFold(current, tag.Name) {
+				return ""
+			}
+			return current
+		})
+		if err != nil {
+			return err
+		}
+
+		return deleteTags(tx, []string{id})
+	})
+
+	return updated, err
+}
+
+func deleteTags(q Querier, ids []string) error {
+	for _, id := range ids {
+		if _, err := q.Exec("DELETE FROM record_tags WHERE tag_id = ?", id); err != nil {
+			return err
+		}
+		if _, err := q.Exec("DELETE FROM tags WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteTaggedRecords passes every tag name in the records using one of the
+// given tags through rewrite, an empty result dropping the name. Records are
+// written directly, without automation rules, like other bulk repairs.
+func rewriteTaggedRecords(q Querier, tagIDs []string, rewrite func(string) string) (int, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tagIDs)), ", ")
+	args := make([]interface{}, len(tagIDs))
+	for i, id := range tagIDs {
+		args[i] = id
+	}
+
+	rows, err := q.Query(
+		`SELECT id, dataset_id, data, created_at, last_modified FROM data_records
+         WHERE id IN (SELECT record_id FROM record_tags WHERE tag_id IN (`+placeholders+`))`,
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	records, err := scanDataRecords(rows)
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	resolver := newTagResolver(q)
+	now := time.Now()
+	for _, record := range records {
+		previous := record
+		keys, err := resolver.fields(record.DatasetID)
+		if err != nil {
+			return 0, err
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal(record.Data, &data); err != nil {
+			return 0, fmt.Errorf("record %s: %w", record.ID, err)
+		}
+		for _, key := range keys {
+			if _, exists := data[key]; !exists {
+				continue
+			}
+			var names []string
+			for _, name := range SplitTags(data[key]) {
+				if renamed := rewrite(name); renamed != "" {
+					names = append(names, renamed)
+				}
+			}
+			data[key] = JoinTags(SplitTags(names))
+		}
+
+		record.Data, err = json.Marshal(data)
+		if err != nil {
+			return 0, err
+		}
+		tags, err := resolver.resolve(&record)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = q.Exec("UPDATE data_records SET data = ?, last_modified = ? WHERE id = ?", []byte(record.Data), now, record.ID)
+		if err != nil {
+			return 0, err
+		}
+		if err := writeRecordTags(q, record, tags); err != nil {
+			return 0, err
+		}
+		record.LastModified = now
+		recordChange(q, &previous, &record)
+	}
+
+	return len(records), nil
+}
@@ -0,0 +1,344 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimelineQuery selects records of several datasets by their primary date.
+// From and To are dates or timestamps; a date-only To includes that day.
+// Without DatasetIDs every dataset with a date field is included.
+type TimelineQuery struct {
+	From       string   `json:"from,omitempty"`
+	To         string   `json:"to,omitempty"`
+	DatasetIDs []string `json:"datasetIds,omitempty"`
+	Limit      int      `json:"limit,omitempty"`
+	Offset     int      `json:"offset,omitempty"`
+}
+
+type TimelineEntry struct {
+	RecordID  string    `json:"recordId"`
+	DatasetID string    `json:"datasetId"`
+	DateField string    `json:"dateField"`
+	Date      time.Time `json:"date"`
+	Title     string    `json:"title"`
+	Private   bool      `json:"private,omitempty"`
+}
+
+// TimelineDataset is a dataset on the timeline with its display name and the
+// date field its records are placed by.
+type TimelineDataset struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	DateField string `json:"dateField"`
+}
+
+type TimelinePage struct {
+	Entries  []TimelineEntry   `json:"entries"`
+	Datasets []TimelineDataset `json:"datasets"`
+	Total    int               `json:"total"`
+	Offset   int               `json:"offset"`
+	Limit    int               `json:"limit"`
+}
+
+const defaultTimelineLimit = 100
+
+// timelineDateKeys are the fields that say when a record happened, most
+// telling first. Datasets without any of them use their first date field.
+var timelineDateKeys = []string{"date", "start_time", "meeting_date", "note_date", "start_date", "deadline", "reminder_date", "learned_date", "since_date", "first_met_date"}
+
+// timelineTitles are the title templates of the built-in datasets. Relation
+// fields are replaced by the title of the record they point to.
+var timelineTitles = map[string]string{
+	DatasetIDBodyMeasurements:    "{measurement}: {value} {unit}",
+	DatasetIDFinancialLogs:       "{description}: {amount}",
+	DatasetIDFinancialBalances:   "{account_name}: {amount}",
+	DatasetIDPaycheckInfo:        "{category}: {amount}",
+	DatasetIDBloodwork:           "Bloodwork at {lab_name}",
+	DatasetIDDailyLog:            "{metric_id}: {value}",
+	DatasetIDMeetings:            "Meeting with {person_id}",
+	DatasetIDPersonNotes:         "Note about {person_id}",
+	DatasetIDPersonAttributes:    "{person_id}: {attribute_name}",
+	DatasetIDBirthdayReminders:   "{person_id}'s birthday",
+	DatasetIDPersonRelationships: "{person1_id} and {person2_id}: {relationship_type}",
+}
+
+// timelineTitleKeys are tried in order for datasets without a template, or
+// when a template's fields are empty.
+var timelineTitleKeys = []string{"title", "name", "description", "entry", "affirmation", "content", "measurement", "category", "notes"}
+
+var titlePlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+const timelineTitleLength = 80
+
+// TimelineDateField returns the field a dataset's records are placed on the
+// timeline by, and false when the dataset has no date field.
+func TimelineDateField(dataset Dataset) (FieldDefinition, bool) {
+	dates := make(map[string]FieldDefinition)
+	var first *FieldDefinition
+	for i, field := range dataset.Fields {
+		if field.Type != FieldTypeDate || field.IsRelation || !indexableKey.MatchString(field.Key) {
+			continue
+		}
+		dates[field.Key] = field
+		if first == nil {
+			first = &dataset.Fields[i]
+		}
+	}
+
+	for _, key := range timelineDateKeys {
+		if field, ok := dates[key]; ok {
+			return field, true
+		}
+	}
+	if first != nil {
+		return *first, true
+	}
+	return FieldDefinition{}, false
+}
+
+// QueryTimeline merges the records of the selected datasets into one stream
+// ordered by their primary date, oldest first.
+func QueryTimeline(query TimelineQuery) (TimelinePage, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultTimelineLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+	page := TimelinePage{Entries: []TimelineEntry{}, Datasets: []TimelineDataset{}, Offset: query.Offset, Limit: query.Limit}
+
+	from, until, err := timelineRange(query.From, query.To)
+	if err != nil {
+		return TimelinePage{}, err
+	}
+
+	datasets, err := timelineDatasets(query.DatasetIDs)
+	if err != nil {
+		return TimelinePage{}, err
+	}
+
+	var branches []string
+	var args []interface{}
+	fields := make(map[string]FieldDefinition)
+	names := make(map[string]Dataset)
+	for _, dataset := range datasets {
+		field, ok := TimelineDateField(dataset)
+		if !ok {
+			if len(query.DatasetIDs) > 0 {
+				return TimelinePage{}, fmt.Errorf("dataset %s has no date field", dataset.ID)
+			}
+			continue
+		}
+		fields[dataset.ID] = field
+		names[dataset.ID] = dataset
+		page.Datasets = append(page.Datasets, TimelineDataset{ID: dataset.ID, Name: dataset.Name, DateField: field.Key})
+
+		expression := FieldExpression(field)
+		branch := fmt.Sprintf(
+			`SELECT id, dataset_id, data, %s AS at FROM data_records WHERE dataset_id = %s AND %s IS NOT NULL`,
+			expression, DatasetLiteral(dataset.ID), expression,
+		)
+		if from != "" {
+			branch += fmt.Sprintf(" AND %s >= ?", expression)
+			args = append(args, from)
+		}
+		if until != "" {
+			branch += fmt.Sprintf(" AND %s < ?", expression)
+			args = append(args, until)
+		}
+		branches = append(branches, branch)
+	}
+	if len(branches) == 0 {
+		return page, nil
+	}
+	union := strings.Join(branches, " UNION ALL ")
+
+	err = DB.QueryRow("SELECT COUNT(*) FROM ("+union+")", args...).Scan(&page.Total)
+	if err != nil {
+		return TimelinePage{}, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT id, dataset_id, data, at FROM ("+union+") ORDER BY at, id LIMIT ? OFFSET ?",
+		append(args, query.Limit, query.Offset)...,
+	)
+	if err != nil {
+		return TimelinePage{}, err
+	}
+
+	type timelineRow struct {
+		entry TimelineEntry
+		data  map[string]interface{}
+	}
+	var found []timelineRow
+	for rows.Next() {
+		var row timelineRow
+		var data []byte
+		var at string
+		if err := rows.Scan(&row.entry.RecordID, &row.entry.DatasetID, &data, &at); err != nil {
+			rows.Close()
+			return TimelinePage{}, err
+		}
+		if err := json.Unmarshal(data, &row.data); err != nil {
+			rows.Close()
+			return TimelinePage{}, fmt.Errorf("record %s: %w", row.entry.RecordID, err)
+		}
+		row.entry.Date, err = time.Parse(filterDateLayout, at)
+		if err != nil {
+			rows.Close()
+			return TimelinePage{}, err
+		}
+		found = append(found, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return TimelinePage{}, err
+	}
+
+	// Titles are built once the rows are closed, since relations are looked
+	// up along the way.
+	titles := &timelineTitler{related: make(map[string]string)}
+	for _, row := range found {
+		row.entry.DateField = fields[row.entry.DatasetID].Key
+		row.entry.Title = titles.title(names[row.entry.DatasetID], row.data)
+		row.entry.Private, _ = row.data["private"].(bool)
+		page.Entries = append(page.Entries, row.entry)
+	}
+
+	return page, nil
+}
+
+func timelineDatasets(ids []string) ([]Dataset, error) {
+	if len(ids) == 0 {
+		return ListDatasets()
+	}
+
+	var datasets []Dataset
+	for _, id := range ids {
+		dataset, err := GetDataset(id)
+		if err != nil {
+			return nil, fmt.Errorf("unknown dataset '%s'", id)
+		}
+		datasets = append(datasets, dataset)
+	}
+	return datasets, nil
+}
+
+// timelineRange turns the bounds into values comparable with FieldExpression
+// dates. The upper bound is exclusive.
+func timelineRange(from string, to string) (string, string, error) {
+	var start, until string
+	if strings.TrimSpace(from) != "" {
+		parsed, ok := ParseDateValue(from)
+		if !ok {
+			return "", "", fmt.Errorf("invalid from date %q", from)
+		}
+		start = parsed.UTC().Format(filterDateLayout)
+	}
+
+	if strings.TrimSpace(to) != "" {
+		parsed, ok := ParseDateValue(to)
+		if !ok {
+			return "", "", fmt.Errorf("invalid to date %q", to)
+		}
+		if strings.ContainsAny(strings.TrimSpace(to), "T: ") {
+			parsed = parsed.Add(time.Millisecond)
+		} else {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		until = parsed.UTC().Format(filterDateLayout)
+	}
+
+	return start, until, nil
+}
+
+type timelineTitler struct {
+	related map[string]string
+}
+
+func (t *timelineTitler) title(dataset Dataset, data map[string]interface{}) string {
+	if template, ok := timelineTitles[dataset.ID]; ok {
+		relations := make(map[string]bool)
+		for _, field := range dataset.Fields {
+			relations[field.Key] = field.IsRelation
+		}
+
+		complete := true
+		title := titlePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+			key := placeholder[1 : len(placeholder)-1]
+			value := titleValue(data[key])
+			if relations[key] && value != "" {
+				value = t.relatedTitle(value)
+			}
+			if value == "" {
+				complete = false
+			}
+			return value
+		})
+		if complete {
+			return shortenTitle(title)
+		}
+	}
+
+	if title := genericTitle(data); title != "" {
+		return title
+	}
+	return dataset.Name
+}
+
+func (t *timelineTitler) relatedTitle(id string) string {
+	if title, ok := t.related[id]; ok {
+		return title
+	}
+
+	title := ""
+	record, err := getDataRecord(DB, id)
+	if err == nil {
+		var data map[string]interface{}
+		if json.Unmarshal(record.Data, &data) == nil {
+			title = genericTitle(data)
+		}
+	}
+	t.related[id] = title
+	return title
+}
+
+func genericTitle(data map[string]interface{}) string {
+	for _, key := range timelineTitleKeys {
+		if value := titleValue(data[key]); value != "" {
+			return shortenTitle(value)
+		}
+	}
+	return ""
+}
+
+func titleValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// shortenTitle keeps the first line of a title, without markdown markers,
+// and cuts it to timelineTitleLength characters.
+func shortenTitle(title string) string {
+	for _, line := range strings.Split(title, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#*-> "))
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > timelineTitleLength {
+			return strings.TrimSpace(string(runes[:timelineTitleLength-1])) + "…"
+		}
+		return line
+	}
+	return ""
+}
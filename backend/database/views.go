@@ -0,0 +1,232 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedView is a named table setup for one dataset: which records, in what
+// order, which columns and how they are grouped.
+type SavedView struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description,omitempty"`
+	DatasetID    string    `json:"datasetId"`
+	Filter       string    `json:"filter,omitempty"`
+	Sort         string    `json:"sort,omitempty"`
+	Descending   bool      `json:"descending,omitempty"`
+	Columns      []string  `json:"columns"`
+	GroupBy      string    `json:"groupBy,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// Query returns the record query the view runs.
+func (v SavedView) Query() RecordQuery {
+	return RecordQuery{Filter: v.Filter, Sort: v.Sort, Descending: v.Descending}
+}
+
+func InitializeViews(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS saved_views (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			description TEXT,
+			dataset_id TEXT NOT NULL,
+			filter TEXT NOT NULL DEFAULT '',
+			sort TEXT NOT NULL DEFAULT '',
+			descending INTEGER NOT NULL DEFAULT 0,
+			columns TEXT NOT NULL,
+			group_by TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			last_modified TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_saved_views_dataset ON saved_views(dataset_id)
+	`)
+	return err
+}
+
+func CreateView(view SavedView) (SavedView, error) {
+	if view.ID == "" {
+		view.ID = uuid.New().String()
+	}
+
+	err := validateView(view)
+	if err != nil {
+		return SavedView{}, err
+	}
+
+	now := time.Now()
+	view.CreatedAt = now
+	view.LastModified = now
+
+	columns, err := json.Marshal(viewColumns(view))
+	if err != nil {
+		return SavedView{}, err
+	}
+
+	_, err = DB.Exec(
+		`INSERT INTO saved_views (id, name, description, dataset_id, filter, sort, descending, columns, group_by, created_at, last_modified)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		view.ID, view.Name, view.Description, view.DatasetID, view.Filter, view.Sort, view.Descending, string(columns), view.GroupBy, view.CreatedAt, view.LastModified,
+	)
+	if err != nil {
+		return SavedView{}, err
+	}
+
+	return view, nil
+}
+
+func UpdateView(view SavedView) (SavedView, error) {
+	err := validateView(view)
+	if err != nil {
+		return SavedView{}, err
+	}
+
+	view.LastModified = time.Now()
+
+	columns, err := json.Marshal(viewColumns(view))
+	if err != nil {
+		return SavedView{}, err
+	}
+
+	result, err := DB.Exec(
+		`UPDATE saved_views SET name = ?, description = ?, dataset_id = ?, filter = ?, sort = ?, descending = ?, columns = ?, group_by = ?, last_modified = ?
+         WHERE id = ?`,
+		view.Name, view.Description, view.DatasetID, view.Filter, view.Sort, view.Descending, string(columns), view.GroupBy, view.LastModified, view.ID,
+	)
+	if err != nil {
+		return SavedView{}, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return SavedView{}, err
+	}
+	if rows == 0 {
+		return SavedView{}, &NotFoundError{Resource: "view", ID: view.ID}
+	}
+
+	return GetView(view.ID)
+}
+
+func DeleteView(id string) error {
+	result, err := DB.Exec("DELETE FROM saved_views WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &NotFoundError{Resource: "view", ID: id}
+	}
+
+	return nil
+}
+
+func GetView(id string) (SavedView, error) {
+	views, err := queryViews("WHERE id = ?", id)
+	if err != nil {
+		return SavedView{}, err
+	}
+	if len(views) == 0 {
+		return SavedView{}, &NotFoundError{Resource: "view", ID: id}
+	}
+
+	return views[0], nil
+}
+
+// ListViews lists all views, or only those of one dataset.
+func ListViews(datasetID string) ([]SavedView, error) {
+	if datasetID != "" {
+		return queryViews("WHERE dataset_id = ? ORDER BY name", datasetID)
+	}
+	return queryViews("ORDER BY name")
+}
+
+func validateView(view SavedView) error {
+	if strings.TrimSpace(view.Name) == "" {
+		return invalid("name", "view name is required")
+	}
+
+	dataset, err := GetDataset(view.DatasetID)
+	if err != nil {
+		return invalid("datasetId", "unknown dataset '%s'", view.DatasetID)
+	}
+
+	if _, err := CompileFilter(dataset, view.Filter); err != nil {
+		return invalid("filter", "%v", err)
+	}
+
+	fields := make(map[string]bool)
+	for _, field := range dataset.Fields {
+		fields[field.Key] = true
+	}
+	checks := []struct {
+		name string
+		keys []string
+	}{{"sort", []string{view.Sort}}, {"groupBy", []string{view.GroupBy}}, {"columns", view.Columns}}
+	for _, check := range checks {
+		for _, key := range check.keys {
+			if key != "" && !fields[key] {
+				return invalid(check.name, "dataset %s has no field %q", view.DatasetID, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func viewColumns(view SavedView) []string {
+	if view.Columns == nil {
+		return []string{}
+	}
+	return view.Columns
+}
+
+func queryViews(where string, args ...interface{}) ([]SavedView, error) {
+	rows, err := DB.Query(
+		`SELECT id, name, description, dataset_id, filter, sort, descending, columns, group_by, created_at, last_modified
+         FROM saved_views `+where,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []SavedView{}
+	for rows.Next() {
+		var view SavedView
+		var description sql.NullString
+		var columns string
+
+		err := rows.Scan(&view.ID, &view.Name, &description, &view.DatasetID, &view.Filter, &view.Sort, &view.Descending, &columns, &view.GroupBy, &view.CreatedAt, &view.LastModified)
+		if err != nil {
+			return nil, err
+		}
+		view.Description = description.String
+
+		if err := json.Unmarshal([]byte(columns), &view.Columns); err != nil {
+			return nil, fmt.Errorf("invalid columns for view %s: %w", view.ID, err)
+		}
+
+		views = append(views, view)
+	}
+
+	return views, rows.Err()
+}
@@ -0,0 +1,24 @@
+package backend
+
+import (
+	"myproject/backend/digest"
+	"time"
+)
+
+// GetDailyDigest collects what was recorded on a date (YYYY-MM-DD, today when
+// empty) across all datasets.
+func (a *App) GetDailyDigest(date string) (digest.Digest, error) {
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	return digest.Build(date)
+}
+
+// GetDailyDigestMarkdown returns the same digest rendered as markdown.
+func (a *App) GetDailyDigestMarkdown(date string) (string, error) {
+	d, err := a.GetDailyDigest(date)
+	if err != nil {
+		return "", err
+	}
+	return d.Markdown(), nil
+}
@@ -0,0 +1,510 @@
+package digest
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dayLayout = "2006-01-02"
+
+// priorYearEntries caps the records listed for each earlier year.
+const priorYearEntries = 20
+
+type Digest struct {
+	Date         string        `json:"date"`
+	Weekday      string        `json:"weekday"`
+	Metrics      Metrics       `json:"metrics"`
+	Time         TimeTracked   `json:"time"`
+	Journal      []JournalNote `json:"journal"`
+	Meetings     []Meeting     `json:"meetings"`
+	Todos        Todos         `json:"todos"`
+	Transactions Transactions  `json:"transactions"`
+	Measurements []Measurement `json:"measurements"`
+	PriorYears   []PriorYear   `json:"priorYears"`
+}
+
+// Metrics lists the logged values of the day and the metrics that were
+// scheduled for it but not logged.
+type Metrics struct {
+	Logged []MetricLog `json:"logged"`
+	Missed []MetricRef `json:"missed"`
+}
+
+type MetricLog struct {
+	RecordID string `json:"recordId"`
+	MetricID string `json:"metricId,omitempty"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Unit     string `json:"unit,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+type MetricRef struct {
+	MetricID string `json:"metricId"`
+	Name     string `json:"name"`
+}
+
+type TimeTracked struct {
+	TotalMinutes float64        `json:"totalMinutes"`
+	Categories   []TimeCategory `json:"categories"`
+}
+
+type TimeCategory struct {
+	Name    string  `json:"name"`
+	Minutes float64 `json:"minutes"`
+	Entries int     `json:"entries"`
+}
+
+type JournalNote struct {
+	RecordID  string `json:"recordId"`
+	DatasetID string `json:"datasetId"`
+	Journal   string `json:"journal"`
+	Text      string `json:"text"`
+}
+
+type Meeting struct {
+	RecordID        string  `json:"recordId"`
+	Person          string  `json:"person,omitempty"`
+	Location        string  `json:"location,omitempty"`
+	DurationMinutes float64 `json:"durationMinutes,omitempty"`
+	Description     string  `json:"description,omitempty"`
+}
+
+type Todos struct {
+	Completed []Todo `json:"completed"`
+	Overdue   []Todo `json:"overdue"`
+}
+
+type Todo struct {
+	RecordID string `json:"recordId"`
+	Title    string `json:"title"`
+	Deadline string `json:"deadline,omitempty"`
+	Priority string `json:"priority,omitempty"`
+}
+
+type Transactions struct {
+	Entries  []Transaction `json:"entries"`
+	Income   float64       `json:"income"`
+	Expenses float64       `json:"expenses"`
+}
+
+type Transaction struct {
+	RecordID    string  `json:"recordId"`
+	Description string  `json:"description"`
+	Category    string  `json:"category,omitempty"`
+	Amount      float64 `json:"amount"`
+}
+
+type Measurement struct {
+	RecordID    string  `json:"recordId"`
+	Measurement string  `json:"measurement"`
+	Value       float64 `json:"value"`
+	Unit        string  `json:"unit,omitempty"`
+	Time        string  `json:"time,omitempty"`
+}
+
+// PriorYear is what was recorded on the same calendar date in an earlier
+// year. Entries holds at most priorYearEntries of Total records.
+type PriorYear struct {
+	Year     int          `json:"year"`
+	YearsAgo int          `json:"yearsAgo"`
+	Total    int          `json:"total"`
+	Entries  []PriorEntry `json:"entries"`
+}
+
+type PriorEntry struct {
+	RecordID  string `json:"recordId"`
+	DatasetID string `json:"datasetId"`
+	Dataset   string `json:"dataset"`
+	Title     string `json:"title"`
+}
+
+var journals = []string{
+	database.DatasetIDGratitudeJournal,
+	database.DatasetIDAffirmation,
+	database.DatasetIDCreativityJournal,
+	database.DatasetIDQuestionJournal,
+}
+
+type record struct {
+	id        string
+	createdAt time.Time
+	data      map[string]interface{}
+}
+
+type builder struct {
+	day     time.Time
+	date    string
+	digest  Digest
+	related map[string]map[string]interface{}
+}
+
+// Build collects everything recorded for one calendar date, given as
+// YYYY-MM-DD.
+func Build(date string) (Digest, error) {
+	day, err := time.Parse(dayLayout, strings.TrimSpace(date))
+	if err != nil {
+		return Digest{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+
+	b := &builder{
+		day:     day,
+		date:    day.Format(dayLayout),
+		related: make(map[string]map[string]interface{}),
+		digest: Digest{
+			Date:         day.Format(dayLayout),
+			Weekday:      day.Weekday().String(),
+			Metrics:      Metrics{Logged: []MetricLog{}, Missed: []MetricRef{}},
+			Time:         TimeTracked{Categories: []TimeCategory{}},
+			Journal:      []JournalNote{},
+			Meetings:     []Meeting{},
+			Todos:        Todos{Completed: []Todo{}, Overdue: []Todo{}},
+			Transactions: Transactions{Entries: []Transaction{}},
+			Measurements: []Measurement{},
+			PriorYears:   []PriorYear{},
+		},
+	}
+
+	for _, step := range []func() error{
+		b.metrics,
+		b.timeTracked,
+		b.journal,
+		b.meetings,
+		b.todos,
+		b.transactions,
+		b.measurements,
+		b.priorYears,
+	} {
+		if err := step(); err != nil {
+			return Digest{}, err
+		}
+	}
+
+	return b.digest, nil
+}
+
+func (b *builder) records(datasetID string, filter string) ([]record, error) {
+	records, err := database.QueryDataRecords(datasetID, database.RecordQuery{Filter: filter})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", datasetID, err)
+	}
+
+	result := make([]record, 0, len(records))
+	for _, r := range records {
+		var data map[string]interface{}
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			continue
+		}
+		result = append(result, record{id: r.ID, createdAt: r.CreatedAt, data: data})
+	}
+
+	// Oldest first reads naturally within a day.
+	sort.SliceStable(result, func(i, j int) bool { return result[i].createdAt.Before(result[j].createdAt) })
+	return result, nil
+}
+
+// onDay is a filter matching the records whose field falls on the digest's
+// date.
+func (b *builder) onDay(field string) string {
+	return fmt.Sprintf(`%s = "%s"`, field, b.date)
+}
+
+// relatedText reads a field of the record a relation points to.
+func (b *builder) relatedText(id string, key string) string {
+	if id == "" {
+		return ""
+	}
+
+	data, ok := b.related[id]
+	if !ok {
+		record, err := database.GetDataRecord(id, false)
+		if err == nil {
+			_ = json.Unmarshal(record.Data, &data)
+		}
+		b.related[id] = data
+	}
+	return text(data[key])
+}
+
+func (b *builder) metrics() error {
+	metrics, err := b.records(database.DatasetIDMetric, "")
+	if err != nil {
+		return err
+	}
+	logs, err := b.records(database.DatasetIDDailyLog, b.onDay("date"))
+	if err != nil {
+		return err
+	}
+
+	logged := make(map[string]bool)
+	for _, log := range logs {
+		metricID := text(log.data["metric_id"])
+		logged[metricID] = true
+		b.digest.Metrics.Logged = append(b.digest.Metrics.Logged, MetricLog{
+			RecordID: log.id,
+			MetricID: metricID,
+			Name:     firstText(b.relatedText(metricID, "name"), "Metric"),
+			Value:    text(log.data["value"]),
+			Unit:     b.relatedText(metricID, "unit"),
+			Notes:    text(log.data["notes"]),
+		})
+	}
+
+	endOfDay := b.day.AddDate(0, 0, 1)
+	for _, metric := range metrics {
+		if logged[metric.id] || !metric.createdAt.Before(endOfDay) || !scheduledOn(metric.data, b.day) {
+			continue
+		}
+		b.digest.Metrics.Missed = append(b.digest.Metrics.Missed, MetricRef{MetricID: metric.id, Name: text(metric.data["name"])})
+	}
+
+	return nil
+}
+
+func (b *builder) timeTracked() error {
+	entries, err := b.records(database.DatasetIDTimeEntries, b.onDay("start_time"))
+	if err != nil {
+		return err
+	}
+
+	categories := make(map[string]*TimeCategory)
+	var order []string
+	for _, entry := range entries {
+		minutes, ok := number(entry.data["duration_minutes"])
+		if !ok {
+			start, startOK := database.ParseDateValue(entry.data["start_time"])
+			end, endOK := database.ParseDateValue(entry.data["end_time"])
+			if !startOK || !endOK || end.Before(start) {
+				continue
+			}
+			minutes = end.Sub(start).Minutes()
+		}
+
+		name := firstText(b.relatedText(text(entry.data["category_id"]), "name"), "Uncategorized")
+		category, ok := categories[name]
+		if !ok {
+			category = &TimeCategory{Name: name}
+			categories[name] = category
+			order = append(order, name)
+		}
+		category.Minutes += minutes
+		category.Entries++
+		b.digest.Time.TotalMinutes += minutes
+	}
+
+	for _, name := range order {
+		b.digest.Time.Categories = append(b.digest.Time.Categories, *categories[name])
+	}
+	sort.SliceStable(b.digest.Time.Categories, func(i, j int) bool {
+		return b.digest.Time.Categories[i].Minutes > b.digest.Time.Categories[j].Minutes
+	})
+
+	return nil
+}
+
+func (b *builder) journal() error {
+	for _, datasetID := range journals {
+		dataset, err := database.GetDataset(datasetID)
+		if err != nil {
+			continue
+		}
+
+		entries, err := b.records(datasetID, b.onDay("date"))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			b.digest.Journal = append(b.digest.Journal, JournalNote{
+				RecordID:  entry.id,
+				DatasetID: datasetID,
+				Journal:   dataset.Name,
+				Text:      firstText(text(entry.data["entry"]), text(entry.data["affirmation"])),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (b *builder) meetings() error {
+	meetings, err := b.records(database.DatasetIDMeetings, b.onDay("meeting_date"))
+	if err != nil {
+		return err
+	}
+
+	for _, meeting := range meetings {
+		duration, _ := number(meeting.data["duration_minutes"])
+		b.digest.Meetings = append(b.digest.Meetings, Meeting{
+			RecordID:        meeting.id,
+			Person:          b.relatedText(text(meeting.data["person_id"]), "name"),
+			Location:        text(meeting.data["location"]),
+			DurationMinutes: duration,
+			Description:     text(meeting.data["description"]),
+		})
+	}
+
+	return nil
+}
+
+// todos lists what was completed on the day and what was overdue then: past
+// its deadline and not yet done by the end of the day.
+func (b *builder) todos() error {
+	completed, err := b.records(database.DatasetIDTodos, b.onDay("completed_at"))
+	if err != nil {
+		return err
+	}
+	for _, todo := range completed {
+		b.digest.Todos.Completed = append(b.digest.Todos.Completed, newTodo(todo))
+	}
+
+	overdue, err := b.records(database.DatasetIDTodos, fmt.Sprintf(
+		`deadline < "%s" and (is_complete is empty or is_complete = false or completed_at > "%s")`,
+		b.date, b.date,
+	))
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(overdue, func(i, j int) bool {
+		return text(overdue[i].data["deadline"]) < text(overdue[j].data["deadline"])
+	})
+	for _, todo := range overdue {
+		b.digest.Todos.Overdue = append(b.digest.Todos.Overdue, newTodo(todo))
+	}
+
+	return nil
+}
+
+func newTodo(todo record) Todo {
+	return Todo{
+		RecordID: todo.id,
+		Title:    text(todo.data["title"]),
+		Deadline: text(todo.data["deadline"]),
+		Priority: text(todo.data["priority"]),
+	}
+}
+
+func (b *builder) transactions() error {
+	transactions, err := b.records(database.DatasetIDFinancialLogs, b.onDay("date"))
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range transactions {
+		amount, _ := number(transaction.data["amount"])
+		if amount >= 0 {
+			b.digest.Transactions.Income += amount
+		} else {
+			b.digest.Transactions.Expenses -= amount
+		}
+		b.digest.Transactions.Entries = append(b.digest.Transactions.Entries, Transaction{
+			RecordID:    transaction.id,
+			Description: text(transaction.data["description"]),
+			Category:    text(transaction.data["category"]),
+			Amount:      amount,
+		})
+	}
+
+	return nil
+}
+
+func (b *builder) measurements() error {
+	measurements, err := b.records(database.DatasetIDBodyMeasurements, b.onDay("date"))
+	if err != nil {
+		return err
+	}
+
+	for _, measurement := range measurements {
+		value, _ := number(measurement.data["value"])
+		b.digest.Measurements = append(b.digest.Measurements, Measurement{
+			RecordID:    measurement.id,
+			Measurement: text(measurement.data["measurement"]),
+			Value:       value,
+			Unit:        text(measurement.data["unit"]),
+			Time:        text(measurement.data["time"]),
+		})
+	}
+
+	return nil
+}
+
+// priorYears goes back one year at a time until the year of the oldest
+// record. February 29 only recurs in leap years.
+func (b *builder) priorYears() error {
+	oldest, err := database.QueryTimeline(database.TimelineQuery{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(oldest.Entries) == 0 {
+		return nil
+	}
+
+	for year := b.day.Year() - 1; year >= oldest.Entries[0].Date.Year(); year-- {
+		day := time.Date(year, b.day.Month(), b.day.Day(), 0, 0, 0, 0, time.UTC)
+		if day.Month() != b.day.Month() {
+			continue
+		}
+
+		date := day.Format(dayLayout)
+		page, err := database.QueryTimeline(database.TimelineQuery{From: date, To: date, Limit: priorYearEntries})
+		if err != nil {
+			return err
+		}
+		if page.Total == 0 {
+			continue
+		}
+
+		names := make(map[string]string)
+		for _, dataset := range page.Datasets {
+			names[dataset.ID] = dataset.Name
+		}
+		prior := PriorYear{Year: year, YearsAgo: b.day.Year() - year, Total: page.Total, Entries: []PriorEntry{}}
+		for _, entry := range page.Entries {
+			prior.Entries = append(prior.Entries, PriorEntry{
+				RecordID:  entry.RecordID,
+				DatasetID: entry.DatasetID,
+				Dataset:   names[entry.DatasetID],
+				Title:     entry.Title,
+			})
+		}
+		b.digest.PriorYears = append(b.digest.PriorYears, prior)
+	}
+
+	return nil
+}
+
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+func firstText(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+package digest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Markdown renders the digest for reading or pasting into notes. Sections
+// without anything in them are left out.
+func (d Digest) Markdown() string {
+	var out strings.Builder
+
+	title := d.Date
+	if day, err := time.Parse(dayLayout, d.Date); err == nil {
+		title = day.Format("Monday, January 2, 2006")
+	}
+	fmt.Fprintf(&out, "# %s\n", title)
+
+	empty := true
+	section := func(heading string) {
+		empty = false
+		fmt.Fprintf(&out, "\n## %s\n\n", heading)
+	}
+
+	if len(d.Metrics.Logged) > 0 || len(d.Metrics.Missed) > 0 {
+		section("Metrics")
+		for _, log := range d.Metrics.Logged {
+			line := fmt.Sprintf("- %s: %s", log.Name, joinNonEmpty(" ", log.Value, log.Unit))
+			if log.Notes != "" {
+				line += " — " + firstLine(log.Notes)
+			}
+			out.WriteString(line + "\n")
+		}
+		if len(d.Metrics.Missed) > 0 {
+			names := make([]string, len(d.Metrics.Missed))
+			for i, metric := range d.Metrics.Missed {
+				names[i] = metric.Name
+			}
+			fmt.Fprintf(&out, "- Not logged: %s\n", strings.Join(names, ", "))
+		}
+	}
+
+	if len(d.Time.Categories) > 0 {
+		section(fmt.Sprintf("Time tracked (%s)", duration(d.Time.TotalMinutes)))
+		for _, category := range d.Time.Categories {
+			fmt.Fprintf(&out, "- %s: %s (%s)\n", category.Name, duration(category.Minutes), plural(category.Entries, "entry", "entries"))
+		}
+	}
+
+	if len(d.Journal) > 0 {
+		section("Journal")
+		for i, note := range d.Journal {
+			if i > 0 {
+				out.WriteString("\n")
+			}
+			fmt.Fprintf(&out, "**%s**\n\n", note.Journal)
+			for _, line := range strings.Split(note.Text, "\n") {
+				out.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+		}
+	}
+
+	if len(d.Meetings) > 0 {
+		section("Meetings")
+		for _, meeting := range d.Meetings {
+			line := "- " + firstText(meeting.Person, "Meeting")
+			if meeting.Location != "" {
+				line += " at " + meeting.Location
+			}
+			if meeting.DurationMinutes > 0 {
+				line += fmt.Sprintf(" (%s)", duration(meeting.DurationMinutes))
+			}
+			if meeting.Description != "" {
+				line += ": " + firstLine(meeting.Description)
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+
+	if len(d.Todos.Completed) > 0 || len(d.Todos.Overdue) > 0 {
+		section("Todos")
+		for _, todo := range d.Todos.Completed {
+			fmt.Fprintf(&out, "- [x] %s\n", todo.Title)
+		}
+		for _, todo := range d.Todos.Overdue {
+			fmt.Fprintf(&out, "- [ ] %s (overdue since %s)\n", todo.Title, todo.Deadline)
+		}
+	}
+
+	if len(d.Transactions.Entries) > 0 {
+		section(fmt.Sprintf("Transactions (in %s, out %s)", amount(d.Transactions.Income), amount(d.Transactions.Expenses)))
+		for _, transaction := range d.Transactions.Entries {
+			line := fmt.Sprintf("- %s: %s", firstText(transaction.Description, "Transaction"), amount(transaction.Amount))
+			if transaction.Category != "" {
+				line += fmt.Sprintf(" (%s)", transaction.Category)
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+
+	if len(d.Measurements) > 0 {
+		section("Body measurements")
+		for _, measurement := range d.Measurements {
+			line := fmt.Sprintf("- %s: %s", measurement.Measurement, joinNonEmpty(" ", strconv.FormatFloat(measurement.Value, 'f', -1, 64), measurement.Unit))
+			if measurement.Time != "" {
+				line += fmt.Sprintf(" at %s", measurement.Time)
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+
+	if empty {
+		out.WriteString("\nNothing was recorded on this day.\n")
+	}
+
+	if len(d.PriorYears) > 0 {
+		out.WriteString("\n## On this day\n")
+		for _, year := range d.PriorYears {
+			fmt.Fprintf(&out, "\n### %d (%s ago)\n\n", year.Year, plural(year.YearsAgo, "year", "years"))
+			for _, entry := range year.Entries {
+				fmt.Fprintf(&out, "- %s (%s)\n", entry.Title, entry.Dataset)
+			}
+			if more := year.Total - len(year.Entries); more > 0 {
+				fmt.Fprintf(&out, "- and %d more\n", more)
+			}
+		}
+	}
+
+	return out.String()
+}
+
+func duration(minutes float64) string {
+	total := int(minutes + 0.5)
+	if total < 60 {
+		return fmt.Sprintf("%dm", total)
+	}
+	if total%60 == 0 {
+		return fmt.Sprintf("%dh", total/60)
+	}
+	return fmt.Sprintf("%dh %dm", total/60, total%60)
+}
+
+func amount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func plural(n int, one string, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+func firstLine(value string) string {
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func joinNonEmpty(separator string, values ...string) string {
+	var parts []string
+	for _, value := range values {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, separator)
+}
@@ -0,0 +1,104 @@
+package digest
+
+import (
+	"encoding/json"
+	"myproject/backend/database"
+	"time"
+)
+
+// scheduledOn tells whether a metric was due on a day. It follows
+// isMetricScheduledForDate in the daily tracker. Inactive metrics only count
+// up to their end date.
+func scheduledOn(metric map[string]interface{}, day time.Time) bool {
+	start, hasStart := scheduleDate(metric["schedule_start_date"])
+	end, hasEnd := scheduleDate(metric["schedule_end_date"])
+	if active, ok := metric["active"].(bool); ok && !active && !hasEnd {
+		return false
+	}
+
+	frequency := text(metric["schedule_frequency"])
+	days := scheduleDays(metric["schedule_days"])
+	if frequency == "" && !hasStart && !hasEnd && len(days) == 0 {
+		return true
+	}
+
+	if hasStart && day.Before(start) {
+		return false
+	}
+	if hasEnd && day.After(end) {
+		return false
+	}
+
+	onWeekday := func(otherwise bool) bool {
+		if len(days) == 0 {
+			return otherwise
+		}
+		return days[int(day.Weekday())]
+	}
+
+	switch frequency {
+	case "daily":
+		return true
+	case "weekly":
+		return onWeekday(true)
+	case "custom":
+		return onWeekday(false)
+	case "interval":
+		interval, _ := number(metric["schedule_interval_value"])
+		if !hasStart || interval < 1 {
+			return false
+		}
+		return onInterval(start, day, int(interval), text(metric["schedule_interval_unit"]))
+	}
+	return onWeekday(true)
+}
+
+func onInterval(start time.Time, day time.Time, interval int, unit string) bool {
+	elapsed := int(day.Sub(start).Hours() / 24)
+	switch unit {
+	case "", "days":
+		return elapsed%interval == 0
+	case "weeks":
+		return elapsed%7 == 0 && (elapsed/7)%interval == 0
+	case "months":
+		months := (day.Year()-start.Year())*12 + int(day.Month()-start.Month())
+		if months%interval != 0 {
+			return false
+		}
+		// Like date-fns addMonths, the 31st falls back to a shorter month's
+		// last day.
+		target := time.Date(start.Year(), start.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+		last := target.AddDate(0, 1, -1).Day()
+		return day.Day() == min(start.Day(), last)
+	}
+	return false
+}
+
+func scheduleDate(value interface{}) (time.Time, bool) {
+	parsed, ok := database.ParseDateValue(value)
+	if !ok {
+		return time.Time{}, false
+	}
+	year, month, day := parsed.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), true
+}
+
+// scheduleDays reads the weekdays a metric is shown on, 0 being Sunday. They
+// are stored as a list, or as the JSON text of one.
+func scheduleDays(value interface{}) map[int]bool {
+	if s, ok := value.(string); ok && s != "" {
+		var parsed []interface{}
+		if json.Unmarshal([]byte(s), &parsed) == nil {
+			value = parsed
+		}
+	}
+
+	days := make(map[int]bool)
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		if day, ok := number(item); ok && day >= 0 && day <= 6 {
+			days[int(day)] = true
+		}
+	}
+	return days
+}
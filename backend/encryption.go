@@ -0,0 +1,224 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"myproject/backend/file"
+	"myproject/backend/settings"
+	"time"
+)
+
+type EncryptionJob struct {
+	Running    bool                 `json:"running"`
+	Encrypting bool                 `json:"encrypting"`
+	Progress   file.ConvertProgress `json:"progress"`
+	Error      string               `json:"error,omitempty"`
+	StartedAt  *time.Time           `json:"startedAt,omitempty"`
+	FinishedAt *time.Time           `json:"finishedAt,omitempty"`
+}
+
+type EncryptionStatus struct {
+	Enabled    bool          `json:"enabled"`
+	Configured bool          `json:"configured"`
+	Unlocked   bool          `json:"unlocked"`
+	Job        EncryptionJob `json:"job"`
+}
+
+func (a *App) GetEncryptionStatus() (EncryptionStatus, error) {
+	encryption := a.currentSettings().Encryption
+
+	a.encryptionMu.Lock()
+	defer a.encryptionMu.Unlock()
+
+	return EncryptionStatus{
+		Enabled:    encryption.Enabled,
+		Configured: encryption.Salt != "",
+		Unlocked:   file.VaultUnlocked(),
+		Job:        a.encryptionJob,
+	}, nil
+}
+
+// EnableEncryption turns on encryption for new files and starts encrypting
+// the existing ones in the background.
+func (a *App) EnableEncryption(passphrase string) (EncryptionStatus, error) {
+	var key []byte
+	_, err := a.updateSettings(func(updated *settings.Settings) error {
+		if updated.Encryption.Enabled {
+			return errors.New("encryption is already enabled")
+		}
+
+		// A vault left over from an unfinished disable keeps its key, because
+		// some files may still be encrypted with it.
+		var err error
+		if updated.Encryption.Salt != "" {
+			key, err = vaultKey(updated.Encryption, passphrase)
+		} else {
+			updated.Encryption.Salt, err = file.NewVaultSalt()
+			if err == nil {
+				key, updated.Encryption.KeyCheck, err = file.DeriveVaultKey(passphrase, updated.Encryption.Salt)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		if a.conversionRunning() {
+			return errors.New("files are still being converted")
+		}
+
+		updated.Encryption.Enabled = true
+		return nil
+	})
+	if err != nil {
+		return EncryptionStatus{}, err
+	}
+
+	file.UnlockVault(key)
+	file.SetEncryptNewFiles(true)
+	a.startConversion(true)
+
+	return a.GetEncryptionStatus()
+}
+
+// DisableEncryption stores new files in plain form again and decrypts the
+// existing ones in the background. The vault key is forgotten once every
+// file has been decrypted.
+func (a *App) DisableEncryption(passphrase string) (EncryptionStatus, error) {
+	var key []byte
+	_, err := a.updateSettings(func(updated *settings.Settings) error {
+		if !updated.Encryption.Enabled {
+			return errors.New("encryption is not enabled")
+		}
+
+		var err error
+		key, err = vaultKey(updated.Encryption, passphrase)
+		if err != nil {
+			return err
+		}
+
+		if a.conversionRunning() {
+			return errors.New("files are still being converted")
+		}
+
+		updated.Encryption.Enabled = false
+		return nil
+	})
+	if err != nil {
+		return EncryptionStatus{}, err
+	}
+
+	file.UnlockVault(key)
+	file.SetEncryptNewFiles(false)
+	a.startConversion(false)
+
+	return a.GetEncryptionStatus()
+}
+
+func (a *App) UnlockVault(passphrase string) (EncryptionStatus, error) {
+	encryption := a.currentSettings().Encryption
+	key, err := vaultKey(encryption, passphrase)
+	if err != nil {
+		return EncryptionStatus{}, err
+	}
+
+	file.UnlockVault(key)
+
+	// Finish a conversion that was interrupted by a restart; files that are
+	// already in the right format are skipped quickly.
+	if !a.conversionRunning() {
+		a.startConversion(encryption.Enabled)
+	}
+
+	return a.GetEncryptionStatus()
+}
+
+func (a *App) LockVault() (EncryptionStatus, error) {
+	if a.conversionRunning() {
+		return EncryptionStatus{}, errors.New("files are still being converted")
+	}
+
+	file.LockVault()
+	return a.GetEncryptionStatus()
+}
+
+func vaultKey(encryption settings.EncryptionSettings, passphrase string) ([]byte, error) {
+	if encryption.Salt == "" {
+		return nil, errors.New("no vault has been set up")
+	}
+
+	key, check, err := file.DeriveVaultKey(passphrase, encryption.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if check != encryption.KeyCheck {
+		return nil, file.ErrWrongPassphrase
+	}
+
+	return key, nil
+}
+
+func (a *App) conversionRunning() bool {
+	a.encryptionMu.Lock()
+	defer a.encryptionMu.Unlock()
+	return a.encryptionJob.Running
+}
+
+func (a *App) startConversion(encrypt bool) {
+	now := time.Now()
+
+	a.encryptionMu.Lock()
+	a.encryptionJob = EncryptionJob{
+		Running:    true,
+		Encrypting: encrypt,
+		Progress:   file.ConvertProgress{Failed: []string{}},
+		StartedAt:  &now,
+	}
+	a.encryptionMu.Unlock()
+
+	a.restoreMu.RLock()
+	go func() {
+		defer a.restoreMu.RUnlock()
+
+		result, err := file.ConvertFiles(a.filesRoot, encrypt, func(progress file.ConvertProgress) {
+			a.encryptionMu.Lock()
+			a.encryptionJob.Progress = progress
+			a.encryptionMu.Unlock()
+		})
+
+		failed := err != nil || len(result.Failed) > 0
+		if failed {
+			log.Printf("Error converting files: %v (%d failed)", err, len(result.Failed))
+		} else if result.Converted > 0 {
+			log.Printf("Converted %d file(s)", result.Converted)
+		}
+
+		// The vault is cleared while the job still runs, so encryption cannot
+		// be enabled again in between and have its new salt cleared.
+		if !failed && !encrypt {
+			_, clearErr := a.updateSettings(func(updated *settings.Settings) error {
+				updated.Encryption.Salt = ""
+				updated.Encryption.KeyCheck = ""
+				return nil
+			})
+			if clearErr != nil {
+				log.Println("Error clearing vault settings:", clearErr.Error())
+			} else {
+				file.LockVault()
+			}
+		}
+
+		finished := time.Now()
+
+		a.encryptionMu.Lock()
+		a.encryptionJob.Running = false
+		a.encryptionJob.Progress = result
+		a.encryptionJob.FinishedAt = &finished
+		if err != nil {
+			a.encryptionJob.Error = err.Error()
+		} else if len(result.Failed) > 0 {
+			a.encryptionJob.Error = fmt.Sprintf("%d file(s) could not be converted", len(result.Failed))
+		}
+		a.encryptionMu.Unlock()
+	}()
+}
@@ -0,0 +1,10 @@
+package backend
+
+import "myproject/backend/database"
+
+// FormatError is the Wails error formatter. App methods reject with a
+// database.ErrorInfo object, {code, message, details}, instead of a string,
+// so the frontend can tell a missing record from a duplicate value.
+func FormatError(err error) any {
+	return database.DescribeError(err)
+}
@@ -0,0 +1,54 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// NewAssetHandler serves files/... paths for the Wails asset server, which
+// only calls it for requests the embedded frontend assets do not answer.
+// root is read per request because the files location is resolved at startup.
+// A size query parameter serves a cached thumbnail of an image instead.
+func NewAssetHandler(root func() string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		relativePath := path.Clean(strings.TrimPrefix(r.URL.Path, "/"))
+		if !strings.HasPrefix(relativePath, FilesDir+"/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		appDataDir := root()
+		if appDataDir == "" {
+			http.Error(w, "Files are not available yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		relativePath = filepath.FromSlash(relativePath)
+
+		if size := r.URL.Query().Get("size"); size != "" && IsThumbnailable(relativePath) {
+			thumbPath, err := Thumbnail(appDataDir, relativePath, size)
+			if err == nil {
+				relativePath = thumbPath
+			} else if !errors.Is(err, ErrNotAnImage) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+		}
+
+		fullPath, err := ResolvePath(appDataDir, relativePath)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		StreamFile(w, r, fullPath)
+	})
+}
@@ -0,0 +1,506 @@
+package file
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Encrypted files start with a magic string and a random salt, followed by
+// the content in AES-256-GCM sealed segments. Each file gets its own key,
+// derived from the vault key and the salt, so segment nonces can simply
+// count. The final segment is flagged in its nonce, which makes truncation
+// detectable. Plain and encrypted files can sit side by side; readers look at
+// the header to tell them apart.
+
+const (
+	encryptedMagic     = "DDVAULT1"
+	encryptedSaltSize  = 16
+	encryptedHeaderLen = len(encryptedMagic) + encryptedSaltSize
+	segmentSize        = 64 * 1024
+	segmentOverhead    = 16
+)
+
+var (
+	ErrVaultLocked      = errors.New("attachment vault is locked")
+	ErrWrongPassphrase  = errors.New("wrong vault passphrase")
+	ErrDecryptionFailed = errors.New("file could not be decrypted")
+)
+
+var vault struct {
+	sync.RWMutex
+	key        []byte
+	encryptNew bool
+}
+
+func NewVaultSalt() (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(salt), nil
+}
+
+// DeriveVaultKey stretches a passphrase with Argon2id. checkValue is stored
+// in the settings so a wrong passphrase is caught before any file is touched.
+func DeriveVaultKey(passphrase string, salt string) (key []byte, checkValue string, err error) {
+	if passphrase == "" {
+		return nil, "", errors.New("passphrase must not be empty")
+	}
+
+	decodedSalt, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || len(decodedSalt) == 0 {
+		return nil, "", fmt.Errorf("invalid vault salt")
+	}
+
+	key = argon2.IDKey([]byte(passphrase), decodedSalt, 3, 64*1024, 4, 32)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("vault key check"))
+	return key, base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func UnlockVault(key []byte) {
+	vault.Lock()
+	defer vault.Unlock()
+	vault.key = append([]byte(nil), key...)
+}
+
+func LockVault() {
+	vault.Lock()
+	defer vault.Unlock()
+	for i := range vault.key {
+		vault.key[i] = 0
+	}
+	vault.key = nil
+}
+
+func VaultUnlocked() bool {
+	vault.RLock()
+	defer vault.RUnlock()
+	return vault.key != nil
+}
+
+// SetEncryptNewFiles decides whether files written from now on are encrypted.
+func SetEncryptNewFiles(enabled bool) {
+	vault.Lock()
+	defer vault.Unlock()
+	vault.encryptNew = enabled
+}
+
+func vaultKey() ([]byte, error) {
+	vault.RLock()
+	defer vault.RUnlock()
+	if vault.key == nil {
+		return nil, ErrVaultLocked
+	}
+	return vault.key, nil
+}
+
+func encryptNewFiles() bool {
+	vault.RLock()
+	defer vault.RUnlock()
+	return vault.encryptNew
+}
+
+func fileCipher(key []byte, salt []byte) (cipher.AEAD, error) {
+	fileKey := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, salt, []byte("attachment")), fileKey); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func segmentNonce(index int64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], uint64(index))
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	buffer []byte
+	index  int64
+}
+
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	salt := make([]byte, encryptedSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := fileCipher(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(append([]byte(encryptedMagic), salt...)); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{w: w, aead: aead}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	e.buffer = append(e.buffer, p...)
+
+	// Hold back a full segment until more data arrives, because only the
+	// last segment may carry the final flag.
+	for len(e.buffer) > segmentSize {
+		if err := e.seal(e.buffer[:segmentSize], false); err != nil {
+			return 0, err
+		}
+		e.buffer = e.buffer[segmentSize:]
+	}
+
+	return len(p), nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.seal(e.buffer, true)
+}
+
+func (e *encryptWriter) seal(plain []byte, last bool) error {
+	sealed := e.aead.Seal(nil, segmentNonce(e.index, last), plain, nil)
+	e.index++
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+type decryptReader struct {
+	f        *os.File
+	aead     cipher.AEAD
+	size     int64
+	segments int64
+	pos      int64
+	cached   int64
+	plain    []byte
+}
+
+func newDecryptReader(f *os.File, fileSize int64, key []byte) (*decryptReader, error) {
+	header := make([]byte, encryptedHeaderLen)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+	}
+
+	aead, err := fileCipher(key, header[len(encryptedMagic):])
+	if err != nil {
+		return nil, err
+	}
+
+	body := fileSize - int64(encryptedHeaderLen)
+	full := body / (segmentSize + segmentOverhead)
+	remainder := body % (segmentSize + segmentOverhead)
+
+	r := &decryptReader{f: f, aead: aead, cached: -1}
+	switch {
+	case remainder == 0 && full > 0:
+		r.segments = full
+		r.size = full * segmentSize
+	case remainder >= segmentOverhead:
+		r.segments = full + 1
+		r.size = full*segmentSize + remainder - segmentOverhead
+	default:
+		return nil, fmt.Errorf("%w: truncated file", ErrDecryptionFailed)
+	}
+
+	// Opening the last segment up front rejects truncated or tampered files
+	// before anything is served.
+	if err := r.load(r.segments - 1); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *decryptReader) load(index int64) error {
+	if index == r.cached {
+		return nil
+	}
+
+	offset := int64(encryptedHeaderLen) + index*(segmentSize+segmentOverhead)
+	length := int64(segmentSize + segmentOverhead)
+	if index == r.segments-1 {
+		length = r.size - index*segmentSize + segmentOverhead
+	}
+
+	sealed := make([]byte, length)
+	if _, err := r.f.ReadAt(sealed, offset); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+	}
+
+	plain, err := r.aead.Open(r.plain[:0], segmentNonce(index, index == r.segments-1), sealed, nil)
+	if err != nil {
+		r.cached = -1
+		return ErrDecryptionFailed
+	}
+
+	r.plain = plain
+	r.cached = index
+	return nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+
+	index := r.pos / segmentSize
+	if err := r.load(index); err != nil {
+		return 0, err
+	}
+
+	n := copy(p, r.plain[r.pos-index*segmentSize:])
+	r.pos += int64(n)
+	return n, nil
+}
+
+func (r *decryptReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	r.pos = offset
+	return offset, nil
+}
+
+func (r *decryptReader) Close() error {
+	return r.f.Close()
+}
+
+func isEncryptedHeader(header []byte) bool {
+	return bytes.HasPrefix(header, []byte(encryptedMagic))
+}
+
+// IsEncrypted reports whether the file on disk is in the encrypted format.
+func IsEncrypted(fullPath string) (bool, error) {
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(encryptedMagic))
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+
+	return isEncryptedHeader(header[:n]), nil
+}
+
+// openStored opens a file for reading its plain content, decrypting it when
+// needed, and returns that content's size.
+func openStored(fullPath string) (io.ReadSeekCloser, int64, error) {
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, 0, &os.PathError{Op: "open", Path: fullPath, Err: os.ErrNotExist}
+	}
+
+	header := make([]byte, len(encryptedMagic))
+	n, _ := f.ReadAt(header, 0)
+	if !isEncryptedHeader(header[:n]) {
+		return f, info.Size(), nil
+	}
+
+	key, err := vaultKey()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+
+	r, err := newDecryptReader(f, info.Size(), key)
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+
+	return r, r.size, nil
+}
+
+// writeStored atomically replaces fullPath with the content of r, encrypted
+// according to the current vault setting.
+func writeStored(fullPath string, r io.Reader) error {
+	return writeFile(fullPath, r, encryptNewFiles())
+}
+
+func writeFile(fullPath string, r io.Reader, encrypt bool) error {
+	var key []byte
+	if encrypt {
+		var err error
+		key, err = vaultKey()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create file directory: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	var w io.Writer = temp
+	var encrypter *encryptWriter
+	if encrypt {
+		encrypter, err = newEncryptWriter(temp, key)
+		if err != nil {
+			temp.Close()
+			return fmt.Errorf("failed to encrypt file: %w", err)
+		}
+		w = encrypter
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if encrypter != nil {
+		if err := encrypter.Close(); err != nil {
+			temp.Close()
+			return fmt.Errorf("failed to encrypt file: %w", err)
+		}
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := os.Rename(temp.Name(), fullPath); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// ConvertFile rewrites a file into the encrypted or plain format, keeping its
+// modification time so caches and thumbnails stay valid. It reports whether
+// anything had to change.
+func ConvertFile(fullPath string, encrypt bool) (bool, error) {
+	encrypted, err := IsEncrypted(fullPath)
+	if err != nil {
+		return false, err
+	}
+	if encrypted == encrypt {
+		return false, nil
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return false, err
+	}
+
+	src, _, err := openStored(fullPath)
+	if err != nil {
+		return false, err
+	}
+
+	err = writeFile(fullPath, src, encrypt)
+	src.Close()
+	if err != nil {
+		return false, err
+	}
+
+	return true, os.Chtimes(fullPath, info.ModTime(), info.ModTime())
+}
+
+type ConvertProgress struct {
+	Total     int      `json:"total"`
+	Done      int      `json:"done"`
+	Converted int      `json:"converted"`
+	Failed    []string `json:"failed"`
+}
+
+// ConvertFiles brings every stored file, thumbnails included, into the
+// requested format. progress is called after each file.
+func ConvertFiles(appDataDir string, encrypt bool, progress func(ConvertProgress)) (ConvertProgress, error) {
+	filesDir := filepath.Join(appDataDir, FilesDir)
+	result := ConvertProgress{Failed: []string{}}
+
+	var paths []string
+	err := filepath.WalkDir(filesDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".upload-") && !strings.HasPrefix(entry.Name(), ".thumb-") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Total = len(paths)
+	for _, path := range paths {
+		changed, err := ConvertFile(path, encrypt)
+		if err != nil {
+			if errors.Is(err, ErrVaultLocked) {
+				return result, err
+			}
+			if !os.IsNotExist(err) {
+				relativePath, _ := filepath.Rel(appDataDir, path)
+				result.Failed = append(result.Failed, relativePath)
+			}
+		} else if changed {
+			result.Converted++
+		}
+
+		result.Done++
+		if progress != nil {
+			progress(result)
+		}
+	}
+
+	return result, nil
+}
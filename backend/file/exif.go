@@ -0,0 +1,221 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"strings"
+	"time"
+)
+
+type exifData struct {
+	orientation int
+	takenAt     *time.Time
+	make        string
+	model       string
+	hasGPS      bool
+}
+
+// jpegOrientation returns the EXIF orientation (1-8) of a JPEG stream, or 1
+// when the image has none.
+func jpegOrientation(r io.Reader) int {
+	return parseTIFF(jpegExif(r)).orientation
+}
+
+// jpegExif returns the TIFF block of a JPEG's EXIF segment, if there is one.
+func jpegExif(r io.Reader) []byte {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil || header[0] != 0xFF || header[1] != 0xD8 {
+		return nil
+	}
+
+	for {
+		marker := make([]byte, 4)
+		if _, err := io.ReadFull(r, marker); err != nil || marker[0] != 0xFF {
+			return nil
+		}
+
+		// Start of scan: there is no metadata after this point.
+		if marker[1] == 0xDA {
+			return nil
+		}
+
+		length := int(binary.BigEndian.Uint16(marker[2:]))
+		if length < 2 {
+			return nil
+		}
+
+		segment := make([]byte, length-2)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return nil
+		}
+
+		if marker[1] == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+	}
+}
+
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+)
+
+func parseTIFF(tiff []byte) exifData {
+	data := exifData{orientation: 1}
+	if len(tiff) < 8 {
+		return data
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return data
+	}
+
+	var dateTime, dateTimeOriginal, offsetOriginal string
+	exifOffset := -1
+
+	readIFD(tiff, order, int(order.Uint32(tiff[4:8])), func(tag uint16, entry []byte) {
+		switch tag {
+		case tagOrientation:
+			if orientation := int(order.Uint16(entry[8:])); orientation >= 1 && orientation <= 8 {
+				data.orientation = orientation
+			}
+		case tagMake:
+			data.make = tiffString(tiff, order, entry)
+		case tagModel:
+			data.model = tiffString(tiff, order, entry)
+		case tagDateTime:
+			dateTime = tiffString(tiff, order, entry)
+		case tagExifIFD:
+			exifOffset = int(order.Uint32(entry[8:]))
+		case tagGPSIFD:
+			data.hasGPS = true
+		}
+	})
+
+	if exifOffset >= 0 {
+		readIFD(tiff, order, exifOffset, func(tag uint16, entry []byte) {
+			switch tag {
+			case tagDateTimeOriginal:
+				dateTimeOriginal = tiffString(tiff, order, entry)
+			case tagOffsetOriginal:
+				offsetOriginal = tiffString(tiff, order, entry)
+			}
+		})
+	}
+
+	if dateTimeOriginal != "" {
+		data.takenAt = parseExifTime(dateTimeOriginal, offsetOriginal)
+	} else if dateTime != "" {
+		data.takenAt = parseExifTime(dateTime, "")
+	}
+
+	return data
+}
+
+func readIFD(tiff []byte, order binary.ByteOrder, offset int, visit func(tag uint16, entry []byte)) {
+	if offset < 0 || offset+2 > len(tiff) {
+		return
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return
+		}
+		visit(order.Uint16(tiff[entry:]), tiff[entry:entry+12])
+	}
+}
+
+// tiffString reads an ASCII entry, which is stored inline when it fits in
+// four bytes and at an offset otherwise.
+func tiffString(tiff []byte, order binary.ByteOrder, entry []byte) string {
+	if order.Uint16(entry[2:]) != 2 {
+		return ""
+	}
+
+	count := int(order.Uint32(entry[4:]))
+	var value []byte
+	if count <= 4 {
+		value = entry[8 : 8+count]
+	} else {
+		offset := int(order.Uint32(entry[8:]))
+		if offset < 0 || offset+count > len(tiff) {
+			return ""
+		}
+		value = tiff[offset : offset+count]
+	}
+
+	return strings.TrimSpace(strings.TrimRight(string(value), "\x00"))
+}
+
+// parseExifTime reads "2006:01:02 15:04:05". Without an offset tag the time
+// is the camera's wall clock, which is taken to be local time.
+func parseExifTime(value string, offset string) *time.Time {
+	if offset != "" {
+		if t, err := time.Parse("2006:01:02 15:04:05-07:00", value+offset); err == nil {
+			return &t
+		}
+	}
+
+	t, err := time.ParseInLocation("2006:01:02 15:04:05", value, time.Local)
+	if err != nil || t.Year() < 1900 {
+		return nil
+	}
+	return &t
+}
+
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Orientations 5-8 swap the axes.
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.Set(dx, dy, src.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return dst
+}
@@ -2,18 +2,16 @@ package file
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
-	"time"
-
-	"github.com/google/uuid"
 )
 
 const FilesDir = "files"
@@ -23,14 +21,22 @@ func Initialize(appDataDir string) error {
 	return os.MkdirAll(filesPath, 0755)
 }
 
-func SaveFile(appDataDir string, base64File string, prefix string, fileName string) (string, error) {
+type SavedFile struct {
+	Path  string
+	Image *ImageMetadata
+}
+
+// SaveFile stores a data URL. Photos have their metadata read into the
+// result first, and embedded EXIF, XMP and GPS data removed when
+// stripMetadata is set.
+func SaveFile(appDataDir string, base64File string, fileName string, stripMetadata bool) (SavedFile, error) {
 	if !strings.HasPrefix(base64File, "data:") {
-		return "", errors.New("invalid file format")
+		return SavedFile{}, errors.New("invalid file format")
 	}
 
 	parts := strings.SplitN(base64File, ",", 2)
 	if len(parts) != 2 {
-		return "", errors.New("invalid file data format")
+		return SavedFile{}, errors.New("invalid file data format")
 	}
 
 	mimeType := strings.Split(parts[0], ":")[1]
@@ -45,37 +51,52 @@ func SaveFile(appDataDir string, base64File string, prefix string, fileName stri
 
 	data, err := base64.StdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return "", fmt.Errorf("failed to decode file: %w", err)
+		return SavedFile{}, fmt.Errorf("failed to decode file: %w", err)
 	}
 
-	timestamp := time.Now().UnixNano()
-	safeFileName := sanitizeFileName(fileName)
-	if safeFileName == "" {
-		safeFileName = fmt.Sprintf("%s_%d_%s%s", prefix, timestamp, uuid.New().String(), extension)
-	} else {
-		nameWithoutExt := strings.TrimSuffix(safeFileName, extension)
-		safeFileName = fmt.Sprintf("%s_%d%s", nameWithoutExt, timestamp, extension)
+	var metadata *ImageMetadata
+	if IsThumbnailable(extension) {
+		data, metadata = prepareImage(data, stripMetadata)
 	}
 
-	path := filepath.Join(appDataDir, FilesDir, safeFileName)
-
-	if err := ioutil.WriteFile(path, data, 0644); err != nil {
-		return "", fmt.Errorf("failed to write file: %w", err)
+	relativePath, err := StoreBytes(appDataDir, data, extension)
+	if err != nil {
+		return SavedFile{}, err
 	}
 
-	return filepath.Join(FilesDir, safeFileName), nil
+	return SavedFile{Path: relativePath, Image: metadata}, nil
 }
 
-func GetFilePath(appDataDir string, relativePath string) string {
-	return filepath.Join(appDataDir, relativePath)
+func GetFilePath(appDataDir string, relativePath string) (string, error) {
+	return ResolvePath(appDataDir, relativePath)
 }
 
 func DeleteFile(appDataDir string, relativePath string) error {
 	if relativePath == "" {
 		return nil
 	}
-	fullPath := filepath.Join(appDataDir, relativePath)
-	return os.Remove(fullPath)
+
+	relativePath, err := CleanPath(relativePath)
+	if err != nil {
+		return err
+	}
+
+	fullPath, err := ResolvePath(appDataDir, relativePath)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(fullPath)
+	if err != nil {
+		return err
+	}
+
+	DeleteThumbnails(appDataDir, relativePath)
+
+	if dir := filepath.Dir(fullPath); dir != filepath.Join(appDataDir, FilesDir) {
+		os.Remove(dir)
+	}
+	return nil
 }
 
 func GetFileAsBase64(appDataDir string, relativePath string) (string, error) {
@@ -83,13 +104,13 @@ func GetFileAsBase64(appDataDir string, relativePath string) (string, error) {
 		return "", nil
 	}
 
-	fullPath := filepath.Join(appDataDir, relativePath)
-
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("file does not exist")
+	f, _, err := OpenFile(appDataDir, relativePath)
+	if err != nil {
+		return "", err
 	}
+	defer f.Close()
 
-	data, err := ioutil.ReadFile(fullPath)
+	data, err := io.ReadAll(f)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
@@ -212,123 +233,116 @@ func sanitizeFileName(fileName string) string {
 	return replacer.Replace(fileName)
 }
 
-const ChunkSize = 1024 * 1024 // 1MB
-
-func UploadFileInChunks(appDataDir string, chunkData string, fileName string, chunkIndex int, totalChunks int, sessionId string) (string, error) {
-	if chunkIndex == 0 {
-		tempDir := filepath.Join(appDataDir, "temp", sessionId)
-		if err := os.MkdirAll(tempDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create temp directory: %w", err)
-		}
-	}
-
-	parts := strings.SplitN(chunkData, ",", 2)
-	if len(parts) != 2 {
-		return "", errors.New("invalid chunk data format")
+func StreamFile(w http.ResponseWriter, r *http.Request, fullPath string) {
+	fileInfo, err := os.Stat(fullPath)
+	if err != nil || fileInfo.IsDir() {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
 	}
 
-	data, err := base64.StdEncoding.DecodeString(parts[1])
+	file, size, err := openStored(fullPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode chunk: %w", err)
-	}
-
-	chunkPath := filepath.Join(appDataDir, "temp", sessionId, fmt.Sprintf("chunk_%d", chunkIndex))
-	if err := ioutil.WriteFile(chunkPath, data, 0644); err != nil {
-		return "", fmt.Errorf("failed to write chunk: %w", err)
-	}
-
-	if chunkIndex == totalChunks-1 {
-		ext := filepath.Ext(fileName)
-		if ext == "" {
-			mimeType := strings.Split(parts[0], ":")[1]
-			mimeType = strings.Split(mimeType, ";")[0]
-			ext = mimeTypeToExtension(mimeType)
-		}
-
-		timestamp := time.Now().UnixNano()
-		safeFileName := sanitizeFileName(fileName)
-		finalFileName := ""
-
-		if safeFileName == "" {
-			finalFileName = fmt.Sprintf("%s_%d%s", sessionId, timestamp, ext)
-		} else {
-			nameWithoutExt := strings.TrimSuffix(safeFileName, ext)
-			finalFileName = fmt.Sprintf("%s_%d%s", nameWithoutExt, timestamp, ext)
+		switch {
+		case os.IsNotExist(err):
+			http.Error(w, "File not found", http.StatusNotFound)
+		case errors.Is(err, ErrVaultLocked):
+			http.Error(w, "File is encrypted and the vault is locked", http.StatusForbidden)
+		default:
+			http.Error(w, "Failed to open file", http.StatusInternalServerError)
 		}
-
-		finalPath := filepath.Join(appDataDir, FilesDir, finalFileName)
-
-		destFile, err := os.Create(finalPath)
-		if err != nil {
-			return "", fmt.Errorf("failed to create final file: %w", err)
-		}
-		defer destFile.Close()
-
-		for i := 0; i < totalChunks; i++ {
-			chunkPath := filepath.Join(appDataDir, "temp", sessionId, fmt.Sprintf("chunk_%d", i))
-			chunkData, err := ioutil.ReadFile(chunkPath)
-			if err != nil {
-				return "", fmt.Errorf("failed to read chunk %d: %w", i, err)
-			}
-
-			if _, err := destFile.Write(chunkData); err != nil {
-				return "", fmt.Errorf("failed to write to final file: %w", err)
-			}
-		}
-
-		os.RemoveAll(filepath.Join(appDataDir, "temp", sessionId))
-
-		return filepath.Join(FilesDir, finalFileName), nil
+		return
 	}
+	defer file.Close()
 
-	return "", nil
-}
+	w.Header().Set("Content-Type", detectContentType(file, fullPath))
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Last-Modified", fileInfo.ModTime().UTC().Format(http.TimeFormat))
 
-func StreamFile(w http.ResponseWriter, r *http.Request, fullPath string) {
-	file, err := os.Open(fullPath)
-	if err != nil {
-		http.Error(w, "Failed to open file", http.StatusInternalServerError)
-		return
+	etag := fmt.Sprintf(`"%x-%x"`, fileInfo.ModTime().UnixNano(), size)
+	if hash := ContentHash(fullPath); hash != "" {
+		etag = `"` + hash + `"`
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "private, no-cache")
 	}
-	defer file.Close()
+	w.Header().Set("ETag", etag)
 
-	fileInfo, err := file.Stat()
-	if err != nil {
-		http.Error(w, "Failed to get file info", http.StatusInternalServerError)
+	if match := r.Header.Get("If-None-Match"); match != "" && match == etag {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
-	contentType := extensionToMimeType(filepath.Ext(fullPath))
-	w.Header().Set("Content-Type", contentType)
+	start, length := int64(0), size
+	status := http.StatusOK
 
 	rangeHeader := r.Header.Get("Range")
-	if rangeHeader != "" {
-		ranges, err := parseRange(rangeHeader, fileInfo.Size())
-		if err != nil {
+	if rangeHeader != "" && (r.Header.Get("If-Range") == "" || r.Header.Get("If-Range") == etag) {
+		ranges, err := parseRange(rangeHeader, size)
+		if err != nil || len(ranges) == 0 {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
 			http.Error(w, "Invalid range", http.StatusRequestedRangeNotSatisfiable)
 			return
 		}
 
 		if len(ranges) > 1 {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
 			http.Error(w, "Multiple ranges not supported", http.StatusRequestedRangeNotSatisfiable)
 			return
 		}
 
-		w.WriteHeader(http.StatusPartialContent)
+		start = ranges[0].start
+		length = ranges[0].end - ranges[0].start + 1
+		status = http.StatusPartialContent
 
 		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d",
-			ranges[0].start, ranges[0].end, fileInfo.Size()))
+			ranges[0].start, ranges[0].end, size))
+	}
 
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", ranges[0].end-ranges[0].start+1))
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	w.WriteHeader(status)
 
-		file.Seek(ranges[0].start, io.SeekStart)
+	if r.Method == http.MethodHead {
+		return
+	}
 
-		io.CopyN(w, file, ranges[0].end-ranges[0].start+1)
-	} else {
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", fileInfo.Size()))
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return
+	}
+	io.CopyN(w, file, length)
+}
+
+func detectContentType(file io.Reader, fullPath string) string {
+	contentType := extensionToMimeType(strings.ToLower(filepath.Ext(fullPath)))
+	if contentType != "application/octet-stream" {
+		return contentType
+	}
+
+	buffer := make([]byte, 512)
+	n, _ := io.ReadFull(file, buffer)
+	return http.DetectContentType(buffer[:n])
+}
+
+// DetectMimeType sniffs the content of a stored file, falling back to the
+// extension only when the content itself is not recognised.
+func DetectMimeType(fullPath string) (string, error) {
+	file, _, err := openStored(fullPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buffer := make([]byte, 512)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
 
-		io.Copy(w, file)
+	contentType := http.DetectContentType(buffer[:n])
+	if contentType == "application/octet-stream" {
+		contentType = extensionToMimeType(strings.ToLower(filepath.Ext(fullPath)))
 	}
+
+	return contentType, nil
 }
 
 type httpRange struct {
@@ -368,6 +382,9 @@ func parseRange(rangeHeader string, fileSize int64) ([]httpRange, error) {
 			}
 
 			start = fileSize - end
+			if start < 0 {
+				start = 0
+			}
 			end = fileSize - 1
 		} else {
 			start, err = strconv.ParseInt(parts[0], 10, 64)
@@ -382,6 +399,9 @@ func parseRange(rangeHeader string, fileSize int64) ([]httpRange, error) {
 				if err != nil {
 					return nil, err
 				}
+				if end >= fileSize {
+					end = fileSize - 1
+				}
 			}
 		}
 
@@ -394,3 +414,47 @@ func parseRange(rangeHeader string, fileSize int64) ([]httpRange, error) {
 
 	return ranges, nil
 }
+
+func IsFileReference(value string) bool {
+	if value == "" || strings.HasPrefix(value, "data:") {
+		return false
+	}
+
+	return strings.HasPrefix(value, FilesDir+"/") || strings.HasPrefix(value, FilesDir+string(filepath.Separator))
+}
+
+func CollectReferences(data interface{}) []string {
+	var references []string
+
+	switch v := data.(type) {
+	case string:
+		if IsFileReference(v) {
+			references = append(references, v)
+		}
+	case map[string]interface{}:
+		for _, value := range v {
+			references = append(references, CollectReferences(value)...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			references = append(references, CollectReferences(item)...)
+		}
+	}
+
+	return references
+}
+
+var quotedString = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// ScanReferences finds the references in record data that is not valid JSON
+// by looking at each quoted string in it.
+func ScanReferences(raw []byte) []string {
+	var references []string
+	for _, quoted := range quotedString.FindAll(raw, -1) {
+		var value string
+		if json.Unmarshal(quoted, &value) == nil && IsFileReference(value) {
+			references = append(references, value)
+		}
+	}
+	return references
+}
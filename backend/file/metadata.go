@@ -0,0 +1,264 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"time"
+)
+
+// ImageMetadata is what is worth keeping from a photo before its embedded
+// metadata is stripped. Width and Height are as displayed, after orientation.
+type ImageMetadata struct {
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+	Orientation int        `json:"orientation"`
+	TakenAt     *time.Time `json:"takenAt,omitempty"`
+	CameraMake  string     `json:"cameraMake,omitempty"`
+	CameraModel string     `json:"cameraModel,omitempty"`
+	HadLocation bool       `json:"hadLocation"`
+	Stripped    bool       `json:"stripped"`
+}
+
+func ExtractImageMetadata(data []byte) (*ImageMetadata, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotAnImage
+	}
+
+	var tiff []byte
+	switch format {
+	case "jpeg":
+		tiff = jpegExif(bytes.NewReader(data))
+	case "png":
+		tiff = pngExif(data)
+	case "webp":
+		tiff = webpExif(data)
+	}
+
+	exif := parseTIFF(tiff)
+	metadata := &ImageMetadata{
+		Width:       config.Width,
+		Height:      config.Height,
+		Orientation: exif.orientation,
+		TakenAt:     exif.takenAt,
+		CameraMake:  exif.make,
+		CameraModel: exif.model,
+		HadLocation: exif.hasGPS,
+	}
+	if exif.orientation >= 5 {
+		metadata.Width, metadata.Height = config.Height, config.Width
+	}
+
+	return metadata, nil
+}
+
+func ReadImageMetadata(fullPath string) (*ImageMetadata, error) {
+	f, _, err := openStored(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return ExtractImageMetadata(data)
+}
+
+// StripImageMetadata removes EXIF, XMP, IPTC, GPS and comment data from JPEG,
+// PNG and WebP images without re-encoding them. JPEGs keep a minimal EXIF
+// block holding only the orientation so they still display upright. Other
+// formats, and files that cannot be parsed, are returned unchanged with
+// false.
+func StripImageMetadata(data []byte) ([]byte, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		if stripped, ok := stripJPEG(data); ok {
+			return stripped, true
+		}
+	case bytes.HasPrefix(data, pngSignature):
+		if stripped, ok := stripPNG(data); ok {
+			return stripped, true
+		}
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		if stripped, ok := stripWebP(data); ok {
+			return stripped, true
+		}
+	}
+	return data, false
+}
+
+// prepareImage reads what a photo says about itself and, when asked, strips
+// that metadata before the photo is stored.
+func prepareImage(data []byte, strip bool) ([]byte, *ImageMetadata) {
+	metadata, err := ExtractImageMetadata(data)
+	if err != nil {
+		return data, nil
+	}
+
+	if strip {
+		data, metadata.Stripped = StripImageMetadata(data)
+	}
+
+	return data, metadata
+}
+
+func stripJPEG(data []byte) ([]byte, bool) {
+	orientation := jpegOrientation(bytes.NewReader(data))
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	inserted := orientation <= 1
+
+	pos := 2
+	for {
+		// Skip fill bytes between segments.
+		for pos+1 < len(data) && data[pos] == 0xFF && data[pos+1] == 0xFF {
+			pos++
+		}
+		if pos+4 > len(data) || data[pos] != 0xFF {
+			return nil, false
+		}
+
+		marker := data[pos+1]
+		if marker == 0xDA {
+			if !inserted {
+				out = append(out, orientationSegment(orientation)...)
+			}
+			return append(out, data[pos:]...), true
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, false
+		}
+
+		switch marker {
+		case 0xE1, 0xED, 0xFE:
+			// APP1 (EXIF, XMP), APP13 (IPTC) and comments are dropped.
+		default:
+			// The orientation block goes after JFIF, which must stay first.
+			if !inserted && marker != 0xE0 {
+				out = append(out, orientationSegment(orientation)...)
+				inserted = true
+			}
+			out = append(out, data[pos:end]...)
+		}
+
+		pos = end
+	}
+}
+
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+
+func forEachPNGChunk(data []byte, visit func(chunkType string, chunk []byte, body []byte)) bool {
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return false
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return false
+		}
+		visit(string(data[pos+4:pos+8]), data[pos:end], data[pos+8:pos+8+length])
+		pos = end
+	}
+	return true
+}
+
+func pngExif(data []byte) []byte {
+	var tiff []byte
+	forEachPNGChunk(data, func(chunkType string, _ []byte, body []byte) {
+		if chunkType == "eXIf" && tiff == nil {
+			tiff = body
+		}
+	})
+	return tiff
+}
+
+func stripPNG(data []byte) ([]byte, bool) {
+	out := append(make([]byte, 0, len(data)), pngSignature...)
+	ok := forEachPNGChunk(data, func(chunkType string, chunk []byte, body []byte) {
+		switch chunkType {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, chunk...)
+		}
+	})
+	return out, ok
+}
+
+func forEachWebPChunk(data []byte, visit func(fourCC string, chunk []byte, body []byte)) bool {
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return false
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if size < 0 || pos+8+size > len(data) {
+			return false
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		visit(string(data[pos:pos+4]), data[pos:end], data[pos+8:pos+8+size])
+		pos = end
+	}
+	return true
+}
+
+func webpExif(data []byte) []byte {
+	var tiff []byte
+	forEachWebPChunk(data, func(fourCC string, _ []byte, body []byte) {
+		if fourCC == "EXIF" && tiff == nil {
+			tiff = bytes.TrimPrefix(body, []byte("Exif\x00\x00"))
+		}
+	})
+	return tiff
+}
+
+func stripWebP(data []byte) ([]byte, bool) {
+	out := append(make([]byte, 0, len(data)), data[:12]...)
+	ok := forEachWebPChunk(data, func(fourCC string, chunk []byte, body []byte) {
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			// Clear the EXIF and XMP flags to match the removed chunks.
+			start := len(out)
+			out = append(out, chunk...)
+			if len(body) > 0 {
+				out[start+8] &^= 0x08 | 0x04
+			}
+		default:
+			out = append(out, chunk...)
+		}
+	})
+	if !ok {
+		return nil, false
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, true
+}
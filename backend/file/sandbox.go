@@ -0,0 +1,138 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Every path that reaches the file package comes from record data or the
+// frontend, so it is resolved here before anything touches the disk.
+
+var (
+	ErrInvalidPath  = errors.New("invalid file path")
+	ErrOutsideFiles = errors.New("path is outside the files directory")
+	ErrSymlink      = errors.New("path escapes the files directory through a symlink")
+	ErrNotFound     = errors.New("file does not exist")
+)
+
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%v: %q", e.Err, e.Path)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// CleanPath returns the canonical form of a stored file path, which must name
+// something strictly inside files/.
+func CleanPath(relativePath string) (string, error) {
+	if relativePath == "" || strings.ContainsRune(relativePath, 0) {
+		return "", &PathError{Path: relativePath, Err: ErrInvalidPath}
+	}
+
+	normalized := filepath.FromSlash(strings.ReplaceAll(relativePath, `\`, "/"))
+	if filepath.IsAbs(normalized) || filepath.VolumeName(normalized) != "" {
+		return "", &PathError{Path: relativePath, Err: ErrOutsideFiles}
+	}
+
+	cleaned := filepath.Clean(normalized)
+	if !strings.HasPrefix(cleaned, FilesDir+string(filepath.Separator)) {
+		return "", &PathError{Path: relativePath, Err: ErrOutsideFiles}
+	}
+
+	return cleaned, nil
+}
+
+// ResolvePath maps a stored file path onto the disk. Symlinks inside files/
+// are followed only as long as they stay inside it.
+func ResolvePath(appDataDir string, relativePath string) (string, error) {
+	cleaned, err := CleanPath(relativePath)
+	if err != nil {
+		return "", err
+	}
+
+	root := filepath.Join(appDataDir, FilesDir)
+	fullPath := filepath.Join(appDataDir, cleaned)
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fullPath, nil
+		}
+		return "", err
+	}
+
+	realPath, err := resolveExisting(fullPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !within(realRoot, realPath) {
+		return "", &PathError{Path: relativePath, Err: ErrSymlink}
+	}
+
+	return fullPath, nil
+}
+
+// resolveExisting follows symlinks in the longest existing prefix of path and
+// appends the parts that do not exist yet.
+func resolveExisting(path string) (string, error) {
+	var missing []string
+	current := path
+
+	for {
+		resolved, err := filepath.EvalSymlinks(current)
+		if err == nil {
+			for i := len(missing) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, missing[i])
+			}
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path, nil
+		}
+		missing = append(missing, filepath.Base(current))
+		current = parent
+	}
+}
+
+func within(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// OpenFile opens a stored file for reading its plain content after resolving
+// it, and returns the size of that content.
+func OpenFile(appDataDir string, relativePath string) (io.ReadSeekCloser, int64, error) {
+	fullPath, err := ResolvePath(appDataDir, relativePath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, size, err := openStored(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, &PathError{Path: relativePath, Err: ErrNotFound}
+		}
+		return nil, 0, err
+	}
+
+	return f, size, nil
+}
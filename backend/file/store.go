@@ -0,0 +1,108 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Attachments are stored by the SHA-256 of their content under
+// files/<first two hex chars>/<hash><ext>, so identical uploads share a blob.
+
+func contentPath(hash string, extension string) string {
+	return filepath.Join(FilesDir, hash[:2], hash+strings.ToLower(extension))
+}
+
+func ContentHash(relativePath string) string {
+	name := filepath.Base(relativePath)
+	hash := strings.TrimSuffix(name, filepath.Ext(name))
+	if len(hash) != sha256.Size*2 {
+		return ""
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return ""
+	}
+	if filepath.Base(filepath.Dir(relativePath)) != hash[:2] {
+		return ""
+	}
+	return hash
+}
+
+func StoreBytes(appDataDir string, data []byte, extension string) (string, error) {
+	sum := sha256.Sum256(data)
+	relativePath := contentPath(hex.EncodeToString(sum[:]), extension)
+	fullPath := filepath.Join(appDataDir, relativePath)
+
+	if _, err := os.Stat(fullPath); err == nil {
+		return relativePath, nil
+	}
+
+	if err := writeStored(fullPath, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+
+	return relativePath, nil
+}
+
+// StoreFile moves an already written file into the content-addressed layout.
+// The source file is consumed either way.
+func StoreFile(appDataDir string, sourcePath string, extension string) (string, error) {
+	defer os.Remove(sourcePath)
+
+	hash, _, err := HashFile(sourcePath)
+	if err != nil {
+		return "", err
+	}
+
+	relativePath := contentPath(hash, extension)
+	fullPath := filepath.Join(appDataDir, relativePath)
+
+	if _, err := os.Stat(fullPath); err == nil {
+		return relativePath, nil
+	}
+
+	if encryptNewFiles() {
+		source, err := os.Open(sourcePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to open file: %w", err)
+		}
+		defer source.Close()
+
+		if err := writeStored(fullPath, source); err != nil {
+			return "", err
+		}
+		return relativePath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create file directory: %w", err)
+	}
+
+	if err := os.Rename(sourcePath, fullPath); err != nil {
+		return "", fmt.Errorf("failed to move file into place: %w", err)
+	}
+
+	return relativePath, nil
+}
+
+// HashFile hashes the plain content, so encrypted files keep their address.
+func HashFile(fullPath string) (string, int64, error) {
+	f, _, err := openStored(fullPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
@@ -0,0 +1,181 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const ThumbnailsDir = ".thumbnails"
+
+var ThumbnailSizes = []struct {
+	Name string
+	Max  int
+}{
+	{Name: "small", Max: 160},
+	{Name: "medium", Max: 480},
+	{Name: "large", Max: 1280},
+}
+
+var ErrNotAnImage = errors.New("file is not a supported image")
+
+func thumbnailMax(size string) (int, bool) {
+	for _, s := range ThumbnailSizes {
+		if s.Name == size {
+			return s.Max, true
+		}
+	}
+	return 0, false
+}
+
+func IsThumbnailable(relativePath string) bool {
+	switch strings.ToLower(filepath.Ext(relativePath)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		return true
+	}
+	return false
+}
+
+// ThumbnailPath is where the given size of an original lives: a hidden
+// directory beside the original, so thumbnails move and vanish with it.
+func ThumbnailPath(relativePath string, size string) string {
+	name := filepath.Base(relativePath)
+	ext := strings.ToLower(filepath.Ext(name))
+
+	thumbExt := ".jpg"
+	if ext == ".png" || ext == ".gif" {
+		thumbExt = ".png"
+	}
+
+	return filepath.Join(filepath.Dir(relativePath), ThumbnailsDir, strings.TrimSuffix(name, filepath.Ext(name))+"-"+size+thumbExt)
+}
+
+func Thumbnail(appDataDir string, relativePath string, size string) (string, error) {
+	maxDimension, ok := thumbnailMax(size)
+	if !ok {
+		return "", fmt.Errorf("unknown thumbnail size '%s'", size)
+	}
+
+	if !IsThumbnailable(relativePath) {
+		return "", ErrNotAnImage
+	}
+
+	relativePath, err := CleanPath(relativePath)
+	if err != nil {
+		return "", err
+	}
+
+	originalPath, err := ResolvePath(appDataDir, relativePath)
+	if err != nil {
+		return "", err
+	}
+
+	originalInfo, err := os.Stat(originalPath)
+	if err != nil {
+		return "", err
+	}
+
+	thumbPath := ThumbnailPath(relativePath, size)
+	fullThumbPath, err := ResolvePath(appDataDir, thumbPath)
+	if err != nil {
+		return "", err
+	}
+
+	if info, err := os.Stat(fullThumbPath); err == nil && !info.ModTime().Before(originalInfo.ModTime()) {
+		return thumbPath, nil
+	}
+
+	err = writeThumbnail(originalPath, fullThumbPath, maxDimension)
+	if err != nil {
+		return "", err
+	}
+
+	return thumbPath, nil
+}
+
+func GenerateThumbnails(appDataDir string, relativePath string) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, size := range ThumbnailSizes {
+		path, err := Thumbnail(appDataDir, relativePath, size.Name)
+		if err != nil {
+			return nil, err
+		}
+		paths[size.Name] = path
+	}
+	return paths, nil
+}
+
+func DeleteThumbnails(appDataDir string, relativePath string) {
+	for _, size := range ThumbnailSizes {
+		if thumbPath, err := ResolvePath(appDataDir, ThumbnailPath(relativePath, size.Name)); err == nil {
+			os.Remove(thumbPath)
+		}
+	}
+	if dir, err := ResolvePath(appDataDir, filepath.Join(filepath.Dir(relativePath), ThumbnailsDir)); err == nil {
+		os.Remove(dir)
+	}
+}
+
+func writeThumbnail(originalPath string, thumbPath string, maxDimension int) error {
+	src, _, err := openStored(originalPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	img, format, err := image.Decode(src)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotAnImage, err)
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		if _, err := src.Seek(0, 0); err == nil {
+			orientation = jpegOrientation(src)
+		}
+	}
+
+	thumb := applyOrientation(resize(img, maxDimension), orientation)
+
+	var encoded bytes.Buffer
+	if strings.HasSuffix(thumbPath, ".png") {
+		err = png.Encode(&encoded, thumb)
+	} else {
+		err = jpeg.Encode(&encoded, thumb, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	return writeStored(thumbPath, &encoded)
+}
+
+func resize(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= maxDimension && height <= maxDimension {
+		return img
+	}
+
+	newWidth, newHeight := maxDimension, maxDimension
+	if width > height {
+		newHeight = max(1, height*maxDimension/width)
+	} else {
+		newWidth = max(1, width*maxDimension/height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
@@ -0,0 +1,381 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ChunkSize    = 1024 * 1024 // 1MB
+	MaxChunkSize = 16 * 1024 * 1024
+	UploadsDir   = "temp/uploads"
+)
+
+var (
+	ErrUploadNotFound   = errors.New("upload session not found")
+	ErrChunkOutOfRange  = errors.New("chunk index out of range")
+	ErrChunkSize        = errors.New("chunk has the wrong size")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrUploadIncomplete = errors.New("upload is missing chunks")
+)
+
+type UploadSession struct {
+	ID          string    `json:"id"`
+	FileName    string    `json:"fileName"`
+	Size        int64     `json:"size"`
+	ChunkSize   int64     `json:"chunkSize"`
+	TotalChunks int       `json:"totalChunks"`
+	SHA256      string    `json:"sha256,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type UploadStatus struct {
+	Session  UploadSession `json:"session"`
+	Received int           `json:"received"`
+	Missing  []int         `json:"missing"`
+	Complete bool          `json:"complete"`
+}
+
+func uploadDir(appDataDir string, sessionID string) (string, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return "", ErrUploadNotFound
+	}
+	return filepath.Join(appDataDir, filepath.FromSlash(UploadsDir), sessionID), nil
+}
+
+func chunkFileName(index int) string {
+	return fmt.Sprintf("chunk_%d", index)
+}
+
+func BeginUpload(appDataDir string, fileName string, size int64, chunkSize int64, sha256Hex string) (UploadSession, error) {
+	if size < 0 {
+		return UploadSession{}, fmt.Errorf("invalid file size %d", size)
+	}
+	if chunkSize <= 0 {
+		chunkSize = ChunkSize
+	}
+	if chunkSize > MaxChunkSize {
+		return UploadSession{}, fmt.Errorf("chunk size may not exceed %d bytes", MaxChunkSize)
+	}
+
+	sha256Hex = strings.ToLower(strings.TrimSpace(sha256Hex))
+	if sha256Hex != "" {
+		if decoded, err := hex.DecodeString(sha256Hex); err != nil || len(decoded) != sha256.Size {
+			return UploadSession{}, fmt.Errorf("invalid SHA-256 checksum")
+		}
+	}
+
+	totalChunks := int((size + chunkSize - 1) / chunkSize)
+	if totalChunks == 0 {
+		totalChunks = 1
+	}
+
+	session := UploadSession{
+		ID:          uuid.New().String(),
+		FileName:    filepath.Base(fileName),
+		Size:        size,
+		ChunkSize:   chunkSize,
+		TotalChunks: totalChunks,
+		SHA256:      sha256Hex,
+		CreatedAt:   time.Now(),
+	}
+
+	dir, _ := uploadDir(appDataDir, session.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return UploadSession{}, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return UploadSession{}, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "session.json"), data, 0644); err != nil {
+		os.RemoveAll(dir)
+		return UploadSession{}, fmt.Errorf("failed to save upload session: %w", err)
+	}
+
+	return session, nil
+}
+
+func loadUpload(appDataDir string, sessionID string) (UploadSession, string, error) {
+	dir, err := uploadDir(appDataDir, sessionID)
+	if err != nil {
+		return UploadSession{}, "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "session.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return UploadSession{}, "", ErrUploadNotFound
+		}
+		return UploadSession{}, "", err
+	}
+
+	var session UploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return UploadSession{}, "", fmt.Errorf("corrupt upload session: %w", err)
+	}
+
+	return session, dir, nil
+}
+
+func (s UploadSession) expectedChunkSize(index int) int64 {
+	if index == s.TotalChunks-1 {
+		return s.Size - int64(index)*s.ChunkSize
+	}
+	return s.ChunkSize
+}
+
+// WriteChunk stores one chunk. Chunks may arrive in any order and may be
+// sent again; a retried chunk simply replaces the earlier copy.
+func WriteChunk(appDataDir string, sessionID string, index int, chunkData string, chunkSHA256 string) (UploadStatus, error) {
+	session, dir, err := loadUpload(appDataDir, sessionID)
+	if err != nil {
+		return UploadStatus{}, err
+	}
+
+	if index < 0 || index >= session.TotalChunks {
+		return UploadStatus{}, fmt.Errorf("%w: %d of %d", ErrChunkOutOfRange, index, session.TotalChunks)
+	}
+
+	if _, encoded, found := strings.Cut(chunkData, ","); found && strings.HasPrefix(chunkData, "data:") {
+		chunkData = encoded
+	}
+
+	data, err := base64.StdEncoding.DecodeString(chunkData)
+	if err != nil {
+		return UploadStatus{}, fmt.Errorf("failed to decode chunk: %w", err)
+	}
+
+	if expected := session.expectedChunkSize(index); int64(len(data)) != expected {
+		return UploadStatus{}, fmt.Errorf("%w: chunk %d is %d bytes, expected %d", ErrChunkSize, index, len(data), expected)
+	}
+
+	if chunkSHA256 != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), strings.TrimSpace(chunkSHA256)) {
+			return UploadStatus{}, fmt.Errorf("%w: chunk %d", ErrChecksumMismatch, index)
+		}
+	}
+
+	temp, err := os.CreateTemp(dir, chunkFileName(index)+".*.part")
+	if err != nil {
+		return UploadStatus{}, fmt.Errorf("failed to write chunk: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return UploadStatus{}, fmt.Errorf("failed to write chunk: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return UploadStatus{}, fmt.Errorf("failed to write chunk: %w", err)
+	}
+
+	if err := os.Rename(temp.Name(), filepath.Join(dir, chunkFileName(index))); err != nil {
+		return UploadStatus{}, fmt.Errorf("failed to write chunk: %w", err)
+	}
+
+	return uploadStatus(session, dir)
+}
+
+func GetUploadStatus(appDataDir string, sessionID string) (UploadStatus, error) {
+	session, dir, err := loadUpload(appDataDir, sessionID)
+	if err != nil {
+		return UploadStatus{}, err
+	}
+
+	return uploadStatus(session, dir)
+}
+
+func uploadStatus(session UploadSession, dir string) (UploadStatus, error) {
+	status := UploadStatus{Session: session, Missing: []int{}}
+
+	for i := 0; i < session.TotalChunks; i++ {
+		info, err := os.Stat(filepath.Join(dir, chunkFileName(i)))
+		if err == nil && info.Size() == session.expectedChunkSize(i) {
+			status.Received++
+			continue
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return UploadStatus{}, err
+		}
+		status.Missing = append(status.Missing, i)
+	}
+
+	status.Complete = len(status.Missing) == 0
+	return status, nil
+}
+
+// Larger photos are stored as uploaded rather than read into memory to strip
+// their metadata.
+const maxPreparedImageSize = 64 * 1024 * 1024
+
+// FinishUpload assembles the chunks, checks the size and whole-file checksum,
+// and moves the result into the content-addressed store. Photos are handled
+// as in SaveFile. A file that does not match is not resumable, so its session
+// is removed.
+func FinishUpload(appDataDir string, sessionID string, stripMetadata bool) (SavedFile, error) {
+	session, dir, err := loadUpload(appDataDir, sessionID)
+	if err != nil {
+		return SavedFile{}, err
+	}
+
+	status, err := uploadStatus(session, dir)
+	if err != nil {
+		return SavedFile{}, err
+	}
+	if !status.Complete {
+		return SavedFile{}, fmt.Errorf("%w: %d of %d chunks missing", ErrUploadIncomplete, len(status.Missing), session.TotalChunks)
+	}
+
+	assembledPath := filepath.Join(dir, "assembled")
+	assembled, err := os.Create(assembledPath)
+	if err != nil {
+		return SavedFile{}, fmt.Errorf("failed to create final file: %w", err)
+	}
+
+	hasher := sha256.New()
+	writer := io.MultiWriter(assembled, hasher)
+	var written int64
+
+	for i := 0; i < session.TotalChunks; i++ {
+		chunk, err := os.Open(filepath.Join(dir, chunkFileName(i)))
+		if err != nil {
+			assembled.Close()
+			return SavedFile{}, fmt.Errorf("failed to read chunk %d: %w", i, err)
+		}
+
+		n, err := io.Copy(writer, chunk)
+		chunk.Close()
+		if err != nil {
+			assembled.Close()
+			return SavedFile{}, fmt.Errorf("failed to write to final file: %w", err)
+		}
+		written += n
+	}
+
+	if err := assembled.Close(); err != nil {
+		return SavedFile{}, fmt.Errorf("failed to write to final file: %w", err)
+	}
+
+	if written != session.Size {
+		os.RemoveAll(dir)
+		return SavedFile{}, fmt.Errorf("%w: assembled %d bytes, expected %d", ErrChunkSize, written, session.Size)
+	}
+
+	if session.SHA256 != "" && hex.EncodeToString(hasher.Sum(nil)) != session.SHA256 {
+		os.RemoveAll(dir)
+		return SavedFile{}, fmt.Errorf("%w: whole file", ErrChecksumMismatch)
+	}
+
+	extension := filepath.Ext(session.FileName)
+
+	var saved SavedFile
+	if IsThumbnailable(extension) && written <= maxPreparedImageSize {
+		data, err := os.ReadFile(assembledPath)
+		if err != nil {
+			return SavedFile{}, fmt.Errorf("failed to read final file: %w", err)
+		}
+
+		data, saved.Image = prepareImage(data, stripMetadata)
+		saved.Path, err = StoreBytes(appDataDir, data, extension)
+		if err != nil {
+			return SavedFile{}, err
+		}
+	} else {
+		saved.Path, err = StoreFile(appDataDir, assembledPath, extension)
+		if err != nil {
+			return SavedFile{}, err
+		}
+	}
+
+	os.RemoveAll(dir)
+	return saved, nil
+}
+
+func AbortUpload(appDataDir string, sessionID string) error {
+	dir, err := uploadDir(appDataDir, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// CleanupStaleUploads removes upload sessions that have not received a chunk
+// within maxAge, along with leftovers from the old temp/<session> layout.
+func CleanupStaleUploads(appDataDir string, maxAge time.Duration) (int, error) {
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+
+	uploadsRoot := filepath.Join(appDataDir, filepath.FromSlash(UploadsDir))
+	entries, err := os.ReadDir(uploadsRoot)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(uploadsRoot, entry.Name())
+		if latestModTime(dir).Before(cutoff) {
+			if err := os.RemoveAll(dir); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+
+	tempRoot := filepath.Join(appDataDir, "temp")
+	legacy, err := os.ReadDir(tempRoot)
+	if err != nil && !os.IsNotExist(err) {
+		return removed, err
+	}
+
+	for _, entry := range legacy {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := uuid.Parse(entry.Name()); err != nil {
+			continue
+		}
+
+		dir := filepath.Join(tempRoot, entry.Name())
+		if latestModTime(dir).Before(cutoff) {
+			if err := os.RemoveAll(dir); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+func latestModTime(dir string) time.Time {
+	var latest time.Time
+
+	if info, err := os.Stat(dir); err == nil {
+		latest = info.ModTime()
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,146 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"myproject/backend/database"
+	"myproject/backend/file"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Uploads are saved before the record that points at them, so blobs that are
+// still unreferenced get this long before they are garbage collected.
+const unreferencedFileGracePeriod = 24 * time.Hour
+
+func (a *App) registerFile(relativePath string, originalName string, image *file.ImageMetadata) error {
+	if relativePath == "" {
+		return nil
+	}
+
+	hash := file.ContentHash(relativePath)
+	fullPath, err := file.GetFilePath(a.filesRoot, relativePath)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return err
+	}
+
+	if hash == "" {
+		hash, _, err = file.HashFile(fullPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	mimeType, err := file.DetectMimeType(fullPath)
+	if err != nil {
+		return err
+	}
+
+	// Files that were not uploaded through SaveFile still get what their
+	// own metadata says; a file that cannot be decoded is simply not a photo.
+	if image == nil && file.IsThumbnailable(relativePath) {
+		image, _ = file.ReadImageMetadata(fullPath)
+	}
+
+	return database.RegisterFile(relativePath, hash, info.Size(), originalName, mimeType, image)
+}
+
+func (a *App) releaseFiles(paths []string) {
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		unreferenced, err := database.ReleaseFile(path)
+		if err != nil {
+			log.Printf("Error releasing file %s: %v", path, err)
+			continue
+		}
+
+		if unreferenced {
+			err = file.DeleteFile(a.filesRoot, path)
+			if err != nil && !os.IsNotExist(err) {
+				log.Printf("Error deleting file %s: %v", path, err)
+			}
+		}
+	}
+}
+
+func (a *App) syncFileStore() error {
+	err := database.RunMigrationOnce("file_references", database.RebuildFileReferences)
+	if err != nil {
+		return err
+	}
+
+	missing, err := database.ListFilesMissingMetadata()
+	if err != nil {
+		return err
+	}
+	for _, path := range missing {
+		originalName := ""
+		if file.ContentHash(path) == "" {
+			originalName = filepath.Base(path)
+		}
+		if err := a.registerFile(path, originalName, nil); err != nil && !os.IsNotExist(err) {
+			log.Printf("Error hashing file %s: %v", path, err)
+		}
+	}
+
+	unreferenced, err := database.ListUnreferencedFiles(time.Now().Add(-unreferencedFileGracePeriod))
+	if err != nil {
+		return err
+	}
+	a.releaseFiles(unreferenced)
+
+	return nil
+}
+
+func removedReferences(previous map[string]interface{}, current map[string]interface{}) []string {
+	remaining := make(map[string]int)
+	for _, path := range file.CollectReferences(current) {
+		remaining[path]++
+	}
+
+	var removed []string
+	for _, path := range file.CollectReferences(previous) {
+		if remaining[path] > 0 {
+			remaining[path]--
+			continue
+		}
+		removed = append(removed, path)
+	}
+
+	return removed
+}
+
+func NewFileHandler(a *App) http.Handler {
+	return file.NewAssetHandler(func() string {
+		return a.filesRoot
+	})
+}
+
+func (a *App) ListFiles(filterJSON string) ([]database.FileEntry, error) {
+	var filter database.FileFilter
+	if filterJSON != "" {
+		err := json.Unmarshal([]byte(filterJSON), &filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file filter: %w", err)
+		}
+	}
+
+	return database.ListFiles(filter)
+}
+
+func (a *App) GetFileInfo(relativePath string) (database.FileEntry, error) {
+	return database.GetFileEntry(relativePath)
+}
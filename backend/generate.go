@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+	"myproject/backend/generator"
+)
+
+// GenerateData fills the datasets with seeded synthetic records. Everything it
+// writes is tracked so CleanupGeneratedData can take it out again.
+func (a *App) GenerateData(optionsJSON string) (database.GeneratedBatch, error) {
+	var options generator.Options
+	if optionsJSON != "" {
+		err := json.Unmarshal([]byte(optionsJSON), &options)
+		if err != nil {
+			return database.GeneratedBatch{}, fmt.Errorf("invalid generator options: %w", err)
+		}
+	}
+
+	plan, err := generator.Generate(options)
+	if err != nil {
+		return database.GeneratedBatch{}, err
+	}
+
+	datasets, err := database.ListDatasets()
+	if err != nil {
+		return database.GeneratedBatch{}, err
+	}
+	withFiles := make(map[string]bool)
+	for _, dataset := range datasets {
+		for _, field := range dataset.Fields {
+			if field.Type == database.FieldTypeFile || field.Type == database.FieldTypeFileMultiple {
+				withFiles[dataset.ID] = true
+			}
+		}
+	}
+
+	// Attachments arrive as data URLs and are stored like any upload.
+	for i, record := range plan.Records {
+		if !withFiles[record.DatasetID] {
+			continue
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal(record.Data, &data); err != nil {
+			return database.GeneratedBatch{}, err
+		}
+
+		processed, err := a.processDataWithExistingFiles(map[string]interface{}{}, data, record.DatasetID)
+		if err != nil {
+			return database.GeneratedBatch{}, fmt.Errorf("failed to store generated attachments: %w", err)
+		}
+
+		plan.Records[i].Data, err = json.Marshal(processed)
+		if err != nil {
+			return database.GeneratedBatch{}, err
+		}
+	}
+
+	encoded, err := json.Marshal(plan.Options)
+	if err != nil {
+		return database.GeneratedBatch{}, err
+	}
+
+	return database.InsertGeneratedRecords(plan.Options.Seed, encoded, plan.Records)
+}
+
+func (a *App) ListGeneratedBatches() ([]database.GeneratedBatch, error) {
+	return database.ListGeneratedBatches()
+}
+
+// CleanupGeneratedData deletes every generated record that nothing entered by
+// hand depends on, along with attachments only those records used.
+func (a *App) CleanupGeneratedData() (database.GeneratedCleanup, error) {
+	result, err := database.DeleteGeneratedRecords()
+	if err != nil {
+		return result, err
+	}
+
+	a.releaseFiles(result.ReleasedFiles)
+
+	return result, nil
+}
@@ -0,0 +1,392 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"myproject/backend/database"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type payLine struct {
+	category      string
+	deductionType string
+	amount        float64
+}
+
+type paycheck struct {
+	day   time.Time
+	gross float64
+	net   float64
+	lines []payLine
+}
+
+type transaction struct {
+	day         time.Time
+	description string
+	category    string
+	amount      float64
+	tags        string
+}
+
+// payroll lists the paychecks in the span: twice a month, with a raise every
+// January. Both the paycheck breakdown and the deposits in the transaction
+// log come from here, so they agree.
+func (g *generator) payroll() []paycheck {
+	if g.paychecks != nil {
+		return g.paychecks
+	}
+
+	salary := math.Round(clamp(g.normal(95000, 15000), 45000, 250000)/1000) * 1000
+	g.paychecks = []paycheck{}
+
+	for month := monthStart(g.from); !month.After(g.to); month = month.AddDate(0, 1, 0) {
+		annual := salary * math.Pow(1.03, float64(month.Year()-g.from.Year()))
+		for _, day := range []time.Time{month.AddDate(0, 0, 14), monthEnd(month)} {
+			if day.Before(g.from) || day.After(g.to) {
+				continue
+			}
+
+			gross := round(annual/24, 2)
+			lines := []payLine{
+				{"Gross Pay", "Income", gross},
+				{"Federal Income Tax", "Tax", -round(gross*0.15, 2)},
+				{"State Income Tax", "Tax", -round(gross*0.05, 2)},
+				{"Social Security", "Tax", -round(gross*0.062, 2)},
+				{"Medicare", "Tax", -round(gross*0.0145, 2)},
+				{"401k Contribution", "Investment", -round(gross*0.06, 2)},
+				{"Health Insurance", "Benefit", -137.5},
+				{"Dental Insurance", "Benefit", -22.5},
+				{"Vision Insurance", "Benefit", -7.5},
+			}
+
+			net := 0.0
+			for _, line := range lines {
+				net += line.amount
+			}
+			g.paychecks = append(g.paychecks, paycheck{day: day, gross: gross, net: round(net, 2), lines: lines})
+		}
+	}
+
+	return g.paychecks
+}
+
+type expenseCategory struct {
+	name    string
+	weight  float64
+	median  float64
+	spread  float64
+	entries []expense
+}
+
+type expense struct {
+	description string
+	tags        string
+}
+
+var expenseCategories = []expenseCategory{
+	{"Food & Dining", 5, 24, 0.8, []expense{
+		{"Grocery shopping at Whole Foods", "groceries,food,essentials"},
+		{"Trader Joe's", "groceries,food"},
+		{"Coffee shop", "coffee,eating-out"},
+		{"Lunch with coworkers", "lunch,eating-out,work"},
+		{"Dinner out", "dinner,eating-out"},
+		{"Takeout - Thai", "takeout,eating-out"},
+		{"Farmers market", "groceries,local"},
+	}},
+	{"Transportation", 2, 28, 0.6, []expense{
+		{"Gas station fill-up", "gas,car,transportation"},
+		{"Uber ride", "rideshare,transportation"},
+		{"Parking", "parking,car"},
+		{"Transit pass reload", "transit,commute"},
+	}},
+	{"Shopping", 1.5, 42, 0.9, []expense{
+		{"Online shopping - clothing", "clothes,online,personal"},
+		{"Amazon order", "online,household"},
+		{"Hardware store", "home,tools"},
+		{"Bookstore", "books,personal"},
+	}},
+	{"Entertainment", 0.8, 30, 0.7, []expense{
+		{"Movie tickets", "movies,entertainment"},
+		{"Concert tickets", "music,entertainment"},
+		{"Video game", "games,entertainment"},
+		{"Museum admission", "culture,entertainment"},
+	}},
+	{"Health & Fitness", 0.5, 25, 0.7, []expense{
+		{"Pharmacy", "pharmacy,health"},
+		{"Doctor copay", "medical,health"},
+		{"Supplements", "supplements,health"},
+	}},
+	{"Personal Care", 0.4, 35, 0.5, []expense{
+		{"Haircut", "haircut,personal"},
+		{"Toiletries", "personal,essentials"},
+	}},
+	{"Gifts", 0.2, 55, 0.7, []expense{
+		{"Birthday gift", "gifts,friends"},
+		{"Flowers", "gifts,family"},
+	}},
+	{"Travel", 0.1, 280, 0.9, []expense{
+		{"Flight booking", "travel,flights"},
+		{"Hotel stay", "travel,lodging"},
+		{"Weekend getaway rental", "travel,lodging"},
+	}},
+}
+
+type recurringBill struct {
+	day         int
+	description string
+	category    string
+	tags        string
+	amount      func(g *generator, month time.Time) float64
+}
+
+var recurringBills = []recurringBill{
+	{1, "Rent payment - %s", "Housing", "rent,housing,fixed", func(g *generator, month time.Time) float64 {
+		return -math.Round(g.rent * math.Pow(1.03, float64(month.Year()-g.from.Year())))
+	}},
+	{1, "Gym membership - monthly", "Health & Fitness", "gym,health,fitness,subscription", func(*generator, time.Time) float64 { return -45 }},
+	{3, "Spotify subscription", "Entertainment", "music,entertainment,subscription", func(*generator, time.Time) float64 { return -10.99 }},
+	{5, "Electric bill - %s", "Utilities", "electricity,utilities,bills", func(g *generator, month time.Time) float64 {
+		// Higher in summer and winter.
+		season := math.Abs(math.Cos(2 * math.Pi * float64(month.Month()-1) / 12))
+		return -round(55+60*season+g.normal(0, 8), 2)
+	}},
+	{10, "Internet service", "Utilities", "internet,utilities,bills", func(*generator, time.Time) float64 { return -65 }},
+	{12, "Phone bill", "Utilities", "phone,utilities,bills", func(*generator, time.Time) float64 { return -45 }},
+	{20, "Netflix subscription", "Entertainment", "streaming,entertainment,subscription", func(*generator, time.Time) float64 { return -15.49 }},
+}
+
+func (g *generator) financialLogs(dataset database.DatasetConfig) {
+	employer := pick(g, companies)
+	g.rent = clamp(g.normal(1800, 350), 900, 4500)
+
+	var fixed []transaction
+	for _, check := range g.payroll() {
+		fixed = append(fixed, transaction{check.day, "Paycheck - " + employer, "Income", check.net, "salary,income,work"})
+	}
+	for month := monthStart(g.from); !month.After(g.to); month = month.AddDate(0, 1, 0) {
+		for _, bill := range recurringBills {
+			day := month.AddDate(0, 0, bill.day-1)
+			if day.Before(g.from) || day.After(g.to) {
+				continue
+			}
+			description := bill.description
+			if strings.Contains(description, "%s") {
+				description = fmt.Sprintf(description, month.Format("January"))
+			}
+			fixed = append(fixed, transaction{day, description, bill.category, bill.amount(g, month), bill.tags})
+		}
+	}
+
+	// With a small pinned count, everyday spending takes all of it.
+	if count, pinned := g.counts[dataset.ID]; !pinned || count >= len(fixed) {
+		for _, t := range fixed {
+			g.transaction(dataset.ID, t)
+		}
+	}
+
+	weights := make([]float64, len(expenseCategories))
+	for i, category := range expenseCategories {
+		weights[i] = category.weight
+	}
+
+	g.spread(dataset.ID, true, func(day time.Time) float64 {
+		if isWeekend(day) {
+			return 3
+		}
+		return 2
+	}, func(day time.Time, n int) {
+		for range n {
+			category := expenseCategories[pickWeighted(g, weights)]
+			entry := pick(g, category.entries)
+			amount := -round(math.Exp(g.normal(math.Log(category.median), category.spread)), 2)
+
+			t := transaction{day, entry.description, category.name, amount, entry.tags}
+			if g.chance(0.01) {
+				t.description = "Refund - " + entry.description
+				t.amount = -amount
+			}
+			g.transaction(dataset.ID, t)
+		}
+	})
+}
+
+func (g *generator) transaction(datasetID string, t transaction) {
+	g.transactions = append(g.transactions, t)
+	g.emit(datasetID, g.at(t.day), map[string]interface{}{
+		"date":        date(t.day),
+		"amount":      t.amount,
+		"description": t.description,
+		"category":    t.category,
+		"tags":        t.tags,
+	})
+}
+
+type account struct {
+	name    string
+	kind    string
+	owner   string
+	balance float64
+}
+
+func (g *generator) financialBalances(dataset database.DatasetConfig) {
+	checking := &account{name: "Chase Checking", kind: "Checking", owner: "Self", balance: round(g.normal(4000, 800), 2)}
+	savings := &account{name: "Ally Savings", kind: "Savings", owner: "Joint", balance: round(g.normal(15000, 5000), 2)}
+	brokerage := &account{name: "Vanguard Brokerage", kind: "Investment", owner: "Self", balance: round(g.normal(40000, 12000), 2)}
+	retirement := &account{name: "Fidelity 401k", kind: "Investment", owner: "Self", balance: round(g.normal(60000, 20000), 2)}
+	accounts := []*account{checking, savings, brokerage, retirement}
+
+	// Monthly cash flow comes from the transaction log when there is one.
+	flows := make(map[string]float64)
+	for _, t := range g.transactions {
+		flows[t.day.Format("2006-01")] += t.amount
+	}
+	contributions := make(map[string]float64)
+	for _, check := range g.payroll() {
+		contributions[check.day.Format("2006-01")] += check.gross * 0.06 * 1.5
+	}
+
+	snapshots := monthEnds(g.from, g.to)
+	if count, pinned := g.counts[dataset.ID]; pinned {
+		snapshots = evenlySpaced(g.from, g.to, int(math.Ceil(float64(count)/float64(len(accounts)))))
+	}
+
+	emitted := 0
+	previous := g.from
+	for _, day := range snapshots {
+		months := day.Sub(previous).Hours() / 24 / 30.4
+		previous = day
+
+		flow, ok := flows[day.Format("2006-01")]
+		if !ok || len(g.transactions) == 0 {
+			flow = g.normal(600, 400) * months
+		}
+
+		checking.balance += flow
+		if checking.balance > 6000 {
+			savings.balance += checking.balance - 4000
+			checking.balance = 4000 + g.normal(0, 300)
+		} else if checking.balance < 1000 && savings.balance > 2000 {
+			checking.balance += 2000
+			savings.balance -= 2000
+		}
+		savings.balance *= 1 + 0.0035*months
+		brokerage.balance = brokerage.balance*(1+g.normal(0.006*months, 0.04*math.Sqrt(months))) + 500*months
+		retirement.balance = retirement.balance*(1+g.normal(0.006*months, 0.04*math.Sqrt(months))) + contributions[day.Format("2006-01")]
+
+		for _, a := range accounts {
+			if count, pinned := g.counts[dataset.ID]; pinned && emitted >= count {
+				return
+			}
+			g.emit(dataset.ID, day.Add(23*time.Hour), map[string]interface{}{
+				"date":          date(day),
+				"amount":        round(a.balance, 2),
+				"account_name":  a.name,
+				"account_type":  a.kind,
+				"account_owner": a.owner,
+			})
+			emitted++
+		}
+	}
+}
+
+func (g *generator) paycheckInfo(dataset database.DatasetConfig) {
+	checks := g.payroll()
+	mean := g.perParent(dataset.ID, len(checks), 9)
+
+	for _, check := range checks {
+		n := min(g.occurrences(mean), len(check.lines))
+		for _, line := range check.lines[:n] {
+			g.emit(dataset.ID, check.day, map[string]interface{}{
+				"date":           date(check.day),
+				"amount":         line.amount,
+				"category":       line.category,
+				"deduction_type": line.deductionType,
+			})
+		}
+	}
+}
+
+// financialFiles attaches a CSV statement of each month's transactions. The
+// files go in as data URLs and are stored like any upload.
+func (g *generator) financialFiles(dataset database.DatasetConfig) {
+	months := monthEnds(g.from, g.to)
+	kinds := []string{"bank_statement", "credit_card_statement"}
+
+	n := g.fixed(dataset.ID, len(months))
+	for i := range n {
+		if len(months) == 0 {
+			return
+		}
+		day := months[i%len(months)]
+		kind := kinds[(i/len(months))%len(kinds)]
+		name := fmt.Sprintf("%s_%s.csv", kind, day.Format("2006_01"))
+
+		g.emit(dataset.ID, day.Add(23*time.Hour), map[string]interface{}{
+			"date": date(day),
+			"files": []map[string]interface{}{
+				{
+					"id":    g.newID(),
+					"src":   "data:text/csv;base64," + base64.StdEncoding.EncodeToString(g.statement(day)),
+					"name":  name,
+					"type":  "text/csv",
+					"order": 0,
+				},
+			},
+		})
+	}
+}
+
+func (g *generator) statement(month time.Time) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"date", "description", "category", "amount"})
+	for _, t := range g.transactions {
+		if t.day.Year() == month.Year() && t.day.Month() == month.Month() {
+			w.Write([]string{date(t.day), t.description, t.category, strconv.FormatFloat(t.amount, 'f', 2, 64)})
+		}
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func monthStart(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthEnd(month time.Time) time.Time {
+	return monthStart(month).AddDate(0, 1, -1)
+}
+
+// monthEnds lists the last day of each month in the span, and the last day of
+// the span when it ends mid-month.
+func monthEnds(from, to time.Time) []time.Time {
+	var days []time.Time
+	for month := monthStart(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		end := monthEnd(month)
+		if end.After(to) {
+			end = to
+		}
+		if !end.Before(from) {
+			days = append(days, end)
+		}
+	}
+	return days
+}
+
+func evenlySpaced(from, to time.Time, n int) []time.Time {
+	days := make([]time.Time, 0, n)
+	span := to.Sub(from)
+	for i := range n {
+		offset := span
+		if n > 1 {
+			offset = span * time.Duration(i) / time.Duration(n-1)
+		}
+		days = append(days, from.Add(offset).Truncate(24*time.Hour))
+	}
+	return days
+}
@@ -0,0 +1,480 @@
+package generator
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"myproject/backend/database"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultDays = 365
+	MaxDays     = 100 * 365
+
+	dateLayout = "2006-01-02"
+)
+
+// Options describe one generator run. At Scale 1 a year of data is roughly
+// what one person produces; Counts pins the number of rows for a dataset.
+type Options struct {
+	Seed     int64          `json:"seed"`
+	From     string         `json:"from,omitempty"`
+	To       string         `json:"to,omitempty"`
+	Days     int            `json:"days,omitempty"`
+	Scale    float64        `json:"scale,omitempty"`
+	Counts   map[string]int `json:"counts,omitempty"`
+	Datasets []string       `json:"datasets,omitempty"`
+}
+
+// Plan is the output of a run. Options are filled in so the same plan can
+// be produced again.
+type Plan struct {
+	Options Options               `json:"options"`
+	Records []database.DataRecord `json:"-"`
+	Counts  map[string]int        `json:"counts"`
+}
+
+type profile func(g *generator, dataset database.DatasetConfig)
+
+var profiles = map[string]profile{
+	database.DatasetIDDEXA:                (*generator).dexa,
+	database.DatasetIDBloodwork:           (*generator).bloodwork,
+	database.DatasetIDBloodMarker:         (*generator).bloodMarkers,
+	database.DatasetIDBloodResult:         (*generator).bloodResults,
+	database.DatasetIDBodyMeasurements:    (*generator).bodyMeasurements,
+	database.DatasetIDMetricCategory:      (*generator).metricCategories,
+	database.DatasetIDMetric:              (*generator).metricDefinitions,
+	database.DatasetIDExperiment:          (*generator).experimentRuns,
+	database.DatasetIDExperimentMetric:    (*generator).experimentMetrics,
+	database.DatasetIDDailyLog:            (*generator).dailyLogs,
+	database.DatasetIDGratitudeJournal:    (*generator).gratitudeJournal,
+	database.DatasetIDAffirmation:         (*generator).affirmations,
+	database.DatasetIDCreativityJournal:   (*generator).creativityJournal,
+	database.DatasetIDQuestionJournal:     (*generator).questionJournal,
+	database.DatasetIDTimeCategories:      (*generator).timeCategories,
+	database.DatasetIDTimeEntries:         (*generator).timeEntries,
+	database.DatasetIDTimePlannerConfig:   (*generator).timePlannerConfigs,
+	database.DatasetIDTodos:               (*generator).todos,
+	database.DatasetIDPeople:              (*generator).peopleRecords,
+	database.DatasetIDMeetings:            (*generator).meetings,
+	database.DatasetIDPersonAttributes:    (*generator).personAttributes,
+	database.DatasetIDPersonNotes:         (*generator).personNotes,
+	database.DatasetIDBirthdayReminders:   (*generator).birthdayReminders,
+	database.DatasetIDPersonRelationships: (*generator).personRelationships,
+	database.DatasetIDFinancialLogs:       (*generator).financialLogs,
+	database.DatasetIDFinancialBalances:   (*generator).financialBalances,
+	database.DatasetIDPaycheckInfo:        (*generator).paycheckInfo,
+	database.DatasetIDFinancialFiles:      (*generator).financialFiles,
+}
+
+// Some profiles read what others produced without a relation field between
+// them, so those run first.
+var dependencies = map[string][]string{
+	database.DatasetIDDailyLog:       {database.DatasetIDExperimentMetric},
+	database.DatasetIDFinancialFiles: {database.DatasetIDFinancialLogs},
+}
+
+type generator struct {
+	rng      *rand.Rand
+	from     time.Time
+	to       time.Time
+	days     int
+	scale    float64
+	counts   map[string]int
+	datasets map[string]database.DatasetConfig
+	included map[string]bool
+
+	records []database.DataRecord
+	ids     map[string][]string
+	err     error
+
+	weights      []float64
+	markers      []*markerState
+	bloodTests   []bloodTest
+	categories   []string
+	metrics      []*metric
+	activities   []*timeCategoryState
+	experiments  []*experiment
+	people       []*person
+	rent         float64
+	paychecks    []paycheck
+	transactions []transaction
+}
+
+func Generate(options Options) (Plan, error) {
+	definitions := database.GetAllDatasetDefinitions()
+
+	datasets := make(map[string]database.DatasetConfig)
+	for _, definition := range definitions {
+		datasets[definition.ID] = definition
+	}
+
+	err := options.normalize(datasets)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	from, _ := time.Parse(dateLayout, options.From)
+	to, _ := time.Parse(dateLayout, options.To)
+
+	included := selection(options.Datasets, datasets)
+
+	g := &generator{
+		rng:      rand.New(rand.NewPCG(uint64(options.Seed), 0x9E3779B97F4A7C15)),
+		from:     from,
+		to:       to,
+		days:     options.Days,
+		scale:    options.Scale,
+		counts:   options.Counts,
+		datasets: datasets,
+		included: included,
+		ids:      make(map[string][]string),
+	}
+	g.weights = g.bodyWeights()
+
+	for _, id := range order(definitions, included) {
+		run, ok := profiles[id]
+		if !ok {
+			run = (*generator).generic
+		}
+		run(g, datasets[id])
+	}
+	if g.err != nil {
+		return Plan{}, g.err
+	}
+
+	plan := Plan{Options: options, Records: g.records, Counts: make(map[string]int)}
+	for _, record := range g.records {
+		plan.Counts[record.DatasetID]++
+	}
+
+	return plan, nil
+}
+
+func (o *Options) normalize(datasets map[string]database.DatasetConfig) error {
+	if o.Seed == 0 {
+		o.Seed = time.Now().UnixNano()
+	}
+
+	if o.Scale == 0 {
+		o.Scale = 1
+	}
+	if o.Scale < 0 || math.IsNaN(o.Scale) || math.IsInf(o.Scale, 0) {
+		return fmt.Errorf("scale must be a positive number")
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if o.To != "" {
+		parsed, err := time.Parse(dateLayout, o.To)
+		if err != nil {
+			return fmt.Errorf("invalid end date '%s': %w", o.To, err)
+		}
+		to = parsed
+	}
+
+	var from time.Time
+	if o.From != "" {
+		parsed, err := time.Parse(dateLayout, o.From)
+		if err != nil {
+			return fmt.Errorf("invalid start date '%s': %w", o.From, err)
+		}
+		from = parsed
+	} else {
+		days := o.Days
+		if days == 0 {
+			days = DefaultDays
+		}
+		from = to.AddDate(0, 0, 1-days)
+	}
+
+	if from.After(to) {
+		return fmt.Errorf("start date %s is after end date %s", from.Format(dateLayout), to.Format(dateLayout))
+	}
+
+	o.From = from.Format(dateLayout)
+	o.To = to.Format(dateLayout)
+	o.Days = int(to.Sub(from).Hours()/24) + 1
+	if o.Days > MaxDays {
+		return fmt.Errorf("date span of %d days is longer than the maximum of %d", o.Days, MaxDays)
+	}
+
+	for id, count := range o.Counts {
+		if _, ok := datasets[id]; !ok {
+			return fmt.Errorf("unknown dataset '%s'", id)
+		}
+		if count < 0 {
+			return fmt.Errorf("count for '%s' cannot be negative", id)
+		}
+	}
+
+	for _, id := range o.Datasets {
+		if _, ok := datasets[id]; !ok {
+			return fmt.Errorf("unknown dataset '%s'", id)
+		}
+	}
+
+	return nil
+}
+
+// selection returns the requested datasets together with everything they
+// point at, so every generated relation resolves to a generated record.
+func selection(requested []string, datasets map[string]database.DatasetConfig) map[string]bool {
+	selected := make(map[string]bool)
+	if len(requested) == 0 {
+		for id := range datasets {
+			selected[id] = true
+		}
+		return selected
+	}
+
+	pending := slices.Clone(requested)
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if selected[id] {
+			continue
+		}
+		selected[id] = true
+
+		pending = append(pending, dependencies[id]...)
+		for _, field := range datasets[id].Fields {
+			if field.IsRelation && field.RelatedDataset != "" {
+				pending = append(pending, field.RelatedDataset)
+			}
+		}
+	}
+
+	return selected
+}
+
+// order sorts the selected datasets so parents come before children, keeping
+// the definition order otherwise.
+func order(definitions []database.DatasetConfig, selected map[string]bool) []string {
+	var ordered []string
+	visited := make(map[string]bool)
+
+	var visit func(id string)
+	visit = func(id string) {
+		if visited[id] || !selected[id] {
+			return
+		}
+		visited[id] = true
+
+		for _, definition := range definitions {
+			if definition.ID != id {
+				continue
+			}
+			for _, field := range definition.Fields {
+				if field.IsRelation && field.RelatedDataset != id {
+					visit(field.RelatedDataset)
+				}
+			}
+		}
+		for _, dependency := range dependencies[id] {
+			visit(dependency)
+		}
+
+		ordered = append(ordered, id)
+	}
+
+	for _, definition := range definitions {
+		visit(definition.ID)
+	}
+
+	return ordered
+}
+
+// emit adds a record and returns its ID. The first record that cannot be
+// encoded fails the run; Generate returns its error.
+func (g *generator) emit(datasetID string, at time.Time, data map[string]interface{}) string {
+	id := g.newID()
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		if g.err == nil {
+			g.err = fmt.Errorf("generator produced unencodable %s record: %w", datasetID, err)
+		}
+		return id
+	}
+
+	g.records = append(g.records, database.DataRecord{
+		ID:           id,
+		DatasetID:    datasetID,
+		Data:         encoded,
+		CreatedAt:    at,
+		LastModified: at,
+	})
+	g.ids[datasetID] = append(g.ids[datasetID], id)
+
+	return id
+}
+
+// newID returns a version 4 UUID drawn from the seeded source, so the same
+// seed reproduces relations between records too.
+func (g *generator) newID() string {
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[:8], g.rng.Uint64())
+	binary.BigEndian.PutUint64(id[8:], g.rng.Uint64())
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return id.String()
+}
+
+// entities returns how many rows a dataset of things (not events) gets.
+func (g *generator) entities(datasetID string, base int) int {
+	if count, ok := g.counts[datasetID]; ok {
+		return count
+	}
+	return max(1, int(math.Round(float64(base)*g.scale)))
+}
+
+// fixed is like entities for lookup lists, which do not grow with scale.
+func (g *generator) fixed(datasetID string, base int) int {
+	if count, ok := g.counts[datasetID]; ok {
+		return count
+	}
+	return base
+}
+
+// perParent returns the mean number of child rows for each of parents.
+func (g *generator) perParent(datasetID string, parents int, mean float64) float64 {
+	if count, ok := g.counts[datasetID]; ok {
+		if parents == 0 {
+			return 0
+		}
+		return float64(count) / float64(parents)
+	}
+	return mean
+}
+
+// spread distributes events over the span. weight is the expected number of
+// events on a day at Scale 1. A pinned count is met exactly, less whatever
+// the dataset already holds; otherwise each day draws around its weight.
+func (g *generator) spread(datasetID string, scaled bool, weight func(day time.Time) float64, emit func(day time.Time, n int)) {
+	days := make([]time.Time, 0, g.days)
+	weights := make([]float64, 0, g.days)
+	total := 0.0
+	for day := g.from; !day.After(g.to); day = day.AddDate(0, 0, 1) {
+		w := math.Max(weight(day), 0)
+		days = append(days, day)
+		weights = append(weights, w)
+		total += w
+	}
+
+	count, pinned := g.counts[datasetID]
+	if !pinned {
+		factor := 1.0
+		if scaled {
+			factor = g.scale
+		}
+		for i, day := range days {
+			if n := g.occurrences(weights[i] * factor); n > 0 {
+				emit(day, n)
+			}
+		}
+		return
+	}
+
+	target := count - len(g.ids[datasetID])
+	if target <= 0 || total == 0 {
+		return
+	}
+
+	cumulative, emitted := 0.0, 0
+	for i, day := range days {
+		cumulative += weights[i]
+		n := int(math.Floor(cumulative/total*float64(target)+g.rng.Float64())) - emitted
+		if i == len(days)-1 {
+			n = target - emitted
+		}
+		if n > 0 {
+			emit(day, n)
+			emitted += n
+		}
+	}
+}
+
+// occurrences draws a whole number of events with the given mean.
+func (g *generator) occurrences(mean float64) int {
+	n := int(mean)
+	if g.rng.Float64() < mean-float64(n) {
+		n++
+	}
+	return n
+}
+
+func (g *generator) chance(p float64) bool {
+	return g.rng.Float64() < p
+}
+
+func (g *generator) between(low, high float64) float64 {
+	return low + g.rng.Float64()*(high-low)
+}
+
+func (g *generator) intBetween(low, high int) int {
+	return low + g.rng.IntN(high-low+1)
+}
+
+func (g *generator) normal(mean, stddev float64) float64 {
+	return mean + g.rng.NormFloat64()*stddev
+}
+
+func (g *generator) dayIndex(day time.Time) int {
+	return int(day.Sub(g.from).Hours() / 24)
+}
+
+// dayBetween returns a random day in [from, to].
+func (g *generator) dayBetween(from, to time.Time) time.Time {
+	if !to.After(from) {
+		return from
+	}
+	return from.AddDate(0, 0, g.rng.IntN(int(to.Sub(from).Hours()/24)+1))
+}
+
+// at places an event on day at a random time within working hours.
+func (g *generator) at(day time.Time) time.Time {
+	return day.Add(time.Duration(g.intBetween(7*60, 22*60)) * time.Minute)
+}
+
+func pick[T any](g *generator, values []T) T {
+	return values[g.rng.IntN(len(values))]
+}
+
+// pickSome returns n distinct values in a random order.
+func pickSome[T any](g *generator, values []T, n int) []T {
+	n = min(n, len(values))
+	picked := make([]T, 0, n)
+	for _, i := range g.rng.Perm(len(values))[:n] {
+		picked = append(picked, values[i])
+	}
+	return picked
+}
+
+func round(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
+
+func clamp(value, low, high float64) float64 {
+	return math.Max(low, math.Min(high, value))
+}
+
+func date(day time.Time) string {
+	return day.Format(dateLayout)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"myproject/backend/database"
+	"strings"
+	"time"
+)
+
+// generic fills datasets without a profile from their field definitions, so
+// a newly added dataset still gets data. Datasets with a date field get
+// about one record a week; others get a fixed set of rows.
+func (g *generator) generic(dataset database.DatasetConfig) {
+	for _, field := range dataset.Fields {
+		if field.IsRelation && !field.IsOptional && len(g.ids[field.RelatedDataset]) == 0 {
+			return
+		}
+	}
+
+	record := func(day time.Time) {
+		data := make(map[string]interface{})
+		for _, field := range dataset.Fields {
+			if field.IsOptional && g.chance(0.3) {
+				continue
+			}
+			if value, ok := g.genericValue(field, day); ok {
+				data[field.Key] = value
+			}
+		}
+		g.emit(dataset.ID, g.at(day), data)
+	}
+
+	hasDate := false
+	for _, field := range dataset.Fields {
+		hasDate = hasDate || field.Type == database.FieldTypeDate
+	}
+
+	if !hasDate {
+		for range g.entities(dataset.ID, 10) {
+			record(g.from)
+		}
+		return
+	}
+
+	g.spread(dataset.ID, true, func(time.Time) float64 { return 1.0 / 7 }, func(day time.Time, n int) {
+		for range n {
+			record(day)
+		}
+	})
+}
+
+func (g *generator) genericValue(field database.FieldDefinition, day time.Time) (interface{}, bool) {
+	if field.IsRelation {
+		parents := g.ids[field.RelatedDataset]
+		if len(parents) == 0 {
+			return nil, false
+		}
+		return pick(g, parents), true
+	}
+
+	switch field.Type {
+	case database.FieldTypeDate:
+		return date(day), true
+	case database.FieldTypeBoolean:
+		return g.chance(0.5), true
+	case database.FieldTypeNumber:
+		return round(g.between(0, 100), 1), true
+	case database.FieldTypePercentage:
+		return round(g.between(0, 100), 1), true
+	case database.FieldTypeText:
+		return strings.Join(pickSome(g, fillerWords, g.intBetween(2, 5)), " "), true
+	case database.FieldTypeTags:
+		return database.JoinTags(pickSome(g, fillerWords, g.intBetween(1, 3))), true
+	case database.FieldTypeMarkdown:
+		return strings.Join(pickSome(g, reflectionAnswers, 1), ""), true
+	case database.FieldTypeJSON:
+		return map[string]interface{}{}, true
+	}
+
+	// Attachments cannot be made up for a field nothing is known about.
+	return nil, false
+}
@@ -0,0 +1,672 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"myproject/backend/database"
+	"strconv"
+	"time"
+)
+
+// bodyWeights is the weight the person has on each day of the span, shared
+// by the weight metric, body measurements and DEXA scans.
+func (g *generator) bodyWeights() []float64 {
+	base := clamp(g.normal(176, 14), 130, 240)
+	phase := g.between(0, 2*math.Pi)
+
+	weights := make([]float64, g.days)
+	drift := 0.0
+	for i := range weights {
+		// A slow mean-reverting drift on top of a seasonal swing.
+		drift = drift*0.998 + g.normal(0, 0.12)
+		seasonal := 2.5 * math.Sin(2*math.Pi*float64(g.from.AddDate(0, 0, i).YearDay())/365+phase)
+		weights[i] = base + drift + seasonal
+	}
+
+	return weights
+}
+
+func (g *generator) weightOn(day time.Time) float64 {
+	i := max(0, min(g.dayIndex(day), len(g.weights)-1))
+	return g.weights[i]
+}
+
+// bodyFat follows weight: most of what is gained or lost is fat.
+func (g *generator) bodyFat(weight float64) float64 {
+	return clamp(18+(weight-g.weights[0])*0.45, 6, 40)
+}
+
+type dexaRegion struct {
+	prefix    string
+	massShare float64
+	fatOffset float64
+	boneShare float64
+}
+
+var dexaRegions = []dexaRegion{
+	{"arms", 0.12, -2, 0.09},
+	{"legs", 0.35, 0, 0.36},
+	{"trunk", 0.47, 3, 0.30},
+	{"android", 0.07, 6, 0.015},
+	{"gynoid", 0.16, 2, 0.06},
+	{"right_arm", 0.06, -2, 0.045},
+	{"left_arm", 0.06, -2, 0.045},
+	{"right_leg", 0.175, 0, 0.18},
+	{"left_leg", 0.175, 0, 0.18},
+}
+
+var boneDensities = map[string]float64{
+	"head":   2.25,
+	"arms":   0.86,
+	"legs":   1.32,
+	"trunk":  1.01,
+	"ribs":   0.74,
+	"spine":  1.12,
+	"pelvis": 1.16,
+	"total":  1.21,
+}
+
+func (g *generator) dexa(dataset database.DatasetConfig) {
+	bone := g.normal(6.3, 0.4)
+
+	g.spread(dataset.ID, true, func(time.Time) float64 { return 1.0 / 180 }, func(day time.Time, n int) {
+		for range n {
+			mass := g.weightOn(day) + g.normal(0, 0.5)
+			fat := g.bodyFat(mass) + g.normal(0, 0.4)
+			fatTissue := mass * fat / 100
+
+			data := map[string]interface{}{
+				"date":                      date(day),
+				"fasted":                    g.chance(0.85),
+				"total_body_fat_percentage": round(fat, 1),
+				"fat_tissue_lbs":            round(fatTissue, 1),
+				"lean_tissue_lbs":           round(mass-fatTissue-bone, 1),
+				"total_mass_lbs":            round(mass, 1),
+				"bone_mineral_content":      round(bone, 2),
+			}
+
+			for _, region := range dexaRegions {
+				regionMass := mass * region.massShare * g.normal(1, 0.01)
+				regionFat := clamp(fat+region.fatOffset+g.normal(0, 0.5), 3, 55)
+				regionBone := bone * region.boneShare
+				regionFatTissue := regionMass * regionFat / 100
+
+				data[region.prefix+"_total_region_fat_percentage"] = round(regionFat, 1)
+				data[region.prefix+"_total_mass_lbs"] = round(regionMass, 1)
+				data[region.prefix+"_fat_tissue_lbs"] = round(regionFatTissue, 1)
+				data[region.prefix+"_lean_tissue_lbs"] = round(regionMass-regionFatTissue-regionBone, 1)
+				data[region.prefix+"_bone_mineral_content"] = round(regionBone, 2)
+			}
+
+			android := data["android_total_region_fat_percentage"].(float64)
+			gynoid := data["gynoid_total_region_fat_percentage"].(float64)
+			leanKg := (mass - fatTissue) * 0.4536
+			vat := clamp(0.5+(fat-12)*0.12+g.normal(0, 0.1), 0.1, 6)
+
+			data["android"] = android
+			data["gynoid"] = gynoid
+			data["a_g_ratio"] = round(android/gynoid, 2)
+			data["resting_metabolic_rate"] = math.Round(370 + 21.6*leanKg)
+			data["vat_mass_lbs"] = round(vat, 2)
+			data["vat_volume_in3"] = round(vat*38.5, 1)
+			for _, site := range sortedKeys(boneDensities) {
+				data["bone_density_g_cm2_"+site] = round(boneDensities[site]*g.normal(1, 0.015), 3)
+			}
+
+			g.emit(dataset.ID, g.at(day), data)
+		}
+	})
+}
+
+type bloodMarker struct {
+	name        string
+	unit        string
+	category    string
+	description string
+	low, high   float64
+	optimalLow  float64
+	optimalHigh float64
+	typical     float64
+	spread      float64
+	decimals    int
+	general     string
+	values      []string
+}
+
+var bloodMarkerTemplates = []bloodMarker{
+	{name: "Total Cholesterol", unit: "mg/dL", category: "Lipid Panel", description: "Total cholesterol levels in blood", low: 125, high: 200, optimalLow: 150, optimalHigh: 180, typical: 178, spread: 18},
+	{name: "HDL Cholesterol", unit: "mg/dL", category: "Lipid Panel", description: "High-density lipoprotein, the protective cholesterol", low: 40, high: 100, optimalLow: 60, optimalHigh: 90, typical: 58, spread: 8},
+	{name: "LDL Cholesterol", unit: "mg/dL", category: "Lipid Panel", description: "Low-density lipoprotein cholesterol", low: 0, high: 100, optimalLow: 50, optimalHigh: 90, typical: 102, spread: 15},
+	{name: "Triglycerides", unit: "mg/dL", category: "Lipid Panel", description: "Fat in the blood used for energy", low: 0, high: 150, optimalLow: 40, optimalHigh: 80, typical: 95, spread: 25},
+	{name: "Fasting Glucose", unit: "mg/dL", category: "Metabolic", description: "Blood sugar after an overnight fast", low: 65, high: 99, optimalLow: 75, optimalHigh: 90, typical: 88, spread: 6},
+	{name: "HbA1c", unit: "%", category: "Metabolic", description: "Average blood sugar over the past three months", low: 4, high: 5.6, optimalLow: 4.6, optimalHigh: 5.3, typical: 5.3, spread: 0.2, decimals: 1},
+	{name: "Fasting Insulin", unit: "uIU/mL", category: "Metabolic", description: "Insulin level after an overnight fast", low: 2.6, high: 24.9, optimalLow: 2, optimalHigh: 6, typical: 6, spread: 2, decimals: 1},
+	{name: "TSH", unit: "mIU/L", category: "Thyroid", description: "Thyroid stimulating hormone", low: 0.45, high: 4.5, optimalLow: 1, optimalHigh: 2.5, typical: 1.9, spread: 0.6, decimals: 2},
+	{name: "Free T4", unit: "ng/dL", category: "Thyroid", description: "Unbound thyroxine", low: 0.82, high: 1.77, optimalLow: 1, optimalHigh: 1.5, typical: 1.2, spread: 0.15, decimals: 2},
+	{name: "Vitamin D", unit: "ng/mL", category: "Vitamins", description: "25-hydroxy vitamin D", low: 30, high: 100, optimalLow: 50, optimalHigh: 80, typical: 42, spread: 10},
+	{name: "Vitamin B12", unit: "pg/mL", category: "Vitamins", description: "Cobalamin level", low: 232, high: 1245, optimalLow: 500, optimalHigh: 1000, typical: 560, spread: 120},
+	{name: "Ferritin", unit: "ng/mL", category: "Iron", description: "Stored iron", low: 30, high: 400, optimalLow: 50, optimalHigh: 150, typical: 120, spread: 35},
+	{name: "hs-CRP", unit: "mg/L", category: "Inflammation", description: "High-sensitivity C-reactive protein", low: 0, high: 3, optimalLow: 0, optimalHigh: 1, typical: 0.9, spread: 0.5, decimals: 1},
+	{name: "Total Testosterone", unit: "ng/dL", category: "Hormones", description: "Total testosterone", low: 264, high: 916, optimalLow: 500, optimalHigh: 900, typical: 560, spread: 90},
+	{name: "ALT", unit: "IU/L", category: "Liver", description: "Alanine aminotransferase", low: 0, high: 44, optimalLow: 0, optimalHigh: 25, typical: 24, spread: 7},
+	{name: "Creatinine", unit: "mg/dL", category: "Kidney", description: "Waste product filtered by the kidneys", low: 0.76, high: 1.27, optimalLow: 0.8, optimalHigh: 1.1, typical: 0.98, spread: 0.1, decimals: 2},
+	{name: "Urine Appearance", unit: "n/a", category: "Urinalysis", description: "Visual clarity of the urine sample", general: "Clear", values: []string{"Clear", "Clear", "Clear", "Slightly cloudy"}},
+}
+
+type markerState struct {
+	id       string
+	template bloodMarker
+	baseline float64
+}
+
+type bloodTest struct {
+	id     string
+	day    time.Time
+	fasted bool
+}
+
+func (g *generator) bloodMarkers(dataset database.DatasetConfig) {
+	n := g.fixed(dataset.ID, len(bloodMarkerTemplates))
+	for i := range n {
+		template := bloodMarkerTemplates[i%len(bloodMarkerTemplates)]
+		name := template.name
+		if i >= len(bloodMarkerTemplates) {
+			name = fmt.Sprintf("%s (%d)", name, i/len(bloodMarkerTemplates)+1)
+		}
+
+		data := map[string]interface{}{
+			"name":        name,
+			"unit":        template.unit,
+			"description": template.description,
+			"category":    template.category,
+		}
+		if template.values != nil {
+			data["general_reference"] = template.general
+			data["optimal_general"] = template.general
+		} else {
+			data["lower_reference"] = template.low
+			data["upper_reference"] = template.high
+			data["optimal_low"] = template.optimalLow
+			data["optimal_high"] = template.optimalHigh
+		}
+
+		id := g.emit(dataset.ID, g.from, data)
+		g.markers = append(g.markers, &markerState{
+			id:       id,
+			template: template,
+			baseline: g.normal(template.typical, template.spread*0.6),
+		})
+	}
+}
+
+func (g *generator) bloodwork(dataset database.DatasetConfig) {
+	labs := []string{"Quest Diagnostics", "LabCorp", "City Hospital Lab", "Function Health"}
+	lab := pick(g, labs)
+
+	g.spread(dataset.ID, true, func(time.Time) float64 { return 1.0 / 120 }, func(day time.Time, n int) {
+		for range n {
+			if g.chance(0.15) {
+				lab = pick(g, labs)
+			}
+
+			fasted := g.chance(0.85)
+			data := map[string]interface{}{
+				"date":     date(day),
+				"fasted":   fasted,
+				"lab_name": lab,
+			}
+			if g.chance(0.3) {
+				data["notes"] = pick(g, bloodworkNotes)
+			}
+
+			id := g.emit(dataset.ID, day.Add(time.Duration(g.intBetween(7*60, 10*60))*time.Minute), data)
+			g.bloodTests = append(g.bloodTests, bloodTest{id: id, day: day, fasted: fasted})
+		}
+	})
+}
+
+func (g *generator) bloodResults(dataset database.DatasetConfig) {
+	if len(g.markers) == 0 {
+		return
+	}
+
+	mean := g.perParent(dataset.ID, len(g.bloodTests), float64(len(g.markers))*0.9)
+	for _, test := range g.bloodTests {
+		for _, marker := range pickSome(g, g.markers, g.occurrences(mean)) {
+			template := marker.template
+			data := map[string]interface{}{
+				"blood_test_id":   test.id,
+				"blood_marker_id": marker.id,
+			}
+
+			if template.values != nil {
+				data["value_text"] = pick(g, template.values)
+			} else {
+				value := g.normal(marker.baseline, template.spread*0.5)
+				if !test.fasted && template.category == "Metabolic" {
+					value *= g.between(1.05, 1.3)
+				}
+				value = round(math.Max(value, template.typical*0.05), template.decimals)
+				data["value_number"] = value
+
+				switch {
+				case value > template.high:
+					data["notes"] = "Above reference range"
+				case value < template.low:
+					data["notes"] = "Below reference range"
+				}
+			}
+
+			g.emit(dataset.ID, test.day, data)
+		}
+	}
+}
+
+type bodyMeasurement struct {
+	name   string
+	unit   string
+	weight float64
+	value  func(g *generator, day time.Time) float64
+}
+
+var bodyMeasurementTypes = []bodyMeasurement{
+	{"Weight", "lbs", 0.7, func(g *generator, day time.Time) float64 {
+		return round(g.weightOn(day)+g.normal(0, 0.4), 1)
+	}},
+	{"Waist", "in", 1.0 / 7, func(g *generator, day time.Time) float64 {
+		return round(33+(g.weightOn(day)-g.weights[0])*0.12+g.normal(0, 0.2), 1)
+	}},
+	{"Resting Heart Rate", "bpm", 0.3, func(g *generator, day time.Time) float64 {
+		return math.Round(g.normal(60, 3.5))
+	}},
+	{"Body Fat", "%", 1.0 / 30, func(g *generator, day time.Time) float64 {
+		return round(g.bodyFat(g.weightOn(day))+g.normal(0, 1), 1)
+	}},
+	{"Blood Pressure Systolic", "mmHg", 1.0 / 14, func(g *generator, day time.Time) float64 {
+		return math.Round(g.normal(118, 7))
+	}},
+}
+
+func (g *generator) bodyMeasurements(dataset database.DatasetConfig) {
+	weights := make([]float64, len(bodyMeasurementTypes))
+	total := 0.0
+	for i, measurement := range bodyMeasurementTypes {
+		weights[i] = measurement.weight
+		total += measurement.weight
+	}
+
+	g.spread(dataset.ID, true, func(time.Time) float64 { return total }, func(day time.Time, n int) {
+		for range n {
+			measurement := bodyMeasurementTypes[pickWeighted(g, weights)]
+			taken := day.Add(time.Duration(g.intBetween(6*60+30, 8*60+30)) * time.Minute)
+
+			data := map[string]interface{}{
+				"date":        date(day),
+				"time":        taken.Format("15:04"),
+				"measurement": measurement.name,
+				"value":       measurement.value(g, day),
+				"unit":        measurement.unit,
+			}
+			if g.chance(0.05) {
+				data["private"] = true
+			}
+
+			g.emit(dataset.ID, taken, data)
+		}
+	})
+}
+
+type metricTemplate struct {
+	name        string
+	description string
+	kind        string
+	unit        string
+	category    string
+	base        float64
+	noise       float64
+	low, high   float64
+	decimals    int
+	zeroChance  float64
+	weekend     float64
+	goalType    string
+	goalValue   float64
+}
+
+var metricTemplates = []metricTemplate{
+	{name: "Energy Level", description: "Daily energy level on a scale of 1-10", kind: "scale", unit: "/10", category: "Wellbeing", base: 6.5, noise: 1.3, low: 1, high: 10, weekend: 0.4, goalType: "target", goalValue: 8},
+	{name: "Mood", description: "Overall mood for the day", kind: "scale", unit: "/10", category: "Wellbeing", base: 7, noise: 1.4, low: 1, high: 10, weekend: 0.6},
+	{name: "Sleep Hours", description: "Hours of sleep last night", kind: "number", unit: "hours", category: "Sleep", base: 7.1, noise: 0.8, low: 3, high: 11, decimals: 1, weekend: 0.6, goalType: "minimum", goalValue: 7},
+	{name: "Exercise Minutes", description: "Minutes of exercise completed", kind: "number", unit: "minutes", category: "Fitness", base: 38, noise: 18, low: 0, high: 180, zeroChance: 0.3, weekend: 10, goalType: "minimum", goalValue: 30},
+	{name: "Weight", description: "Daily weight measurement", kind: "number", unit: "lbs", category: "Body", decimals: 1},
+	{name: "Steps", description: "Steps walked during the day", kind: "number", unit: "steps", category: "Fitness", base: 8200, noise: 2600, low: 400, high: 30000, weekend: 1500, goalType: "minimum", goalValue: 10000},
+	{name: "Water Intake", description: "Glasses of water", kind: "number", unit: "glasses", category: "Nutrition", base: 7, noise: 2, low: 0, high: 16},
+	{name: "Meditated", description: "Did I meditate today?", kind: "boolean", category: "Mindfulness", base: 0.55, weekend: 0.1, goalType: "target", goalValue: 1},
+	{name: "Caffeine", description: "Caffeine consumed", kind: "number", unit: "mg", category: "Nutrition", base: 190, noise: 70, low: 0, high: 600, weekend: -40, goalType: "maximum", goalValue: 300},
+	{name: "Screen Time", description: "Hours of recreational screen time", kind: "number", unit: "hours", category: "Mindfulness", base: 3.6, noise: 1.2, low: 0, high: 12, decimals: 1, weekend: 1.2, goalType: "maximum", goalValue: 3},
+}
+
+var metricCategoryNames = []string{"Wellbeing", "Sleep", "Fitness", "Body", "Nutrition", "Mindfulness"}
+
+type metric struct {
+	id       string
+	template metricTemplate
+	start    time.Time
+	end      time.Time
+}
+
+func (m *metric) activeOn(day time.Time) bool {
+	return !day.Before(m.start) && (m.end.IsZero() || !day.After(m.end))
+}
+
+func (g *generator) metricCategories(dataset database.DatasetConfig) {
+	n := g.fixed(dataset.ID, len(metricCategoryNames))
+	for i := range n {
+		name := metricCategoryNames[i%len(metricCategoryNames)]
+		if i >= len(metricCategoryNames) {
+			name = fmt.Sprintf("%s (%d)", name, i/len(metricCategoryNames)+1)
+		}
+		g.categories = append(g.categories, g.emit(dataset.ID, g.from, map[string]interface{}{"name": name}))
+	}
+}
+
+func (g *generator) metricDefinitions(dataset database.DatasetConfig) {
+	n := g.entities(dataset.ID, 8)
+	if logs, ok := g.counts[database.DatasetIDDailyLog]; ok {
+		if _, pinned := g.counts[dataset.ID]; !pinned {
+			// Enough metrics that the pinned number of logs fits the span.
+			n = max(n, int(math.Ceil(float64(logs)/float64(g.days)/0.8)))
+		}
+	}
+
+	for i := range n {
+		template := metricTemplates[i%len(metricTemplates)]
+		if i >= len(metricTemplates) {
+			template.name = fmt.Sprintf("%s (%d)", template.name, i/len(metricTemplates)+1)
+		}
+
+		m := &metric{template: template, start: g.from}
+		if i >= 3 && g.chance(0.3) {
+			m.start = g.dayBetween(g.from, g.from.AddDate(0, 0, g.days/2))
+		}
+		active := !g.chance(0.1)
+		if !active {
+			m.end = g.dayBetween(m.start, g.to)
+		}
+
+		data := map[string]interface{}{
+			"name":                template.name,
+			"description":         template.description,
+			"type":                template.kind,
+			"active":              active,
+			"private":             g.chance(0.1),
+			"schedule_start_date": date(m.start),
+		}
+		if template.unit != "" {
+			data["unit"] = template.unit
+		}
+		switch template.kind {
+		case "boolean":
+			data["default_value"] = "false"
+		case "scale":
+			data["default_value"] = "5"
+		}
+		if !m.end.IsZero() {
+			data["schedule_end_date"] = date(m.end)
+		}
+		if template.goalType != "" {
+			data["goal_type"] = template.goalType
+			data["goal_value"] = template.goalValue
+		}
+		if len(g.categories) > 0 {
+			data["category_id"] = g.categories[categoryIndex(template.category)%len(g.categories)]
+		}
+
+		m.id = g.emit(dataset.ID, m.start, data)
+		g.metrics = append(g.metrics, m)
+	}
+}
+
+func categoryIndex(name string) int {
+	for i, category := range metricCategoryNames {
+		if category == name {
+			return i
+		}
+	}
+	return 0
+}
+
+type experimentTemplate struct {
+	name        string
+	description string
+	goal        string
+	startState  string
+	endState    string
+	effects     map[string]float64
+}
+
+var experimentTemplates = []experimentTemplate{
+	{"No Caffeine After Noon", "Cutting off coffee and tea at noon to see if sleep improves", "Fall asleep faster and wake up rested", "Drinking coffee until mid-afternoon, often awake past midnight", "Falling asleep within 20 minutes most nights", map[string]float64{"Caffeine": -80, "Sleep Hours": 0.5, "Energy Level": 0.6}},
+	{"Intermittent Fasting 16:8", "Eating only between noon and 8pm", "Lose 5 pounds and improve mental clarity", "Snacking throughout the day", "Comfortable with the eating window, less afternoon slump", map[string]float64{"Weight": -3, "Energy Level": 0.4}},
+	{"Morning Meditation", "Ten minutes of meditation before looking at my phone", "Meditate every morning for the whole experiment", "Meditating occasionally", "Meditation is part of the morning routine", map[string]float64{"Meditated": 0.35, "Mood": 0.6, "Screen Time": -0.5}},
+	{"10k Steps a Day", "Walking at lunch and after dinner to hit 10,000 steps", "Average 10,000 steps per day", "Mostly sitting at a desk", "Walking has become a habit", map[string]float64{"Steps": 2500, "Exercise Minutes": 12, "Mood": 0.3}},
+	{"Screens Off at 10pm", "No phone or laptop after 10pm", "Better sleep and less doomscrolling", "Scrolling in bed most nights", "Reading before bed instead of scrolling", map[string]float64{"Screen Time": -1.2, "Sleep Hours": 0.4}},
+	{"Hydration Challenge", "Carrying a water bottle everywhere", "Drink 10 glasses of water a day", "Often forgetting to drink water", "Drinking water without thinking about it", map[string]float64{"Water Intake": 3, "Energy Level": 0.3}},
+	{"Strength Training 3x Week", "Lifting on Monday, Wednesday and Friday", "Get stronger and more consistent", "Irregular workouts", "Three sessions a week for the whole block", map[string]float64{"Exercise Minutes": 15, "Weight": 1, "Energy Level": 0.3}},
+}
+
+type experiment struct {
+	id      string
+	start   time.Time
+	end     time.Time
+	effects map[string]float64
+}
+
+func (g *generator) experimentRuns(dataset database.DatasetConfig) {
+	n := g.entities(dataset.ID, max(1, g.days/75))
+	for i := range n {
+		template := experimentTemplates[i%len(experimentTemplates)]
+		if i >= len(experimentTemplates) {
+			template.name = fmt.Sprintf("%s (round %d)", template.name, i/len(experimentTemplates)+1)
+		}
+
+		start := g.dayBetween(g.from, g.to.AddDate(0, 0, -14))
+		if start.Before(g.from) {
+			start = g.from
+		}
+		end := start.AddDate(0, 0, g.intBetween(21, 90))
+
+		data := map[string]interface{}{
+			"name":        template.name,
+			"description": template.description,
+			"goal":        template.goal,
+			"start_state": template.startState,
+			"start_date":  date(start),
+			"end_date":    date(end),
+			"status":      "active",
+			"private":     g.chance(0.1),
+		}
+		if end.Before(g.to) {
+			data["status"] = "completed"
+			data["end_state"] = template.endState
+		}
+
+		id := g.emit(dataset.ID, start, data)
+		g.experiments = append(g.experiments, &experiment{id: id, start: start, end: end, effects: template.effects})
+	}
+}
+
+func (g *generator) experimentMetrics(dataset database.DatasetConfig) {
+	if len(g.metrics) == 0 {
+		return
+	}
+
+	byName := make(map[string]*metric)
+	for _, m := range g.metrics {
+		if _, ok := byName[m.template.name]; !ok {
+			byName[m.template.name] = m
+		}
+	}
+
+	importance := []string{"high", "medium", "low"}
+	for _, exp := range g.experiments {
+		effects := exp.effects
+		exp.effects = make(map[string]float64)
+
+		var tracked []*metric
+		for _, name := range sortedKeys(effects) {
+			if m, ok := byName[name]; ok {
+				tracked = append(tracked, m)
+			}
+		}
+
+		n := g.occurrences(g.perParent(dataset.ID, len(g.experiments), float64(len(tracked))))
+		if n > len(tracked) {
+			tracked = append(tracked, pickSome(g, g.metrics, n-len(tracked))...)
+		}
+
+		seen := make(map[string]bool)
+		for i, m := range tracked {
+			if i >= n || seen[m.id] {
+				continue
+			}
+			seen[m.id] = true
+
+			delta := effects[m.template.name]
+			exp.effects[m.id] = delta
+
+			data := map[string]interface{}{
+				"experiment_id": exp.id,
+				"metric_id":     m.id,
+				"importance":    importance[min(i, len(importance)-1)],
+				"private":       false,
+			}
+			switch {
+			case delta > 0:
+				data["target_type"] = "increase"
+			case delta < 0:
+				data["target_type"] = "decrease"
+			default:
+				data["target_type"] = "maintain"
+			}
+			if m.template.kind != "boolean" {
+				base := m.template.base
+				if m.template.unit == "lbs" {
+					base = g.weightOn(exp.start)
+				}
+				data["target"] = round(base+delta, m.template.decimals)
+			}
+
+			g.emit(dataset.ID, exp.start, data)
+		}
+	}
+}
+
+func (g *generator) dailyLogs(dataset database.DatasetConfig) {
+	if len(g.metrics) == 0 {
+		return
+	}
+
+	active := func(day time.Time) []*metric {
+		var metrics []*metric
+		for _, m := range g.metrics {
+			if m.activeOn(day) {
+				metrics = append(metrics, m)
+			}
+		}
+		return metrics
+	}
+
+	adherence := func(day time.Time) float64 {
+		if isWeekend(day) {
+			return 0.78
+		}
+		return 0.9
+	}
+
+	g.spread(dataset.ID, false, func(day time.Time) float64 {
+		return float64(len(active(day))) * adherence(day)
+	}, func(day time.Time, n int) {
+		for _, m := range pickSome(g, active(day), n) {
+			value, exp := g.metricValue(m, day)
+
+			data := map[string]interface{}{
+				"date":      date(day),
+				"metric_id": m.id,
+				"value":     value,
+			}
+			if exp != nil {
+				data["experiment_id"] = exp.id
+			}
+			if m.template.goalType != "" {
+				data["goal_type"] = m.template.goalType
+				data["goal_value"] = m.template.goalValue
+			}
+			if g.chance(0.08) {
+				data["notes"] = pick(g, dailyLogNotes)
+			}
+
+			g.emit(dataset.ID, day.Add(time.Duration(g.intBetween(19*60, 23*60))*time.Minute), data)
+		}
+	})
+}
+
+// metricValue is the metric's value on day as a log entry stores it, with the
+// effect of any running experiment that tracks the metric phased in over its
+// first week.
+func (g *generator) metricValue(m *metric, day time.Time) (string, *experiment) {
+	template := m.template
+
+	effect := 0.0
+	var running *experiment
+	for _, exp := range g.experiments {
+		delta, tracked := exp.effects[m.id]
+		if !tracked || day.Before(exp.start) || day.After(exp.end) {
+			continue
+		}
+		running = exp
+		effect += delta * math.Min(1, float64(day.Sub(exp.start).Hours()/24+1)/7)
+	}
+
+	weekend := 0.0
+	if isWeekend(day) {
+		weekend = template.weekend
+	}
+
+	switch {
+	case template.kind == "boolean":
+		return strconv.FormatBool(g.chance(template.base + weekend + effect)), running
+	case template.unit == "lbs":
+		return formatNumber(round(g.weightOn(day)+effect+g.normal(0, 0.4), 1)), running
+	}
+
+	if g.chance(template.zeroChance) {
+		return "0", running
+	}
+
+	value := g.normal(template.base+weekend+effect, template.noise)
+	return formatNumber(round(clamp(value, template.low, template.high), template.decimals)), running
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func isWeekend(day time.Time) bool {
+	return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+}
+
+func pickWeighted(g *generator, weights []float64) int {
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+
+	target := g.rng.Float64() * total
+	for i, weight := range weights {
+		target -= weight
+		if target < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
@@ -0,0 +1,567 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"myproject/backend/database"
+	"strings"
+	"time"
+)
+
+func (g *generator) journal(dataset database.DatasetConfig, field string, perDay float64, entry func() string) {
+	g.spread(dataset.ID, true, func(time.Time) float64 { return perDay }, func(day time.Time, n int) {
+		for range n {
+			g.emit(dataset.ID, day.Add(time.Duration(g.intBetween(20*60, 23*60))*time.Minute), map[string]interface{}{
+				"date": date(day),
+				field:  entry(),
+			})
+		}
+	})
+}
+
+func (g *generator) gratitudeJournal(dataset database.DatasetConfig) {
+	g.journal(dataset, "entry", 0.5, func() string {
+		var lines []string
+		for i, item := range pickSome(g, gratitudeItems, g.intBetween(2, 4)) {
+			if g.chance(0.5) {
+				lines = append(lines, fmt.Sprintf("%d. %s", i+1, item))
+			} else {
+				lines = append(lines, "- "+item)
+			}
+		}
+		return fmt.Sprintf("**%s**\n\n%s", pick(g, gratitudeHeadings), strings.Join(lines, "\n"))
+	})
+}
+
+func (g *generator) affirmations(dataset database.DatasetConfig) {
+	g.journal(dataset, "affirmation", 0.3, func() string {
+		return strings.Join(pickSome(g, affirmationSentences, g.intBetween(1, 2)), " ")
+	})
+}
+
+func (g *generator) creativityJournal(dataset database.DatasetConfig) {
+	g.journal(dataset, "entry", 1.0/7, func() string {
+		prompt := pick(g, creativityPrompts)
+		return fmt.Sprintf("**Prompt:** %s\n\n---\n\n%s", prompt.prompt, strings.Join(pickSome(g, prompt.paragraphs, g.intBetween(1, len(prompt.paragraphs))), "\n\n"))
+	})
+}
+
+func (g *generator) questionJournal(dataset database.DatasetConfig) {
+	g.journal(dataset, "entry", 1.0/7, func() string {
+		return fmt.Sprintf("**Question:** %s\n\n**My thoughts:**\n\n%s", pick(g, reflectionQuestions), strings.Join(pickSome(g, reflectionAnswers, g.intBetween(1, 3)), "\n\n"))
+	})
+}
+
+type timeCategory struct {
+	name         string
+	color        string
+	minutes      [2]int
+	weekday      float64
+	weekend      float64
+	descriptions []string
+	tags         []string
+}
+
+var timeCategoryTemplates = []timeCategory{
+	{"Deep Work", "#4CAF50", [2]int{50, 180}, 3, 0.3, []string{"Feature development", "Writing the design doc", "Refactoring the import pipeline", "Code review backlog", "Debugging flaky tests", "Quarterly planning document"}, []string{"coding", "focus", "writing", "planning"}},
+	{"Meetings", "#2196F3", [2]int{15, 60}, 2.5, 0, []string{"Team standup", "One-on-one", "Sprint planning", "Design review", "Customer call", "All hands"}, []string{"team", "standup", "sync", "customer"}},
+	{"Exercise", "#FF9800", [2]int{30, 90}, 0.8, 2, []string{"Strength training", "Morning run", "Yoga class", "Cycling", "Swimming laps", "Long walk"}, []string{"fitness", "strength", "cardio", "outdoors"}},
+	{"Personal", "#9C27B0", [2]int{20, 120}, 0.6, 3, []string{"Grocery shopping", "Cooking dinner", "Reading", "Family time", "Errands", "Cleaning the apartment"}, []string{"home", "family", "errands", "reading"}},
+	{"Admin", "#607D8B", [2]int{10, 45}, 1.2, 0.3, []string{"Email triage", "Expense reports", "Scheduling", "Paying bills", "Updating the task list"}, []string{"email", "admin", "finance"}},
+	{"Learning", "#00BCD4", [2]int{30, 90}, 0.7, 1, []string{"Online course", "Reading technical articles", "Language practice", "Watching conference talks"}, []string{"learning", "course", "reading"}},
+}
+
+type timeCategoryState struct {
+	id       string
+	template timeCategory
+}
+
+func (g *generator) timeCategories(dataset database.DatasetConfig) {
+	n := g.fixed(dataset.ID, len(timeCategoryTemplates))
+	for i := range n {
+		template := timeCategoryTemplates[i%len(timeCategoryTemplates)]
+		if i >= len(timeCategoryTemplates) {
+			template.name = fmt.Sprintf("%s (%d)", template.name, i/len(timeCategoryTemplates)+1)
+		}
+
+		id := g.emit(dataset.ID, g.from, map[string]interface{}{
+			"name":    template.name,
+			"color":   template.color,
+			"private": false,
+		})
+		g.activities = append(g.activities, &timeCategoryState{id: id, template: template})
+	}
+}
+
+func (g *generator) timeEntries(dataset database.DatasetConfig) {
+	categories := g.activities
+	if len(categories) == 0 {
+		for _, template := range timeCategoryTemplates {
+			categories = append(categories, &timeCategoryState{template: template})
+		}
+	}
+
+	g.spread(dataset.ID, true, func(day time.Time) float64 {
+		if isWeekend(day) {
+			return 1.5
+		}
+		return 4.5
+	}, func(day time.Time, n int) {
+		weights := make([]float64, len(categories))
+		for i, category := range categories {
+			if isWeekend(day) {
+				weights[i] = category.template.weekend
+			} else {
+				weights[i] = category.template.weekday
+			}
+		}
+
+		cursor := day.Add(time.Duration(g.intBetween(7*60+30, 9*60+30)) * time.Minute)
+		for range n {
+			category := categories[pickWeighted(g, weights)]
+			minutes := g.intBetween(category.template.minutes[0], category.template.minutes[1]) / 5 * 5
+			end := cursor.Add(time.Duration(minutes) * time.Minute)
+
+			data := map[string]interface{}{
+				"description":      pick(g, category.template.descriptions),
+				"start_time":       cursor.Format(time.RFC3339),
+				"end_time":         end.Format(time.RFC3339),
+				"duration_minutes": minutes,
+				"tags":             strings.Join(pickSome(g, category.template.tags, g.intBetween(1, 2)), ","),
+				"private":          g.chance(0.03),
+			}
+			if category.id != "" && g.chance(0.95) {
+				data["category_id"] = category.id
+			}
+			g.emit(dataset.ID, end, data)
+
+			cursor = end.Add(time.Duration(g.intBetween(0, 12)*5) * time.Minute)
+			if cursor.Sub(day) > 22*time.Hour {
+				cursor = day.Add(time.Duration(g.intBetween(7*60, 9*60)) * time.Minute)
+			}
+		}
+	})
+}
+
+type timeBlock struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	DayOfWeek   int    `json:"dayOfWeek"`
+	StartHour   int    `json:"startHour"`
+	StartMinute int    `json:"startMinute"`
+	EndHour     int    `json:"endHour"`
+	EndMinute   int    `json:"endMinute"`
+	Category    string `json:"category"`
+	Color       string `json:"color,omitempty"`
+}
+
+type plannerBlock struct {
+	title      string
+	start, end [2]int
+}
+
+type plannerTemplate struct {
+	name        string
+	description string
+	days        []int
+	blocks      []plannerBlock
+}
+
+var plannerTemplates = []plannerTemplate{
+	{"Standard Work Week", "Focus mornings, meetings after lunch", []int{1, 2, 3, 4, 5}, []plannerBlock{
+		{"Deep work", [2]int{9, 0}, [2]int{12, 0}},
+		{"Lunch", [2]int{12, 0}, [2]int{13, 0}},
+		{"Meetings", [2]int{13, 0}, [2]int{14, 30}},
+		{"Deep work", [2]int{14, 30}, [2]int{17, 0}},
+		{"Gym", [2]int{17, 30}, [2]int{18, 30}},
+	}},
+	{"Weekend Reset", "Slow mornings and time for chores", []int{0, 6}, []plannerBlock{
+		{"Long run", [2]int{8, 0}, [2]int{9, 30}},
+		{"Errands", [2]int{10, 0}, [2]int{12, 0}},
+		{"Reading", [2]int{14, 0}, [2]int{15, 30}},
+		{"Meal prep", [2]int{17, 0}, [2]int{18, 30}},
+	}},
+	{"Maker Schedule", "No meetings before 2pm", []int{1, 2, 3, 4, 5}, []plannerBlock{
+		{"Writing", [2]int{8, 30}, [2]int{11, 30}},
+		{"Deep work", [2]int{11, 30}, [2]int{13, 30}},
+		{"Meetings", [2]int{14, 0}, [2]int{16, 0}},
+		{"Email", [2]int{16, 0}, [2]int{16, 45}},
+	}},
+}
+
+var plannerBlockCategories = map[string]string{
+	"Deep work": "Deep Work",
+	"Writing":   "Deep Work",
+	"Meetings":  "Meetings",
+	"Email":     "Admin",
+	"Gym":       "Exercise",
+	"Long run":  "Exercise",
+	"Lunch":     "Personal",
+	"Errands":   "Personal",
+	"Meal prep": "Personal",
+	"Reading":   "Learning",
+}
+
+func (g *generator) timePlannerConfigs(dataset database.DatasetConfig) {
+	colors := make(map[string]string)
+	for _, template := range timeCategoryTemplates {
+		colors[template.name] = template.color
+	}
+
+	n := g.fixed(dataset.ID, 2)
+	for i := range n {
+		template := plannerTemplates[i%len(plannerTemplates)]
+		name := template.name
+		if i >= len(plannerTemplates) {
+			name = fmt.Sprintf("%s (%d)", name, i/len(plannerTemplates)+1)
+		}
+
+		var blocks []timeBlock
+		for _, day := range template.days {
+			for _, block := range template.blocks {
+				category := plannerBlockCategories[block.title]
+				blocks = append(blocks, timeBlock{
+					ID:          g.newID(),
+					Title:       block.title,
+					DayOfWeek:   day,
+					StartHour:   block.start[0],
+					StartMinute: block.start[1],
+					EndHour:     block.end[0],
+					EndMinute:   block.end[1],
+					Category:    category,
+					Color:       colors[category],
+				})
+			}
+		}
+
+		g.emit(dataset.ID, g.from, map[string]interface{}{
+			"name":        name,
+			"description": template.description,
+			"blocks":      blocks,
+		})
+	}
+}
+
+func (g *generator) todos(dataset database.DatasetConfig) {
+	priorities := []string{"low", "medium", "high"}
+
+	g.spread(dataset.ID, true, func(time.Time) float64 { return 3.0 / 7 }, func(day time.Time, n int) {
+		for range n {
+			template := pick(g, todoTemplates)
+			deadline := day.AddDate(0, 0, g.intBetween(1, 30))
+
+			data := map[string]interface{}{
+				"title":       template.title,
+				"description": template.description,
+				"priority":    priorities[pickWeighted(g, []float64{0.35, 0.45, 0.2})],
+				"tags":        template.tags,
+				"is_complete": false,
+				"status":      "pending",
+				"private":     g.chance(0.05),
+			}
+
+			if !deadline.After(g.to) && g.chance(0.8) {
+				completed := day.AddDate(0, 0, g.rng.IntN(int(deadline.Sub(day).Hours()/24)+5))
+				if completed.After(deadline) {
+					// A missed deadline is pushed back and remembered.
+					failed, _ := json.Marshal([]string{date(deadline)})
+					data["failed_deadlines"] = string(failed)
+					deadline = completed.AddDate(0, 0, g.intBetween(0, 3))
+				}
+				if !completed.After(g.to) {
+					data["is_complete"] = true
+					data["status"] = "completed"
+					data["completed_at"] = date(completed)
+				}
+			}
+			data["deadline"] = date(deadline)
+
+			if g.chance(0.3) {
+				data["reminder_date"] = date(deadline.AddDate(0, 0, -g.intBetween(1, 3)))
+			}
+			if len(g.metrics) > 0 && g.chance(0.1) {
+				data["related_metric_id"] = pick(g, g.metrics).id
+				data["metric_type"] = "completion"
+			}
+
+			g.emit(dataset.ID, g.at(day), data)
+		}
+	})
+}
+
+type person struct {
+	id        string
+	name      string
+	first     string
+	group     string
+	birthday  time.Time
+	met       time.Time
+	closeness float64
+}
+
+func (g *generator) peopleRecords(dataset database.DatasetConfig) {
+	n := g.entities(dataset.ID, 25)
+	used := make(map[string]bool)
+	groups := []string{"friend", "colleague", "family", "neighbor", "college", "mentor"}
+
+	for i := range n {
+		first, last := pick(g, firstNames), pick(g, lastNames)
+		name := first + " " + last
+		for attempt := 0; used[name]; attempt++ {
+			if attempt < 10 {
+				first, last = pick(g, firstNames), pick(g, lastNames)
+				name = first + " " + last
+			} else {
+				name = fmt.Sprintf("%s %c. %s", first, 'A'+rune(i%26), last)
+				if used[name] {
+					name = fmt.Sprintf("%s %s %d", first, last, i)
+				}
+			}
+		}
+		used[name] = true
+
+		p := &person{
+			name:      name,
+			first:     first,
+			group:     groups[pickWeighted(g, []float64{4, 3, 1.5, 1, 1.5, 0.5})],
+			closeness: -math.Log(1 - g.rng.Float64()),
+		}
+
+		if g.chance(0.75) {
+			p.birthday = time.Date(g.intBetween(1955, 2003), time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, g.rng.IntN(365))
+		}
+		switch {
+		case p.group == "family" && !p.birthday.IsZero():
+			p.met = p.birthday
+		case g.chance(0.2):
+			p.met = g.dayBetween(g.from, g.to)
+		default:
+			p.met = g.from.AddDate(0, 0, -g.intBetween(30, 15*365))
+		}
+
+		tags := []string{p.group}
+		tags = append(tags, pickSome(g, personTags[p.group], g.intBetween(1, 2))...)
+
+		data := map[string]interface{}{
+			"name":           name,
+			"tags":           strings.Join(tags, ","),
+			"first_met_date": date(p.met),
+			"private":        g.chance(0.08),
+		}
+		if !p.birthday.IsZero() {
+			data["birthday"] = date(p.birthday)
+		}
+		if g.chance(0.5) {
+			data["address"] = fmt.Sprintf("%d %s, %s", g.intBetween(10, 9999), pick(g, streets), pick(g, cities))
+		}
+		if g.chance(0.3) {
+			data["employment_history"] = fmt.Sprintf("- **%s** at %s (%d-present)\n- %s at %s", pick(g, jobTitles), pick(g, companies), g.intBetween(2015, 2024), pick(g, jobTitles), pick(g, companies))
+		}
+
+		createdAt := g.from
+		if p.met.After(g.from) {
+			createdAt = p.met
+		}
+		p.id = g.emit(dataset.ID, createdAt, data)
+		g.people = append(g.people, p)
+	}
+}
+
+// acquaintance picks someone already met by day, closer people more often.
+func (g *generator) acquaintance(day time.Time) *person {
+	var known []*person
+	var weights []float64
+	for _, p := range g.people {
+		if !p.met.After(day) {
+			known = append(known, p)
+			weights = append(weights, p.closeness)
+		}
+	}
+	if len(known) == 0 {
+		return pick(g, g.people)
+	}
+	return known[pickWeighted(g, weights)]
+}
+
+func (g *generator) meetings(dataset database.DatasetConfig) {
+	if len(g.people) == 0 {
+		return
+	}
+
+	g.spread(dataset.ID, true, func(day time.Time) float64 {
+		if isWeekend(day) {
+			return 0.4
+		}
+		return 0.15
+	}, func(day time.Time, n int) {
+		for range n {
+			p := g.acquaintance(day)
+			activity := pick(g, meetingActivities)
+
+			data := map[string]interface{}{
+				"person_id":        p.id,
+				"meeting_date":     date(day),
+				"duration_minutes": g.intBetween(2, 12) * 15,
+				"description":      fmt.Sprintf(pick(g, meetingDescriptions), p.first),
+				"tags":             activity.tags,
+				"feelings":         pick(g, meetingFeelings),
+				"follow_up_needed": false,
+				"private":          g.chance(0.05),
+			}
+
+			switch roll := g.rng.Float64(); {
+			case roll < 0.65:
+				data["location_type"] = "in-person"
+				data["location"] = pick(g, activity.places)
+			case roll < 0.9:
+				data["location_type"] = "video call"
+				data["location"] = pick(g, []string{"Zoom", "Google Meet", "FaceTime"})
+			default:
+				data["location_type"] = "phone"
+				data["location"] = "Phone"
+			}
+
+			if g.chance(0.2) && len(g.people) > 1 {
+				other := g.acquaintance(day)
+				if other != p {
+					data["participants"] = other.name
+				}
+			}
+			if g.chance(0.2) {
+				data["follow_up_needed"] = true
+				data["follow_up_date"] = date(day.AddDate(0, 0, g.intBetween(7, 21)))
+			}
+
+			g.emit(dataset.ID, g.at(day), data)
+		}
+	})
+}
+
+func (g *generator) personAttributes(dataset database.DatasetConfig) {
+	mean := g.perParent(dataset.ID, len(g.people), 2.5)
+	for _, p := range g.people {
+		for _, attribute := range pickSome(g, personAttributeTemplates, g.occurrences(mean)) {
+			learned := g.dayBetween(laterOf(p.met, g.from), g.to)
+
+			data := map[string]interface{}{
+				"person_id":       p.id,
+				"attribute_name":  attribute.name,
+				"attribute_value": pick(g, attribute.values),
+				"category":        attribute.category,
+				"learned_date":    date(learned),
+				"source":          pick(g, []string{"Direct conversation", "Direct observation", "Mutual friend", "Social media"}),
+				"private":         g.chance(0.1),
+			}
+			if g.chance(0.3) {
+				data["notes"] = pick(g, attribute.notes)
+			}
+
+			g.emit(dataset.ID, learned, data)
+		}
+	}
+}
+
+func (g *generator) personNotes(dataset database.DatasetConfig) {
+	if len(g.people) == 0 {
+		return
+	}
+
+	g.spread(dataset.ID, true, func(time.Time) float64 { return 1.0 / 7 }, func(day time.Time, n int) {
+		for range n {
+			p := g.acquaintance(day)
+			note := pick(g, personNoteTemplates)
+
+			g.emit(dataset.ID, g.at(day), map[string]interface{}{
+				"person_id": p.id,
+				"note_date": date(day),
+				"content":   fmt.Sprintf(note.content, p.first),
+				"category":  note.category,
+				"tags":      note.tags,
+				"private":   g.chance(0.1),
+			})
+		}
+	})
+}
+
+func (g *generator) birthdayReminders(dataset database.DatasetConfig) {
+	var withBirthdays []*person
+	for _, p := range g.people {
+		if !p.birthday.IsZero() {
+			withBirthdays = append(withBirthdays, p)
+		}
+	}
+
+	mean := g.perParent(dataset.ID, len(withBirthdays), 1)
+	for _, p := range withBirthdays {
+		birthday := nextBirthday(p.birthday, g.from)
+		for range g.occurrences(mean) {
+			advance := pick(g, []int{1, 3, 7, 14})
+			g.emit(dataset.ID, g.from, map[string]interface{}{
+				"person_id":     p.id,
+				"reminder_date": date(birthday.AddDate(0, 0, -advance)),
+				"advance_days":  advance,
+				"reminder_note": fmt.Sprintf(pick(g, birthdayNotes), p.first),
+			})
+			birthday = birthday.AddDate(1, 0, 0)
+		}
+	}
+}
+
+func nextBirthday(birthday time.Time, from time.Time) time.Time {
+	next := time.Date(from.Year(), birthday.Month(), birthday.Day(), 0, 0, 0, 0, time.UTC)
+	if next.Before(from) {
+		next = next.AddDate(1, 0, 0)
+	}
+	return next
+}
+
+func (g *generator) personRelationships(dataset database.DatasetConfig) {
+	if len(g.people) < 2 {
+		return
+	}
+
+	n := g.fixed(dataset.ID, len(g.people)*2/5)
+	seen := make(map[[2]string]bool)
+	for attempts := 0; len(seen) < n && attempts < n*20; attempts++ {
+		a, b := pick(g, g.people), pick(g, g.people)
+		if a == b {
+			continue
+		}
+		key := [2]string{a.id, b.id}
+		if a.id > b.id {
+			key = [2]string{b.id, a.id}
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		relationship := "friend"
+		switch {
+		case a.group == "family" && b.group == "family":
+			relationship = pick(g, []string{"family", "sibling", "cousin"})
+		case a.group == "colleague" && b.group == "colleague":
+			relationship = "colleague"
+		case g.chance(0.1):
+			relationship = "partner"
+		}
+
+		since := laterOf(a.met, b.met).AddDate(0, 0, -g.intBetween(0, 5*365))
+		g.emit(dataset.ID, laterOf(since, g.from), map[string]interface{}{
+			"person1_id":        a.id,
+			"person2_id":        b.id,
+			"relationship_type": relationship,
+			"description":       fmt.Sprintf(pick(g, relationshipDescriptions), a.first, b.first),
+			"since_date":        date(since),
+		})
+	}
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
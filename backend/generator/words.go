@@ -0,0 +1,261 @@
+package generator
+
+var gratitudeHeadings = []string{
+	"Today I'm grateful for:",
+	"Three things I'm grateful for today:",
+	"Good things from today:",
+	"What went well:",
+}
+
+var gratitudeItems = []string{
+	"A slow breakfast with no rush to be anywhere",
+	"Sunny weather on my morning walk",
+	"A long phone call with an old friend",
+	"Finishing a task I had been putting off for weeks",
+	"A good night of sleep",
+	"Dinner with family",
+	"Feeling strong during my workout",
+	"A kind message from a coworker",
+	"Quiet time to read in the evening",
+	"Fresh bread from the bakery down the street",
+	"Getting outside during lunch",
+	"A productive, focused afternoon",
+	"Laughing at something silly with friends",
+	"My health and being able to move without pain",
+	"Cooking something new that turned out well",
+	"The first cup of coffee in the morning",
+	"A clean and tidy apartment",
+	"Learning something new from a podcast",
+}
+
+var affirmationSentences = []string{
+	"I am capable of achieving my goals through consistent daily actions.",
+	"Each small step I take builds toward something meaningful.",
+	"I choose to focus on progress, not perfection.",
+	"Growth comes from embracing challenges and learning from setbacks.",
+	"I deserve rest as much as I deserve success.",
+	"I can handle whatever today brings.",
+	"My worth is not measured by my productivity.",
+	"I am becoming the person I want to be, one day at a time.",
+	"I treat myself with the same kindness I offer others.",
+	"I have everything I need to take the next step.",
+	"I let go of what I cannot control.",
+	"My effort today is enough.",
+}
+
+type creativityPrompt struct {
+	prompt     string
+	paragraphs []string
+}
+
+var creativityPrompts = []creativityPrompt{
+	{"Write about a world where gravity works differently", []string{
+		"In this world, gravity changes direction every twelve hours. Cities are built like hourglasses so that living spaces can be flipped when gravity reverses.",
+		"The birds have adapted best. They drift in slow spirals during the transition, when gravity weakens to almost nothing and everyone else holds on to the railings.",
+		"Children grow up with two bedrooms, one on the ceiling and one on the floor, and argue about which one is really theirs.",
+	}},
+	{"Describe a door that should never be opened", []string{
+		"It sits at the end of the hallway in my grandmother's house, painted the same green as the walls, as if it hoped no one would notice it.",
+		"Nobody ever said not to open it. It was simply understood, the way you understand not to wake a sleeping dog.",
+	}},
+	{"Invent a new holiday and describe how people celebrate it", []string{
+		"On the Day of Unfinished Things, everyone brings one abandoned project to the town square and trades it for someone else's.",
+		"By evening the square is full of half-knitted scarves, unfinished novels and bicycles missing one wheel, all going home with new owners.",
+		"The rule is that you have a year to finish what you took, or pass it on again.",
+	}},
+	{"Write a letter to yourself ten years from now", []string{
+		"I hope you still take walks after dinner. I hope you kept the habit of writing things down, even when nothing seemed worth writing.",
+		"Whatever you are worried about right now, I was worried about something too, and it turned out fine.",
+	}},
+	{"Tell the story of an object in your kitchen", []string{
+		"The cast iron pan has outlived two apartments and one relationship. It was my father's, and before that it belonged to someone at a yard sale.",
+		"It is heavier than it needs to be and it never quite forgives you for leaving it wet, but every egg I have ever cooked in it was perfect.",
+	}},
+}
+
+var reflectionQuestions = []string{
+	"What am I most excited about in the next month?",
+	"What drained my energy this week, and what restored it?",
+	"What would I do if I knew I could not fail?",
+	"Who do I want to spend more time with?",
+	"What habit is quietly making my life better?",
+	"What am I avoiding, and why?",
+	"What did I learn from a mistake recently?",
+	"What does a perfect ordinary day look like for me?",
+}
+
+var reflectionAnswers = []string{
+	"I'm excited about the new project at work and the chance to learn something completely new. There's something energizing about figuring things out as I go.",
+	"Meetings without a clear purpose drained me. Long walks and cooking dinner from scratch gave the energy back.",
+	"I would probably start writing more seriously. It's the thing I keep coming back to when I have free time.",
+	"I want to see my old college friends more often. We always say we'll plan something and then never do.",
+	"Going to bed at the same time every night. I didn't expect it to matter this much, but my mornings are completely different.",
+	"I've been avoiding the conversation about next year's plans because I don't have a clear answer yet. Maybe that's fine.",
+	"I said yes to too many things and ended up doing all of them badly. Saying no earlier would have been kinder to everyone.",
+	"Wake up without an alarm, coffee on the balcony, a few hours of focused work, a long lunch and an evening with friends.",
+	"Getting out in nature always helps me reset and gain perspective on everything else in life.",
+	"I noticed I feel calmer on the days I don't check my phone first thing in the morning.",
+}
+
+var bloodworkNotes = []string{
+	"Annual physical",
+	"Follow-up panel after diet change",
+	"Drawn early morning, well hydrated",
+	"Requested by primary care doctor",
+	"Checking vitamin levels after winter",
+}
+
+var dailyLogNotes = []string{
+	"Good energy after morning workout",
+	"Slightly tired but still better than usual",
+	"Stressful day at work",
+	"Slept badly, woke up twice",
+	"Great day overall",
+	"Travel day, routine was off",
+	"Felt a cold coming on",
+	"Busy weekend with friends",
+}
+
+type todoTemplate struct {
+	title       string
+	description string
+	tags        string
+}
+
+var todoTemplates = []todoTemplate{
+	{"Schedule annual physical exam", "Book the yearly checkup with the doctor", "health,medical"},
+	{"Update resume", "Add recent projects and skills", "career,professional"},
+	{"Plan weekend hiking trip", "Research trails and book a campsite", "recreation,planning"},
+	{"Renew car registration", "Registration expires at the end of the month", "car,admin"},
+	{"Call the insurance company", "Ask about the claim from last month", "finance,admin"},
+	{"Buy birthday present", "Something thoughtful, not another gift card", "gifts,family"},
+	{"Clean out the garage", "Donate what we have not used in a year", "home,chores"},
+	{"File expense report", "Receipts from the conference trip", "work,finance"},
+	{"Book dentist appointment", "Six-month cleaning", "health,medical"},
+	{"Fix the leaking faucet", "Kitchen sink, probably just the washer", "home,repairs"},
+	{"Review monthly budget", "Compare spending against the plan", "finance,planning"},
+	{"Finish online course module", "Two lectures and the quiz left", "learning,career"},
+	{"Write thank-you notes", "For everyone who helped with the move", "personal,family"},
+	{"Back up laptop", "Full backup before the OS upgrade", "tech,admin"},
+	{"Prepare presentation slides", "Quarterly review deck", "work,presentation"},
+	{"Meal prep for the week", "Cook grains and chop vegetables on Sunday", "food,health"},
+}
+
+var firstNames = []string{
+	"Sarah", "Mike", "Lisa", "David", "Emma", "James", "Olivia", "Daniel", "Sophia", "Ethan",
+	"Ava", "Noah", "Mia", "Lucas", "Isabella", "Liam", "Charlotte", "Benjamin", "Amelia", "Henry",
+	"Priya", "Wei", "Carlos", "Fatima", "Kenji", "Aisha", "Mateo", "Ingrid", "Omar", "Leila",
+	"Grace", "Samuel", "Chloe", "Jack", "Hannah", "Ryan", "Zoe", "Nathan", "Nora", "Elijah",
+}
+
+var lastNames = []string{
+	"Johnson", "Chen", "Williams", "Garcia", "Smith", "Brown", "Martinez", "Lee", "Patel", "Nguyen",
+	"Kim", "Rodriguez", "Davis", "Wilson", "Anderson", "Thomas", "Moore", "Taylor", "Jackson", "White",
+	"Harris", "Clark", "Lewis", "Walker", "Young", "Allen", "King", "Wright", "Scott", "Green",
+	"Tanaka", "Okafor", "Silva", "Novak", "Haddad", "Schmidt", "Rossi", "Kowalski", "Ahmed", "Larsen",
+}
+
+var personTags = map[string][]string{
+	"friend":    {"close", "hiking", "board-games", "running"},
+	"colleague": {"engineering", "work", "design", "product"},
+	"family":    {"sister", "brother", "cousin", "parent"},
+	"neighbor":  {"building", "dog-walks", "local"},
+	"college":   {"tech", "roommate", "alumni"},
+	"mentor":    {"career", "advice", "engineering"},
+}
+
+var streets = []string{"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Park Blvd", "Elm St", "Lakeview Rd", "Hillside Ave", "Pine St", "Market St"}
+
+var cities = []string{"San Francisco, CA", "Oakland, CA", "Seattle, WA", "Portland, OR", "Austin, TX", "Denver, CO", "Chicago, IL", "Brooklyn, NY", "Boston, MA", "Minneapolis, MN"}
+
+var jobTitles = []string{"Software Engineer", "Product Manager", "Designer", "Data Scientist", "Teacher", "Nurse", "Architect", "Marketing Lead", "Accountant", "Engineering Manager"}
+
+var companies = []string{"Acme Corp", "Globex", "Initech", "Umbrella Health", "Stark Industries", "Wayne Enterprises", "Hooli", "Pied Piper", "Vandelay Industries", "Soylent"}
+
+type meetingActivity struct {
+	places []string
+	tags   string
+}
+
+var meetingActivities = []meetingActivity{
+	{[]string{"Blue Bottle Coffee", "Ritual Coffee", "The corner cafe"}, "coffee,catchup"},
+	{[]string{"Tartine", "Nopa", "The taco place on 24th"}, "lunch,catchup"},
+	{[]string{"Their place", "My place"}, "dinner,home"},
+	{[]string{"Golden Gate Park", "Mount Tam trailhead", "The waterfront"}, "walk,outdoors"},
+	{[]string{"The office", "Conference room B"}, "work,one-on-one"},
+	{[]string{"The climbing gym", "The running track"}, "fitness,active"},
+}
+
+var meetingDescriptions = []string{
+	"Caught up on life with %s, talked about work and travel plans",
+	"%s shared news about a new job and asked for advice on negotiating",
+	"Long walk with %s, mostly talked about books we've been reading",
+	"Helped %s think through a decision about moving",
+	"Quick coffee with %s between meetings",
+	"%s told me about the trip they just got back from",
+	"Celebrated %s's promotion",
+	"Planned the next group trip with %s",
+}
+
+var meetingFeelings = []string{
+	"Great to reconnect!",
+	"Energized and inspired",
+	"Relaxed and happy",
+	"A bit rushed, want to do it properly next time",
+	"Grateful for the friendship",
+	"Tired but glad we met",
+}
+
+type attributeTemplate struct {
+	name     string
+	category string
+	values   []string
+	notes    []string
+}
+
+var personAttributeTemplates = []attributeTemplate{
+	{"Favorite Coffee", "preferences", []string{"Oat milk latte", "Black coffee", "Cortado", "Cold brew"}, []string{"Always orders this at coffee shops"}},
+	{"Programming Language", "professional", []string{"Python, JavaScript", "Go", "Rust", "TypeScript"}, []string{"Primary language at work"}},
+	{"Dietary Restrictions", "preferences", []string{"Vegetarian", "Gluten-free", "No shellfish", "None"}, []string{"Remember when planning dinners"}},
+	{"Kids", "family", []string{"Two daughters", "One son", "Expecting their first", "No kids"}, []string{"Ask how they are doing"}},
+	{"Pet", "family", []string{"A golden retriever named Max", "Two cats", "A rescue greyhound"}, []string{"Loves showing photos"}},
+	{"Hometown", "background", []string{"Chicago", "Seoul", "Lagos", "Lisbon", "Toronto"}, []string{"Moved here for college"}},
+	{"Favorite Book", "interests", []string{"The Overstory", "Dune", "Middlemarch", "Project Hail Mary"}, []string{"Recommended it to me twice"}},
+	{"Hobby", "interests", []string{"Rock climbing", "Pottery", "Sourdough baking", "Chess", "Trail running"}, []string{"Has been doing it for years"}},
+	{"Allergies", "health", []string{"Peanuts", "Cats", "Pollen"}, []string{"Important for dinners"}},
+}
+
+type noteTemplate struct {
+	content  string
+	category string
+	tags     string
+}
+
+var personNoteTemplates = []noteTemplate{
+	{"%s mentioned they're thinking about switching to a new role. Seems excited about the opportunities.", "career", "career-change,work"},
+	{"%s is training for a half marathon in the spring.", "health", "running,goals"},
+	{"%s's parents are visiting next month, they're a bit stressed about it.", "family", "family,visit"},
+	{"%s recommended a great podcast about urban design.", "interests", "podcast,recommendation"},
+	{"%s just moved into a new apartment, should bring a housewarming gift.", "personal", "moving,gift"},
+	{"%s is looking for a good dentist in the area.", "personal", "recommendation,health"},
+	{"%s got promoted to team lead!", "career", "promotion,celebrate"},
+}
+
+var birthdayNotes = []string{
+	"Get a card for %s",
+	"Plan dinner for %s's birthday",
+	"Call %s",
+	"Order a gift for %s",
+}
+
+var relationshipDescriptions = []string{
+	"%s and %s met through work",
+	"%s and %s have been friends since school",
+	"%s introduced me to %s",
+	"%s and %s live in the same building",
+}
+
+var fillerWords = []string{
+	"morning", "review", "project", "quick", "weekly", "notes", "update", "plan", "summary", "check",
+	"follow-up", "draft", "idea", "home", "team", "personal", "long", "short", "new", "routine",
+}
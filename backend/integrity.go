@@ -0,0 +1,28 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+	"myproject/backend/integrity"
+)
+
+func (a *App) VerifyIntegrity() (integrity.Report, error) {
+	return integrity.Check(a.filesRoot)
+}
+
+func (a *App) RepairIntegrity(optionsJSON string) (integrity.RepairResult, error) {
+	var options integrity.RepairOptions
+	if optionsJSON != "" {
+		err := json.Unmarshal([]byte(optionsJSON), &options)
+		if err != nil {
+			return integrity.RepairResult{}, fmt.Errorf("invalid repair options: %w", err)
+		}
+	}
+
+	return integrity.Repair(a.filesRoot, options)
+}
+
+func (a *App) GetQuarantinedRecords() ([]database.QuarantinedRecord, error) {
+	return database.ListQuarantinedRecords()
+}
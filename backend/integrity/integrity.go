@@ -0,0 +1,315 @@
+package integrity
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+	"myproject/backend/file"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	KindSQLite           = "sqlite"
+	KindOrphanedRecord   = "orphaned_record"
+	KindInvalidJSON      = "invalid_json"
+	KindDanglingRelation = "dangling_relation"
+	KindSchemaViolation  = "schema_violation"
+	KindMissingFile      = "missing_file"
+	KindOrphanedFile     = "orphaned_file"
+)
+
+var AllKinds = []string{
+	KindSQLite,
+	KindOrphanedRecord,
+	KindInvalidJSON,
+	KindDanglingRelation,
+	KindSchemaViolation,
+	KindMissingFile,
+	KindOrphanedFile,
+}
+
+type Issue struct {
+	Kind      string `json:"kind"`
+	DatasetID string `json:"datasetId,omitempty"`
+	RecordID  string `json:"recordId,omitempty"`
+	Field     string `json:"field,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Message   string `json:"message"`
+}
+
+type Report struct {
+	CheckedAt      time.Time      `json:"checkedAt"`
+	RecordsChecked int            `json:"recordsChecked"`
+	FilesChecked   int            `json:"filesChecked"`
+	Issues         []Issue        `json:"issues"`
+	Counts         map[string]int `json:"counts"`
+}
+
+func (r Report) OK() bool {
+	return len(r.Issues) == 0
+}
+
+type checker struct {
+	filesRoot  string
+	datasets   map[string]database.Dataset
+	recordIDs  map[string]string
+	references map[string]bool
+	report     Report
+}
+
+func Check(filesRoot string) (Report, error) {
+	c := &checker{
+		filesRoot:  filesRoot,
+		datasets:   make(map[string]database.Dataset),
+		recordIDs:  make(map[string]string),
+		references: make(map[string]bool),
+		report: Report{
+			CheckedAt: time.Now(),
+			Issues:    []Issue{},
+			Counts:    make(map[string]int),
+		},
+	}
+
+	problems, err := database.IntegrityCheck()
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	for _, problem := range problems {
+		c.add(Issue{Kind: KindSQLite, Message: problem})
+	}
+
+	datasets, err := database.ListDatasets()
+	if err != nil {
+		return Report{}, err
+	}
+	for _, dataset := range datasets {
+		c.datasets[dataset.ID] = dataset
+	}
+
+	records, err := database.GetAllDataRecords()
+	if err != nil {
+		return Report{}, err
+	}
+	for _, record := range records {
+		c.recordIDs[record.ID] = record.DatasetID
+	}
+
+	for _, record := range records {
+		c.checkRecord(record)
+	}
+	c.report.RecordsChecked = len(records)
+
+	// Quarantined records keep their attachments until they are dealt with.
+	quarantined, err := database.ListQuarantinedRecords()
+	if err != nil {
+		return Report{}, err
+	}
+	for _, record := range quarantined {
+		var data interface{}
+		json.Unmarshal(record.Data, &data)
+		references := file.CollectReferences(data)
+		if raw, ok := data.(string); ok {
+			// Data that is not valid JSON is listed as a string.
+			references = file.ScanReferences([]byte(raw))
+		}
+		for _, reference := range references {
+			c.references[filepath.ToSlash(reference)] = true
+		}
+	}
+
+	// Attachments of deleted or edited records stay on disk while the undo
+	// history can bring them back.
+	journaled, err := database.ListJournalFiles()
+	if err != nil {
+		return Report{}, err
+	}
+	for _, path := range journaled {
+		c.references[path] = true
+	}
+
+	err = c.checkFilesOnDisk()
+	if err != nil {
+		return Report{}, err
+	}
+
+	return c.report, nil
+}
+
+func (c *checker) add(issue Issue) {
+	c.report.Issues = append(c.report.Issues, issue)
+	c.report.Counts[issue.Kind]++
+}
+
+func (c *checker) checkRecord(record database.DataRecord) {
+	dataset, knownDataset := c.datasets[record.DatasetID]
+	if !knownDataset {
+		c.add(Issue{
+			Kind:      KindOrphanedRecord,
+			DatasetID: record.DatasetID,
+			RecordID:  record.ID,
+			Message:   fmt.Sprintf("record belongs to dataset '%s', which does not exist", record.DatasetID),
+		})
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(record.Data, &data); err != nil || data == nil {
+		c.add(Issue{
+			Kind:      KindInvalidJSON,
+			DatasetID: record.DatasetID,
+			RecordID:  record.ID,
+			Message:   "record data is not a valid JSON object",
+		})
+		// Its attachments stay referenced until the record is repaired.
+		for _, reference := range file.ScanReferences(record.Data) {
+			c.references[filepath.ToSlash(reference)] = true
+		}
+		return
+	}
+
+	for _, reference := range file.CollectReferences(data) {
+		normalized := filepath.ToSlash(reference)
+		c.references[normalized] = true
+
+		fullPath, err := file.ResolvePath(c.filesRoot, reference)
+		if err != nil {
+			c.add(Issue{
+				Kind:      KindMissingFile,
+				DatasetID: record.DatasetID,
+				RecordID:  record.ID,
+				Path:      reference,
+				Message:   fmt.Sprintf("referenced file %s is not a valid stored file: %v", reference, err),
+			})
+			continue
+		}
+
+		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+			c.add(Issue{
+				Kind:      KindMissingFile,
+				DatasetID: record.DatasetID,
+				RecordID:  record.ID,
+				Path:      reference,
+				Message:   fmt.Sprintf("referenced file %s does not exist", reference),
+			})
+		}
+	}
+
+	if !knownDataset {
+		return
+	}
+
+	for _, field := range dataset.Fields {
+		value, exists := data[field.Key]
+
+		if field.IsRelation && field.RelatedDataset != "" {
+			if relatedID, ok := value.(string); ok && relatedID != "" {
+				if c.recordIDs[relatedID] != field.RelatedDataset {
+					c.add(Issue{
+						Kind:      KindDanglingRelation,
+						DatasetID: record.DatasetID,
+						RecordID:  record.ID,
+						Field:     field.Key,
+						Message:   fmt.Sprintf("%s points to %s, which is not a record in %s", field.DisplayName, relatedID, field.RelatedDataset),
+					})
+				}
+			}
+		}
+
+		if message := schemaViolation(field, value, exists); message != "" {
+			c.add(Issue{
+				Kind:      KindSchemaViolation,
+				DatasetID: record.DatasetID,
+				RecordID:  record.ID,
+				Field:     field.Key,
+				Message:   message,
+			})
+		}
+	}
+}
+
+func schemaViolation(field database.FieldDefinition, value interface{}, exists bool) string {
+	// Required fields are only enforced by the forms, and older records and
+	// imports routinely leave them out, so only values that are present are
+	// checked against their field type.
+	if !exists || value == nil {
+		return ""
+	}
+
+	switch field.Type {
+	case database.FieldTypeNumber, database.FieldTypePercentage:
+		if _, ok := value.(float64); !ok {
+			return fmt.Sprintf("%s should be a number, found %v", field.DisplayName, value)
+		}
+	case database.FieldTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("%s should be true or false, found %v", field.DisplayName, value)
+		}
+	case database.FieldTypeDate:
+		if s, ok := value.(string); ok && s == "" && field.IsOptional {
+			return ""
+		}
+		if _, ok := database.ParseDateValue(value); !ok {
+			return fmt.Sprintf("%s should be a date, found %v", field.DisplayName, value)
+		}
+	case database.FieldTypeText, database.FieldTypeMarkdown, database.FieldTypeFile, database.FieldTypeTags:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("%s should be text, found %v", field.DisplayName, value)
+		}
+	case database.FieldTypeFileMultiple:
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Sprintf("%s should be a list of files", field.DisplayName)
+		}
+	}
+
+	return ""
+}
+
+func (c *checker) checkFilesOnDisk() error {
+	filesDir := filepath.Join(c.filesRoot, file.FilesDir)
+
+	var orphaned []string
+	err := filepath.WalkDir(filesDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if entry.IsDir() {
+			if path != filesDir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		c.report.FilesChecked++
+
+		relativePath, err := filepath.Rel(c.filesRoot, path)
+		if err != nil {
+			return err
+		}
+
+		if !c.references[filepath.ToSlash(relativePath)] {
+			orphaned = append(orphaned, relativePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan files: %w", err)
+	}
+
+	sort.Strings(orphaned)
+	for _, path := range orphaned {
+		c.add(Issue{
+			Kind:    KindOrphanedFile,
+			Path:    path,
+			Message: fmt.Sprintf("file %s is not referenced by any record", path),
+		})
+	}
+
+	return nil
+}
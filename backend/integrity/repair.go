@@ -0,0 +1,390 @@
+package integrity
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const OrphanedFilesDir = "orphaned-files"
+
+type RepairOptions struct {
+	DryRun bool     `json:"dryRun"`
+	Kinds  []string `json:"kinds,omitempty"`
+}
+
+type RepairAction struct {
+	Issue   Issue  `json:"issue"`
+	Action  string `json:"action"`
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+type RepairResult struct {
+	DryRun  bool           `json:"dryRun"`
+	Report  Report         `json:"report"`
+	Actions []RepairAction `json:"actions"`
+}
+
+type pendingRecord struct {
+	data    map[string]interface{}
+	actions []int
+}
+
+type repairer struct {
+	filesRoot   string
+	datasets    map[string]database.Dataset
+	result      RepairResult
+	records     map[string]*pendingRecord
+	quarantined map[string][]int
+	reindex     []int
+	fileMoves   []int
+}
+
+func Repair(filesRoot string, options RepairOptions) (RepairResult, error) {
+	kinds := make(map[string]bool)
+	for _, kind := range options.Kinds {
+		if !isKnownKind(kind) {
+			return RepairResult{}, fmt.Errorf("unknown issue kind '%s'", kind)
+		}
+		kinds[kind] = true
+	}
+
+	report, err := Check(filesRoot)
+	if err != nil {
+		return RepairResult{}, err
+	}
+
+	datasets, err := database.ListDatasets()
+	if err != nil {
+		return RepairResult{}, err
+	}
+
+	r := &repairer{
+		filesRoot:   filesRoot,
+		datasets:    make(map[string]database.Dataset),
+		result:      RepairResult{DryRun: options.DryRun, Report: report, Actions: []RepairAction{}},
+		records:     make(map[string]*pendingRecord),
+		quarantined: make(map[string][]int),
+	}
+	for _, dataset := range datasets {
+		r.datasets[dataset.ID] = dataset
+	}
+
+	var selected []Issue
+	for _, issue := range report.Issues {
+		if len(kinds) == 0 || kinds[issue.Kind] {
+			selected = append(selected, issue)
+		}
+	}
+
+	// Quarantines are planned first so that later fixes for the same record
+	// are not written back over a row that no longer exists.
+	for _, issue := range selected {
+		if issue.Kind == KindOrphanedRecord || issue.Kind == KindInvalidJSON {
+			r.planQuarantine(issue)
+		}
+	}
+	for _, issue := range selected {
+		if issue.Kind != KindOrphanedRecord && issue.Kind != KindInvalidJSON {
+			r.plan(issue)
+		}
+	}
+
+	if options.DryRun {
+		return r.result, nil
+	}
+
+	r.applyDatabaseChanges()
+	r.applyFileMoves()
+
+	// Writes keep reference counts up to date; recounting repairs them after
+	// a crash or an edit made outside the app.
+	if err := database.RebuildFileReferences(); err != nil {
+		return r.result, fmt.Errorf("failed to recount file references: %w", err)
+	}
+
+	return r.result, nil
+}
+
+func isKnownKind(kind string) bool {
+	for _, known := range AllKinds {
+		if known == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *repairer) addAction(issue Issue, action string) int {
+	r.result.Actions = append(r.result.Actions, RepairAction{Issue: issue, Action: action})
+	return len(r.result.Actions) - 1
+}
+
+func (r *repairer) skip(issue Issue, reason string) {
+	r.result.Actions = append(r.result.Actions, RepairAction{Issue: issue, Action: "none", Error: reason})
+}
+
+func (r *repairer) planQuarantine(issue Issue) {
+	r.quarantined[issue.RecordID] = append(r.quarantined[issue.RecordID], r.addAction(issue, "move record to quarantined_records"))
+}
+
+func (r *repairer) plan(issue Issue) {
+	if actions, quarantined := r.quarantined[issue.RecordID]; quarantined && issue.RecordID != "" {
+		r.quarantined[issue.RecordID] = append(actions, r.addAction(issue, "move record to quarantined_records"))
+		return
+	}
+
+	switch issue.Kind {
+	case KindSQLite:
+		r.reindex = append(r.reindex, r.addAction(issue, "rebuild indexes"))
+	case KindOrphanedFile:
+		r.fileMoves = append(r.fileMoves, r.addAction(issue, fmt.Sprintf("move file to %s", OrphanedFilesDir)))
+	case KindDanglingRelation:
+		r.planRecordFix(issue, r.fixDanglingRelation)
+	case KindSchemaViolation:
+		r.planRecordFix(issue, r.fixSchemaViolation)
+	case KindMissingFile:
+		r.planRecordFix(issue, r.fixMissingFile)
+	default:
+		r.skip(issue, "no automatic repair available")
+	}
+}
+
+func (r *repairer) planRecordFix(issue Issue, fix func(database.Dataset, map[string]interface{}, Issue) (string, error)) {
+	pending, err := r.loadRecord(issue.RecordID)
+	if err != nil {
+		r.skip(issue, err.Error())
+		return
+	}
+
+	action, err := fix(r.datasets[issue.DatasetID], pending.data, issue)
+	if err != nil {
+		r.skip(issue, err.Error())
+		return
+	}
+
+	pending.actions = append(pending.actions, r.addAction(issue, action))
+}
+
+func (r *repairer) loadRecord(id string) (*pendingRecord, error) {
+	if pending, ok := r.records[id]; ok {
+		return pending, nil
+	}
+
+	record, err := database.GetDataRecord(id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	err = json.Unmarshal(record.Data, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := &pendingRecord{data: data}
+	r.records[id] = pending
+	return pending, nil
+}
+
+func findField(dataset database.Dataset, key string) (database.FieldDefinition, bool) {
+	for _, field := range dataset.Fields {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return database.FieldDefinition{}, false
+}
+
+func (r *repairer) fixDanglingRelation(dataset database.Dataset, data map[string]interface{}, issue Issue) (string, error) {
+	field, ok := findField(dataset, issue.Field)
+	if !ok {
+		return "", fmt.Errorf("field %s is no longer defined", issue.Field)
+	}
+
+	if !field.IsOptional {
+		return "", fmt.Errorf("%s is required, choose a new related record manually", field.DisplayName)
+	}
+
+	delete(data, field.Key)
+	return fmt.Sprintf("clear %s", field.Key), nil
+}
+
+func (r *repairer) fixSchemaViolation(dataset database.Dataset, data map[string]interface{}, issue Issue) (string, error) {
+	field, ok := findField(dataset, issue.Field)
+	if !ok {
+		return "", fmt.Errorf("field %s is no longer defined", issue.Field)
+	}
+
+	value := data[field.Key]
+	if coerced, ok := coerce(field.Type, value); ok {
+		data[field.Key] = coerced
+		return fmt.Sprintf("convert %s to %s", field.Key, field.Type), nil
+	}
+
+	if field.IsOptional {
+		delete(data, field.Key)
+		return fmt.Sprintf("clear invalid value in %s", field.Key), nil
+	}
+
+	return "", fmt.Errorf("value %v cannot be converted to %s", value, field.Type)
+}
+
+func coerce(fieldType database.FieldType, value interface{}) (interface{}, bool) {
+	switch fieldType {
+	case database.FieldTypeNumber, database.FieldTypePercentage:
+		if s, ok := value.(string); ok {
+			number, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+			if err == nil {
+				return number, true
+			}
+		}
+		if b, ok := value.(bool); ok {
+			if b {
+				return float64(1), true
+			}
+			return float64(0), true
+		}
+	case database.FieldTypeBoolean:
+		switch v := value.(type) {
+		case string:
+			parsed, err := strconv.ParseBool(strings.ToLower(strings.TrimSpace(v)))
+			if err == nil {
+				return parsed, true
+			}
+		case float64:
+			if v == 0 || v == 1 {
+				return v == 1, true
+			}
+		}
+	case database.FieldTypeText, database.FieldTypeMarkdown:
+		switch v := value.(type) {
+		case float64, bool:
+			return fmt.Sprint(v), true
+		}
+	case database.FieldTypeTags:
+		switch v := value.(type) {
+		case float64, bool:
+			return fmt.Sprint(v), true
+		case []interface{}:
+			return database.JoinTags(database.SplitTags(v)), true
+		}
+	}
+
+	return nil, false
+}
+
+func (r *repairer) fixMissingFile(dataset database.Dataset, data map[string]interface{}, issue Issue) (string, error) {
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			if v == issue.Path {
+				field, ok := findField(dataset, key)
+				if ok && !field.IsOptional {
+					data[key] = ""
+				} else {
+					delete(data, key)
+				}
+				return fmt.Sprintf("remove reference from %s", key), nil
+			}
+		case []interface{}:
+			kept := make([]interface{}, 0, len(v))
+			for _, item := range v {
+				if s, ok := item.(string); ok && s == issue.Path {
+					continue
+				}
+				kept = append(kept, item)
+			}
+			if len(kept) != len(v) {
+				data[key] = kept
+				return fmt.Sprintf("remove reference from %s", key), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("reference to %s is nested too deeply to remove automatically", issue.Path)
+}
+
+func (r *repairer) applyDatabaseChanges() {
+	var applied []int
+
+	err := database.WithTx(func(tx *sql.Tx) error {
+		for recordID, actions := range r.quarantined {
+			reason := r.result.Actions[actions[0]].Issue.Message
+			if err := database.QuarantineRecord(tx, recordID, reason); err != nil {
+				return err
+			}
+			applied = append(applied, actions...)
+		}
+
+		for recordID, pending := range r.records {
+			if len(pending.actions) == 0 {
+				continue
+			}
+
+			data, err := json.Marshal(pending.data)
+			if err != nil {
+				return err
+			}
+
+			if err := database.WriteRecordData(tx, recordID, data); err != nil {
+				return fmt.Errorf("failed to update record %s: %w", recordID, err)
+			}
+			applied = append(applied, pending.actions...)
+		}
+
+		if len(r.reindex) > 0 {
+			if _, err := tx.Exec("REINDEX"); err != nil {
+				return fmt.Errorf("failed to rebuild indexes: %w", err)
+			}
+			applied = append(applied, r.reindex...)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		for i := range r.result.Actions {
+			action := &r.result.Actions[i]
+			if action.Action != "none" && action.Issue.Kind != KindOrphanedFile {
+				action.Error = err.Error()
+			}
+		}
+		return
+	}
+
+	for _, index := range applied {
+		r.result.Actions[index].Applied = true
+	}
+}
+
+func (r *repairer) applyFileMoves() {
+	if len(r.fileMoves) == 0 {
+		return
+	}
+
+	destinationDir := filepath.Join(r.filesRoot, OrphanedFilesDir)
+
+	for _, index := range r.fileMoves {
+		action := &r.result.Actions[index]
+		source := filepath.Join(r.filesRoot, action.Issue.Path)
+		destination := filepath.Join(destinationDir, filepath.FromSlash(strings.TrimPrefix(filepath.ToSlash(action.Issue.Path), "files/")))
+
+		err := os.MkdirAll(filepath.Dir(destination), 0755)
+		if err == nil {
+			err = os.Rename(source, destination)
+		}
+		if err != nil {
+			action.Error = err.Error()
+			continue
+		}
+
+		action.Applied = true
+	}
+}
@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"log"
+	"myproject/backend/database"
+)
+
+// journaled runs a mutation as one undoable operation. The mutation passes op
+// to the database functions whose changes belong to it, and returns the
+// attachments it no longer uses; they are released after the operation is
+// saved, so files the undo history needs are kept.
+func (a *App) journaled(label string, mutate func(op *database.Operation) ([]string, error)) error {
+	op := database.BeginOperation(label)
+	released, err := mutate(op)
+
+	dropped, endErr := op.End()
+	if endErr != nil {
+		log.Printf("Error saving %q to the undo history: %v", label, endErr)
+	}
+	a.releaseFiles(append(released, dropped...))
+
+	return err
+}
+
+// Undo reverts the last operation and returns it, or nil when there is
+// nothing to undo.
+func (a *App) Undo() (*database.JournalEntry, error) {
+	return database.Undo()
+}
+
+// Redo applies the last undone operation again, or returns nil when there is
+// nothing to redo.
+func (a *App) Redo() (*database.JournalEntry, error) {
+	return database.Redo()
+}
+
+// GetUndoHistory lists the operations that can be undone or redone, newest
+// first.
+func (a *App) GetUndoHistory() ([]database.JournalEntry, error) {
+	return database.ListJournal()
+}
@@ -0,0 +1,89 @@
+package backend
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"myproject/backend/database"
+)
+
+// QueryRecords returns the records of a dataset that match a
+// database.RecordQuery given as JSON.
+func (a *App) QueryRecords(datasetID string, queryJSON string) ([]map[string]interface{}, error) {
+	var query database.RecordQuery
+	if queryJSON != "" {
+		err := json.Unmarshal([]byte(queryJSON), &query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query format: %w", err)
+		}
+	}
+
+	records, err := database.QueryDataRecords(datasetID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return recordMaps(records)
+}
+
+// ValidateFilter reports why a filter does not work for a dataset, or nil.
+func (a *App) ValidateFilter(datasetID string, filter string) error {
+	dataset, err := database.GetDataset(datasetID)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.CompileFilter(dataset, filter)
+	return err
+}
+
+func (a *App) UpdateRecordsMatching(datasetID string, filter string, changesJSON string) (int, error) {
+	var changes map[string]interface{}
+	err := json.Unmarshal([]byte(changesJSON), &changes)
+	if err != nil {
+		return 0, fmt.Errorf("invalid changes format: %w", err)
+	}
+
+	updated := 0
+	err = a.journaled("Bulk edit", func(op *database.Operation) ([]string, error) {
+		updated, err = database.UpdateDataRecordsMatching(op, datasetID, filter, changes)
+		return nil, err
+	})
+
+	return updated, err
+}
+
+// DeleteRecordsMatching deletes the records a filter matches one by one, so
+// references and attachments are handled as for single deletes. It stops at
+// the first record that cannot be deleted.
+func (a *App) DeleteRecordsMatching(datasetID string, filter string) (int, error) {
+	if err := database.RequireFilter(filter); err != nil {
+		return 0, err
+	}
+
+	records, err := database.QueryDataRecords(datasetID, database.RecordQuery{Filter: filter})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	err = a.journaled("Bulk delete", func(op *database.Operation) ([]string, error) {
+		var released []string
+		for _, record := range records {
+			paths, err := a.deleteRecord(op, record.ID)
+			if errors.Is(err, sql.ErrNoRows) {
+				// Already removed by a cascade from an earlier record.
+				continue
+			}
+			if err != nil {
+				return released, fmt.Errorf("deleted %d of %d records, record %s: %w", deleted, len(records), record.ID, err)
+			}
+			released = append(released, paths...)
+			deleted++
+		}
+		return released, nil
+	})
+
+	return deleted, err
+}
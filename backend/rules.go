@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+)
+
+func (a *App) GetRules() ([]database.Rule, error) {
+	return database.ListRules()
+}
+
+func (a *App) CreateRule(ruleJSON string) (database.Rule, error) {
+	var rule database.Rule
+	err := json.Unmarshal([]byte(ruleJSON), &rule)
+	if err != nil {
+		return database.Rule{}, fmt.Errorf("invalid rule format: %w", err)
+	}
+
+	rule.ID = ""
+	return database.CreateRule(rule)
+}
+
+func (a *App) UpdateRule(id string, ruleJSON string) (database.Rule, error) {
+	rule, err := database.GetRule(id)
+	if err != nil {
+		return database.Rule{}, err
+	}
+
+	err = json.Unmarshal([]byte(ruleJSON), &rule)
+	if err != nil {
+		return database.Rule{}, fmt.Errorf("invalid rule format: %w", err)
+	}
+
+	rule.ID = id
+	return database.UpdateRule(rule)
+}
+
+func (a *App) SetRuleEnabled(id string, enabled bool) (database.Rule, error) {
+	rule, err := database.GetRule(id)
+	if err != nil {
+		return database.Rule{}, err
+	}
+
+	rule.Enabled = enabled
+	return database.UpdateRule(rule)
+}
+
+func (a *App) DeleteRule(id string) error {
+	return database.DeleteRule(id)
+}
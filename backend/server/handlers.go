@@ -0,0 +1,380 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"myproject/backend/database"
+	"myproject/backend/file"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const maxBodyBytes = 32 << 20
+
+type contextKey string
+
+const tokenContextKey contextKey = "apiToken"
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/datasets", s.handleListDatasets)
+	mux.HandleFunc("GET /api/datasets/{id}", s.handleGetDataset)
+	mux.HandleFunc("GET /api/datasets/{id}/records", s.handleListRecords)
+	mux.HandleFunc("POST /api/datasets/{id}/records", s.handleAddRecord)
+	mux.HandleFunc("GET /api/records/{id}", s.handleGetRecord)
+	mux.HandleFunc("PUT /api/records/{id}", s.handleUpdateRecord)
+	mux.HandleFunc("DELETE /api/records/{id}", s.handleDeleteRecord)
+	mux.HandleFunc("GET /api/search", s.handleSearch)
+	mux.HandleFunc("GET /api/files/{path...}", s.handleFile)
+
+	return withCORS(s.withAuth(mux))
+}
+
+func withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Range")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		apiToken, err := database.AuthenticateAPIToken(strings.TrimSpace(token))
+		if err != nil {
+			if errors.Is(err, database.ErrInvalidAPIToken) {
+				writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), tokenContextKey, apiToken)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func tokenFromRequest(r *http.Request) database.APIToken {
+	token, _ := r.Context().Value(tokenContextKey).(database.APIToken)
+	return token
+}
+
+func (s *Server) handleListDatasets(w http.ResponseWriter, r *http.Request) {
+	datasets, err := s.store.GetDatasets()
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, datasets)
+}
+
+func (s *Server) handleGetDataset(w http.ResponseWriter, r *http.Request) {
+	dataset, err := s.store.GetDataset(r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dataset)
+}
+
+func (s *Server) handleListRecords(w http.ResponseWriter, r *http.Request) {
+	datasetID := r.PathValue("id")
+	if _, err := s.store.GetDataset(datasetID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	limit, offset, err := parsePaging(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sort := r.URL.Query().Get("sort")
+	query, err := json.Marshal(map[string]interface{}{
+		"filter":     r.URL.Query().Get("filter"),
+		"sort":       strings.TrimPrefix(sort, "-"),
+		"descending": strings.HasPrefix(sort, "-"),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	records, err := s.store.QueryRecords(datasetID, string(query))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	visible := filterPrivate(records, tokenFromRequest(r))
+	writeJSON(w, http.StatusOK, page(visible, limit, offset))
+}
+
+func (s *Server) handleAddRecord(w http.ResponseWriter, r *http.Request) {
+	body, ok := readRecordBody(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := s.store.AddRecord(r.PathValue("id"), body, false)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, record)
+}
+
+func (s *Server) handleGetRecord(w http.ResponseWriter, r *http.Request) {
+	record, ok := s.visibleRecord(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) handleUpdateRecord(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.visibleRecord(w, r); !ok {
+		return
+	}
+
+	body, ok := readRecordBody(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := s.store.UpdateRecord(r.PathValue("id"), body, false, false)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) handleDeleteRecord(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.visibleRecord(w, r); !ok {
+		return
+	}
+
+	err := s.store.DeleteRecord(r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if strings.TrimSpace(query) == "" {
+		writeError(w, http.StatusBadRequest, "query parameter q is required")
+		return
+	}
+
+	limit, offset, err := parsePaging(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	records, err := s.store.SearchRecords(query, r.URL.Query()["dataset"])
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	visible := filterPrivate(records, tokenFromRequest(r))
+	writeJSON(w, http.StatusOK, page(visible, limit, offset))
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	relativePath := path.Clean(file.FilesDir + "/" + r.PathValue("path"))
+	if !strings.HasPrefix(relativePath, file.FilesDir+"/") {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	owners, err := database.FindRecordsReferencingFile(relativePath)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	token := tokenFromRequest(r)
+	allowed := false
+	for _, owner := range owners {
+		var data map[string]interface{}
+		if json.Unmarshal(owner.Data, &data) == nil && (token.AllowPrivate || !isPrivate(data)) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	fullPath, err := s.store.GetFilePath(relativePath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	file.StreamFile(w, r, fullPath)
+}
+
+func (s *Server) visibleRecord(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	record, err := s.store.GetRecord(r.PathValue("id"), false, false)
+	if err != nil {
+		writeStoreError(w, err)
+		return nil, false
+	}
+
+	if isPrivate(record) && !tokenFromRequest(r).AllowPrivate {
+		writeError(w, http.StatusNotFound, "record not found")
+		return nil, false
+	}
+
+	return record, true
+}
+
+func isPrivate(record map[string]interface{}) bool {
+	private, _ := record["private"].(bool)
+	return private
+}
+
+func filterPrivate(records []map[string]interface{}, token database.APIToken) []map[string]interface{} {
+	if token.AllowPrivate {
+		return records
+	}
+
+	visible := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		if !isPrivate(record) {
+			visible = append(visible, record)
+		}
+	}
+	return visible
+}
+
+type pageResponse struct {
+	Total   int                      `json:"total"`
+	Limit   int                      `json:"limit"`
+	Offset  int                      `json:"offset"`
+	Records []map[string]interface{} `json:"records"`
+}
+
+func page(records []map[string]interface{}, limit int, offset int) pageResponse {
+	total := len(records)
+	if offset > total {
+		offset = total
+	}
+
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	return pageResponse{
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		Records: records[offset:end],
+	}
+}
+
+func parsePaging(r *http.Request) (int, int, error) {
+	limit, offset := 100, 0
+	var err error
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 0 || limit > 1000 {
+			return 0, 0, errors.New("limit must be between 0 and 1000")
+		}
+	}
+
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must be a positive number")
+		}
+	}
+
+	return limit, offset, nil
+}
+
+func readRecordBody(w http.ResponseWriter, r *http.Request) (string, bool) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(body, &record); err != nil || record == nil {
+		writeError(w, http.StatusBadRequest, "request body must be a JSON object")
+		return "", false
+	}
+
+	if isPrivate(record) && !tokenFromRequest(r).AllowPrivate {
+		writeError(w, http.StatusForbidden, "this token cannot write private records")
+		return "", false
+	}
+
+	return string(body), true
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	info := database.DescribeError(err)
+
+	status := http.StatusBadRequest
+	switch info.Code {
+	case database.CodeNotFound:
+		status = http.StatusNotFound
+	case database.CodeReferenced, database.CodeConflict, database.CodeUniqueViolation:
+		status = http.StatusConflict
+	}
+
+	body := map[string]interface{}{"error": info.Message, "code": info.Code}
+	if info.Details != nil {
+		body["details"] = info.Details
+	}
+	writeJSON(w, status, body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"myproject/backend/database"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Store interface {
+	GetDatasets() ([]database.Dataset, error)
+	GetDataset(id string) (database.Dataset, error)
+	GetRecords(datasetID string, fetchImages bool) ([]map[string]interface{}, error)
+	QueryRecords(datasetID string, queryJSON string) ([]map[string]interface{}, error)
+	GetRecord(id string, fetchRelatedData bool, fetchImages bool) (map[string]interface{}, error)
+	AddRecord(datasetID string, data string, fetchFiles bool) (map[string]interface{}, error)
+	UpdateRecord(id string, data string, fetchRelatedData bool, fetchFiles bool) (map[string]interface{}, error)
+	DeleteRecord(id string) error
+	SearchRecords(query string, datasetIDs []string) ([]map[string]interface{}, error)
+	GetFilePath(relativePath string) (string, error)
+}
+
+type Status struct {
+	Running bool   `json:"running"`
+	Address string `json:"address,omitempty"`
+}
+
+type Server struct {
+	store      Store
+	mu         sync.Mutex
+	httpServer *http.Server
+	address    string
+}
+
+func New(store Store) *Server {
+	return &Server{store: store}
+}
+
+func (s *Server) Start(port int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.httpServer != nil {
+		return errors.New("API server is already running")
+	}
+
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	s.httpServer = &http.Server{
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.address = address
+
+	go func(httpServer *http.Server) {
+		err := httpServer.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Println("API server stopped:", err.Error())
+		}
+	}(s.httpServer)
+
+	log.Printf("API server listening on http://%s", address)
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.httpServer == nil {
+		return nil
+	}
+
+	err := s.httpServer.Shutdown(ctx)
+	s.httpServer = nil
+	s.address = ""
+	return err
+}
+
+func (s *Server) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Status{
+		Running: s.httpServer != nil,
+		Address: s.address,
+	}
+}
@@ -0,0 +1,152 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/settings"
+	"os"
+	"path/filepath"
+)
+
+func (a *App) GetSettings() (settings.Settings, error) {
+	return a.currentSettings(), nil
+}
+
+func (a *App) GetEffectiveSettings() (settings.Settings, error) {
+	return a.currentSettings().Resolve(a.appDataDir, a.overrides), nil
+}
+
+func (a *App) UpdateSettings(settingsJSON string) (settings.Settings, error) {
+	return a.updateSettings(func(updated *settings.Settings) error {
+		encryption := updated.Encryption
+		err := json.Unmarshal([]byte(settingsJSON), updated)
+		if err != nil {
+			return fmt.Errorf("invalid settings format: %w", err)
+		}
+
+		// Encryption is only changed through the vault methods.
+		updated.Encryption = encryption
+		return nil
+	})
+}
+
+func (a *App) SetDatabasePath(dbPath string) (settings.Settings, error) {
+	if dbPath != "" {
+		err := ensureWritableDir(filepath.Dir(dbPath))
+		if err != nil {
+			return settings.Settings{}, err
+		}
+	}
+
+	return a.updateSettings(func(updated *settings.Settings) error {
+		updated.DatabasePath = dbPath
+		return nil
+	})
+}
+
+func (a *App) SetFilesLocation(filesLocation string) (settings.Settings, error) {
+	if filesLocation != "" {
+		err := ensureWritableDir(filesLocation)
+		if err != nil {
+			return settings.Settings{}, err
+		}
+	}
+
+	return a.updateSettings(func(updated *settings.Settings) error {
+		updated.FilesLocation = filesLocation
+		return nil
+	})
+}
+
+func (a *App) SetBackupSettings(backupJSON string) (settings.Settings, error) {
+	return a.updateSettings(func(updated *settings.Settings) error {
+		err := json.Unmarshal([]byte(backupJSON), &updated.Backup)
+		if err != nil {
+			return fmt.Errorf("invalid backup settings format: %w", err)
+		}
+
+		if updated.Backup.Directory != "" {
+			return ensureWritableDir(updated.Backup.Directory)
+		}
+		return nil
+	})
+}
+
+func (a *App) SetAPIServerSettings(enabled bool, port int) (settings.Settings, error) {
+	return a.updateSettings(func(updated *settings.Settings) error {
+		updated.Server.Enabled = enabled
+		updated.Server.Port = port
+		return nil
+	})
+}
+
+func (a *App) SetLockTimeout(lockTimeoutMs int) (settings.Settings, error) {
+	return a.updateSettings(func(updated *settings.Settings) error {
+		updated.LockTimeoutMs = lockTimeoutMs
+		return nil
+	})
+}
+
+func (a *App) SetUnitSystem(unitSystem string) (settings.Settings, error) {
+	return a.updateSettings(func(updated *settings.Settings) error {
+		updated.UnitSystem = unitSystem
+		return nil
+	})
+}
+
+func (a *App) SetStripPhotoMetadata(enabled bool) (settings.Settings, error) {
+	return a.updateSettings(func(updated *settings.Settings) error {
+		updated.Attachments.StripPhotoMetadata = enabled
+		return nil
+	})
+}
+
+func (a *App) currentSettings() settings.Settings {
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	return a.settings
+}
+
+// updateSettings applies change to a copy of the settings and saves it. The
+// settings stay locked throughout, so concurrent changes do not overwrite
+// each other.
+func (a *App) updateSettings(change func(updated *settings.Settings) error) (settings.Settings, error) {
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+
+	if a.appDataDir == "" {
+		return settings.Settings{}, fmt.Errorf("settings are not loaded")
+	}
+
+	updated := a.settings
+	if err := change(&updated); err != nil {
+		return settings.Settings{}, err
+	}
+
+	err := settings.Save(a.appDataDir, updated)
+	if err != nil {
+		return settings.Settings{}, err
+	}
+
+	a.settings = updated
+	return updated, nil
+}
+
+func ensureWritableDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("path '%s' must be absolute", dir)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("directory '%s' is not usable: %w", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory '%s' is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return nil
+}
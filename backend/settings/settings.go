@@ -0,0 +1,331 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const FileName = "settings.json"
+
+const (
+	DatabaseFileName    = "DataDesktop.db"
+	DevDatabaseFileName = "DataDesktop-dev.db"
+)
+
+const (
+	UnitSystemMetric   = "metric"
+	UnitSystemImperial = "imperial"
+)
+
+const (
+	DefaultLockTimeoutMs       = 5000
+	DefaultBackupIntervalHours = 24
+	DefaultBackupRetention     = 7
+	DefaultServerPort          = 47800
+)
+
+const (
+	EnvDevMode         = "DATADESKTOP_DEV"
+	EnvDatabasePath    = "DATADESKTOP_DB_PATH"
+	EnvFilesLocation   = "DATADESKTOP_FILES_LOCATION"
+	EnvLockTimeoutMs   = "DATADESKTOP_LOCK_TIMEOUT_MS"
+	EnvUnitSystem      = "DATADESKTOP_UNIT_SYSTEM"
+	EnvBackupDirectory = "DATADESKTOP_BACKUP_DIR"
+)
+
+type BackupSettings struct {
+	Enabled       bool   `json:"enabled"`
+	Directory     string `json:"directory,omitempty"`
+	IntervalHours int    `json:"intervalHours"`
+	Retention     int    `json:"retention"`
+}
+
+type ServerSettings struct {
+	Enabled bool `json:"enabled"`
+	Port    int  `json:"port"`
+}
+
+type AttachmentSettings struct {
+	StripPhotoMetadata bool `json:"stripPhotoMetadata"`
+}
+
+// EncryptionSettings never holds the passphrase or key, only what is needed
+// to derive the key again and recognise the right passphrase.
+type EncryptionSettings struct {
+	Enabled  bool   `json:"enabled"`
+	Salt     string `json:"salt,omitempty"`
+	KeyCheck string `json:"keyCheck,omitempty"`
+}
+
+type Settings struct {
+	DatabasePath  string             `json:"databasePath,omitempty"`
+	FilesLocation string             `json:"filesLocation,omitempty"`
+	Backup        BackupSettings     `json:"backup"`
+	Server        ServerSettings     `json:"server"`
+	Encryption    EncryptionSettings `json:"encryption"`
+	Attachments   AttachmentSettings `json:"attachments"`
+	LockTimeoutMs int                `json:"lockTimeoutMs"`
+	UnitSystem    string             `json:"unitSystem"`
+}
+
+type Overrides struct {
+	DevMode         bool
+	DatabasePath    string
+	FilesLocation   string
+	LockTimeoutMs   int
+	UnitSystem      string
+	BackupDirectory string
+}
+
+func Default() Settings {
+	return Settings{
+		Backup: BackupSettings{
+			Enabled:       false,
+			IntervalHours: DefaultBackupIntervalHours,
+			Retention:     DefaultBackupRetention,
+		},
+		Server: ServerSettings{
+			Enabled: false,
+			Port:    DefaultServerPort,
+		},
+		Attachments: AttachmentSettings{
+			StripPhotoMetadata: true,
+		},
+		LockTimeoutMs: DefaultLockTimeoutMs,
+		UnitSystem:    UnitSystemImperial,
+	}
+}
+
+func Path(appDataDir string) string {
+	return filepath.Join(appDataDir, FileName)
+}
+
+func Load(appDataDir string) (Settings, error) {
+	settings := Default()
+
+	data, err := os.ReadFile(Path(appDataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, Save(appDataDir, settings)
+		}
+		return Settings{}, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	err = json.Unmarshal(data, &settings)
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to parse settings: %w", err)
+	}
+
+	err = settings.Validate()
+	if err != nil {
+		return Settings{}, fmt.Errorf("invalid settings in %s: %w", Path(appDataDir), err)
+	}
+
+	return settings, nil
+}
+
+func Save(appDataDir string, settings Settings) error {
+	err := settings.Validate()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(appDataDir, 0755); err != nil {
+		return err
+	}
+
+	tempPath := Path(appDataDir) + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+
+	return os.Rename(tempPath, Path(appDataDir))
+}
+
+func (s Settings) Validate() error {
+	if s.DatabasePath != "" && !filepath.IsAbs(s.DatabasePath) {
+		return errors.New("database path must be an absolute path")
+	}
+
+	if s.FilesLocation != "" && !filepath.IsAbs(s.FilesLocation) {
+		return errors.New("files location must be an absolute path")
+	}
+
+	if s.Backup.Directory != "" && !filepath.IsAbs(s.Backup.Directory) {
+		return errors.New("backup directory must be an absolute path")
+	}
+
+	if s.Backup.IntervalHours < 1 {
+		return errors.New("backup interval must be at least 1 hour")
+	}
+
+	if s.Backup.Retention < 1 {
+		return errors.New("backup retention must keep at least 1 backup")
+	}
+
+	if s.Server.Port < 1024 || s.Server.Port > 65535 {
+		return errors.New("server port must be between 1024 and 65535")
+	}
+
+	if s.LockTimeoutMs < 0 || s.LockTimeoutMs > 600000 {
+		return errors.New("lock timeout must be between 0 and 600000 milliseconds")
+	}
+
+	if s.Encryption.Enabled && (s.Encryption.Salt == "" || s.Encryption.KeyCheck == "") {
+		return errors.New("encryption is enabled but no vault key has been set up")
+	}
+
+	if s.UnitSystem != UnitSystemMetric && s.UnitSystem != UnitSystemImperial {
+		return fmt.Errorf("unit system must be '%s' or '%s'", UnitSystemMetric, UnitSystemImperial)
+	}
+
+	return nil
+}
+
+func (s Settings) Resolve(appDataDir string, overrides Overrides) Settings {
+	if overrides.DatabasePath != "" {
+		s.DatabasePath = overrides.DatabasePath
+	}
+	if overrides.FilesLocation != "" {
+		s.FilesLocation = overrides.FilesLocation
+	}
+	if overrides.LockTimeoutMs > 0 {
+		s.LockTimeoutMs = overrides.LockTimeoutMs
+	}
+	if overrides.UnitSystem != "" {
+		s.UnitSystem = overrides.UnitSystem
+	}
+	if overrides.BackupDirectory != "" {
+		s.Backup.Directory = overrides.BackupDirectory
+	}
+
+	if s.DatabasePath == "" {
+		if overrides.DevMode {
+			s.DatabasePath = filepath.Join(appDataDir, DevDatabaseFileName)
+		} else {
+			s.DatabasePath = filepath.Join(appDataDir, DatabaseFileName)
+		}
+	}
+	if s.FilesLocation == "" {
+		s.FilesLocation = appDataDir
+	}
+	if s.Backup.Directory == "" {
+		s.Backup.Directory = filepath.Join(appDataDir, "backups")
+	}
+
+	return s
+}
+
+func OverridesFromEnv() (Overrides, error) {
+	overrides := Overrides{
+		DatabasePath:    os.Getenv(EnvDatabasePath),
+		FilesLocation:   os.Getenv(EnvFilesLocation),
+		UnitSystem:      os.Getenv(EnvUnitSystem),
+		BackupDirectory: os.Getenv(EnvBackupDirectory),
+	}
+
+	if value := os.Getenv(EnvDevMode); value != "" {
+		devMode, err := strconv.ParseBool(value)
+		if err != nil {
+			return Overrides{}, fmt.Errorf("invalid %s value '%s'", EnvDevMode, value)
+		}
+		overrides.DevMode = devMode
+	}
+
+	if value := os.Getenv(EnvLockTimeoutMs); value != "" {
+		lockTimeout, err := strconv.Atoi(value)
+		if err != nil {
+			return Overrides{}, fmt.Errorf("invalid %s value '%s'", EnvLockTimeoutMs, value)
+		}
+		overrides.LockTimeoutMs = lockTimeout
+	}
+
+	return overrides.normalize()
+}
+
+func ParseFlags(args []string) (Overrides, []string, error) {
+	var overrides Overrides
+
+	if len(args) > 0 && args[0] == "dev" {
+		overrides.DevMode = true
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet("DataDesktop", flag.ContinueOnError)
+	flags.BoolVar(&overrides.DevMode, "dev", overrides.DevMode, "use the development database and load sample data")
+	flags.StringVar(&overrides.DatabasePath, "db-path", "", "path to the SQLite database file")
+	flags.StringVar(&overrides.FilesLocation, "files-location", "", "directory that contains the files/ attachment folder")
+	flags.IntVar(&overrides.LockTimeoutMs, "lock-timeout-ms", 0, "how long to wait for a locked database, in milliseconds")
+	flags.StringVar(&overrides.UnitSystem, "unit-system", "", "default unit system (metric or imperial)")
+	flags.StringVar(&overrides.BackupDirectory, "backup-dir", "", "directory where backups are written")
+
+	if err := flags.Parse(args); err != nil {
+		return Overrides{}, nil, err
+	}
+
+	overrides, err := overrides.normalize()
+	if err != nil {
+		return Overrides{}, nil, err
+	}
+
+	return overrides, flags.Args(), nil
+}
+
+func (o Overrides) Merge(higher Overrides) Overrides {
+	if higher.DevMode {
+		o.DevMode = true
+	}
+	if higher.DatabasePath != "" {
+		o.DatabasePath = higher.DatabasePath
+	}
+	if higher.FilesLocation != "" {
+		o.FilesLocation = higher.FilesLocation
+	}
+	if higher.LockTimeoutMs > 0 {
+		o.LockTimeoutMs = higher.LockTimeoutMs
+	}
+	if higher.UnitSystem != "" {
+		o.UnitSystem = higher.UnitSystem
+	}
+	if higher.BackupDirectory != "" {
+		o.BackupDirectory = higher.BackupDirectory
+	}
+	return o
+}
+
+func (o Overrides) normalize() (Overrides, error) {
+	paths := []*string{&o.DatabasePath, &o.FilesLocation, &o.BackupDirectory}
+	for _, path := range paths {
+		if *path == "" {
+			continue
+		}
+
+		absPath, err := filepath.Abs(*path)
+		if err != nil {
+			return Overrides{}, err
+		}
+		*path = absPath
+	}
+
+	if o.LockTimeoutMs < 0 {
+		return Overrides{}, errors.New("lock timeout must not be negative")
+	}
+
+	o.UnitSystem = strings.ToLower(o.UnitSystem)
+	if o.UnitSystem != "" && o.UnitSystem != UnitSystemMetric && o.UnitSystem != UnitSystemImperial {
+		return Overrides{}, fmt.Errorf("unit system must be '%s' or '%s'", UnitSystemMetric, UnitSystemImperial)
+	}
+
+	return o, nil
+}
@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+)
+
+func (a *App) GetTags() ([]database.Tag, error) {
+	return database.ListTags()
+}
+
+// RenameTag renames a tag across all datasets and returns how many records
+// were changed.
+func (a *App) RenameTag(id string, name string) (int, error) {
+	changed := 0
+	err := a.journaled("Rename tag", func(op *database.Operation) ([]string, error) {
+		var err error
+		changed, err = database.RenameTag(op, id, name)
+		return nil, err
+	})
+	return changed, err
+}
+
+// MergeTags folds the tags in sourceIDsJSON, a JSON array of ids, into the
+// target tag.
+func (a *App) MergeTags(targetID string, sourceIDsJSON string) (int, error) {
+	var sourceIDs []string
+	err := json.Unmarshal([]byte(sourceIDsJSON), &sourceIDs)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tag ids: %w", err)
+	}
+
+	changed := 0
+	err = a.journaled("Merge tags", func(op *database.Operation) ([]string, error) {
+		changed, err = database.MergeTags(op, targetID, sourceIDs)
+		return nil, err
+	})
+	return changed, err
+}
+
+func (a *App) DeleteTag(id string) (int, error) {
+	changed := 0
+	err := a.journaled("Delete tag", func(op *database.Operation) ([]string, error) {
+		var err error
+		changed, err = database.DeleteTag(op, id)
+		return nil, err
+	})
+	return changed, err
+}
@@ -0,0 +1,27 @@
+package backend
+
+import (
+	"log"
+	"myproject/backend/file"
+)
+
+func (a *App) GetThumbnail(relativePath string, size string) (string, error) {
+	return file.Thumbnail(a.filesRoot, relativePath, size)
+}
+
+func (a *App) GetThumbnails(relativePath string) (map[string]string, error) {
+	return file.GenerateThumbnails(a.filesRoot, relativePath)
+}
+
+func (a *App) pregenerateThumbnails(relativePath string) {
+	if !file.IsThumbnailable(relativePath) {
+		return
+	}
+
+	go func() {
+		_, err := file.GenerateThumbnails(a.filesRoot, relativePath)
+		if err != nil {
+			log.Printf("Error generating thumbnails for %s: %v", relativePath, err)
+		}
+	}()
+}
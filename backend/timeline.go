@@ -0,0 +1,15 @@
+package backend
+
+import "myproject/backend/database"
+
+// GetTimeline returns one page of the records of the given datasets, or of
+// all datasets, between from and to, ordered by each dataset's primary date.
+func (a *App) GetTimeline(from string, to string, datasetIDs []string, offset int, limit int) (database.TimelinePage, error) {
+	return database.QueryTimeline(database.TimelineQuery{
+		From:       from,
+		To:         to,
+		DatasetIDs: datasetIDs,
+		Offset:     offset,
+		Limit:      limit,
+	})
+}
@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"fmt"
+	"myproject/backend/file"
+	"time"
+)
+
+const staleUploadAge = 24 * time.Hour
+
+func (a *App) BeginUpload(fileName string, size int64, chunkSize int64, sha256 string) (file.UploadSession, error) {
+	return file.BeginUpload(a.filesRoot, fileName, size, chunkSize, sha256)
+}
+
+func (a *App) UploadChunk(sessionID string, index int, chunkData string, chunkSHA256 string) (file.UploadStatus, error) {
+	return file.WriteChunk(a.filesRoot, sessionID, index, chunkData, chunkSHA256)
+}
+
+func (a *App) GetUploadStatus(sessionID string) (file.UploadStatus, error) {
+	return file.GetUploadStatus(a.filesRoot, sessionID)
+}
+
+func (a *App) FinishUpload(sessionID string) (string, error) {
+	status, err := file.GetUploadStatus(a.filesRoot, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	saved, err := file.FinishUpload(a.filesRoot, sessionID, a.currentSettings().Attachments.StripPhotoMetadata)
+	if err != nil {
+		return "", err
+	}
+	relativePath := saved.Path
+
+	err = a.registerFile(relativePath, status.Session.FileName, saved.Image)
+	if err != nil {
+		return "", fmt.Errorf("failed to register file: %w", err)
+	}
+
+	a.pregenerateThumbnails(relativePath)
+
+	return relativePath, nil
+}
+
+func (a *App) AbortUpload(sessionID string) error {
+	return file.AbortUpload(a.filesRoot, sessionID)
+}
@@ -0,0 +1,119 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"myproject/backend/database"
+)
+
+type ViewGroup struct {
+	Value   interface{}              `json:"value"`
+	Count   int                      `json:"count"`
+	Records []map[string]interface{} `json:"records"`
+}
+
+// ViewResult holds a view's records, or its groups when the view groups by a
+// field. Groups keep the view's sort order, by their first record.
+type ViewResult struct {
+	View    database.SavedView       `json:"view"`
+	Records []map[string]interface{} `json:"records,omitempty"`
+	Groups  []ViewGroup              `json:"groups,omitempty"`
+	Total   int                      `json:"total"`
+}
+
+// viewMetadata are the record keys kept whatever columns a view shows.
+var viewMetadata = []string{"id", "datasetId", "createdAt", "lastModified"}
+
+func (a *App) GetViews(datasetID string) ([]database.SavedView, error) {
+	return database.ListViews(datasetID)
+}
+
+func (a *App) GetView(id string) (database.SavedView, error) {
+	return database.GetView(id)
+}
+
+func (a *App) CreateView(viewJSON string) (database.SavedView, error) {
+	var view database.SavedView
+	err := json.Unmarshal([]byte(viewJSON), &view)
+	if err != nil {
+		return database.SavedView{}, fmt.Errorf("invalid view format: %w", err)
+	}
+
+	view.ID = ""
+	return database.CreateView(view)
+}
+
+func (a *App) UpdateView(id string, viewJSON string) (database.SavedView, error) {
+	view, err := database.GetView(id)
+	if err != nil {
+		return database.SavedView{}, err
+	}
+
+	err = json.Unmarshal([]byte(viewJSON), &view)
+	if err != nil {
+		return database.SavedView{}, fmt.Errorf("invalid view format: %w", err)
+	}
+
+	view.ID = id
+	return database.UpdateView(view)
+}
+
+func (a *App) DeleteView(id string) error {
+	return database.DeleteView(id)
+}
+
+func (a *App) RunView(viewID string) (ViewResult, error) {
+	view, err := database.GetView(viewID)
+	if err != nil {
+		return ViewResult{}, err
+	}
+
+	records, err := database.QueryDataRecords(view.DatasetID, view.Query())
+	if err != nil {
+		return ViewResult{}, fmt.Errorf("view %s: %w", view.Name, err)
+	}
+
+	maps, err := recordMaps(records)
+	if err != nil {
+		return ViewResult{}, err
+	}
+
+	result := ViewResult{View: view, Total: len(maps)}
+	if view.GroupBy == "" {
+		for i, record := range maps {
+			maps[i] = projectRecord(record, view.Columns)
+		}
+		result.Records = maps
+		return result, nil
+	}
+
+	index := make(map[string]int)
+	for _, record := range maps {
+		value := record[view.GroupBy]
+		key := fmt.Sprintf("%v", value)
+		position, ok := index[key]
+		if !ok {
+			position = len(result.Groups)
+			index[key] = position
+			result.Groups = append(result.Groups, ViewGroup{Value: value})
+		}
+		result.Groups[position].Count++
+		result.Groups[position].Records = append(result.Groups[position].Records, projectRecord(record, view.Columns))
+	}
+
+	return result, nil
+}
+
+func projectRecord(record map[string]interface{}, columns []string) map[string]interface{} {
+	if len(columns) == 0 {
+		return record
+	}
+
+	projected := make(map[string]interface{}, len(columns)+len(viewMetadata))
+	for _, key := range append(viewMetadata, columns...) {
+		if value, ok := record[key]; ok {
+			projected[key] = value
+		}
+	}
+	return projected
+}
@@ -42,6 +42,7 @@ import MultipleFileUploadField from "./multiple-file-upload-field";
 import AutocompleteInput from "@/components/reusable/autocomplete-input";
 import dataStore from "@/store/data-store";
 import { useStore } from "@tanstack/react-store";
+import { isAppError, UniqueViolationDetails } from "@/lib/app-error";
 
 export default function DataForm({
   datasetId,
@@ -696,22 +697,15 @@ export default function DataForm({
         error
       );
 
-      const errorMessage =
-        error instanceof Error ? error.message : String(error);
+      if (isAppError(error) && error.code === "unique_violation") {
+        const details = error.details as UniqueViolationDetails | undefined;
+        const field = fields.find((f) => f.key === details?.field);
 
-      if (errorMessage.includes("must be unique")) {
-        const fieldMatch = errorMessage.match(/field '([^']+)' must be unique/);
-        const fieldName = fieldMatch ? fieldMatch[1] : null;
-
-        if (fieldName) {
-          const field = fields.find((f) => f.displayName === fieldName);
-
-          if (field) {
-            form.setError(field.key, {
-              type: "unique",
-              message: `This ${field.displayName.toLowerCase()} already exists. Please choose a different value.`,
-            });
-          }
+        if (field) {
+          form.setError(field.key, {
+            type: "unique",
+            message: `This ${field.displayName.toLowerCase()} already exists. Please choose a different value.`,
+          });
         }
 
         toast.error("Please fix the unique field conflicts and try again");
@@ -12,6 +12,7 @@ import { DataStoreName } from "@/store/data-store";
 import useLoadData from "@/hooks/useLoadData";
 import ReusableDialog from "@/components/reusable/reusable-dialog";
 import ReusableCard from "@/components/reusable/reusable-card";
+import { getErrorMessage } from "@/lib/app-error";
 
 export function CSVImportProcessor({
   datasetId,
@@ -126,7 +127,7 @@ export function CSVImportProcessor({
         console.error(`Error importing chunk ${i / chunkSize + 1}:`, error);
         failed += chunk.length;
         errors.push(
-          `Chunk ${i / chunkSize + 1}: ${error instanceof Error ? error.message : "Unknown error"}`
+          `Chunk ${i / chunkSize + 1}: ${getErrorMessage(error)}`
         );
       }
 
@@ -1,4 +1,4 @@
-import { useCallback, useEffect, useState } from "react";
+import { useCallback, useMemo } from "react";
 import {
   FileIcon,
   Download,
@@ -26,32 +26,25 @@ export default function FileViewer({
   size = "md",
   showDownloadButton = false,
 }: FileViewerProps) {
-  const [fileUrl, setFileUrl] = useState<string | null>(null);
-  const [loading, setLoading] = useState(false);
-
-  useEffect(() => {
-    const loadFile = async () => {
-      if (src.startsWith("file://") || src.startsWith("files/")) {
-        setLoading(true);
-        try {
-          const filePath = src.startsWith("file://") ? src.substring(7) : src;
-          const base64Data = await ApiService.getFile(filePath);
-
-          setFileUrl(base64Data);
-        } catch (error) {
-          console.error("Failed to load file:", error);
-          setFileUrl(null);
-        } finally {
-          setLoading(false);
-        }
-      } else {
-        setFileUrl(src);
-      }
-    };
-
-    loadFile();
+  const fileUrl = useMemo(() => {
+    if (src.startsWith("file://") || src.startsWith("files/")) {
+      const filePath = src.startsWith("file://") ? src.substring(7) : src;
+      return ApiService.getFileUrl(filePath);
+    }
+    return src;
   }, [src]);
 
+  const thumbnailUrl = useMemo(() => {
+    if (src.startsWith("file://") || src.startsWith("files/")) {
+      const filePath = src.startsWith("file://") ? src.substring(7) : src;
+      return ApiService.getThumbnailUrl(
+        filePath,
+        size === "lg" ? "medium" : "small"
+      );
+    }
+    return src;
+  }, [src, size]);
+
   const handleDownload = useCallback(() => {
     const isFilePath = src.startsWith("file://") || src.startsWith("files/");
     const displayUrl = src.startsWith("file://") ? src.substring(7) : src;
@@ -104,14 +97,6 @@ export default function FileViewer({
     );
   }
 
-  if (loading) {
-    return (
-      <div className="flex items-center justify-center bg-muted rounded-md w-10 h-10">
-        <div className="animate-spin h-4 w-4 border-2 border-primary border-t-transparent rounded-full" />
-      </div>
-    );
-  }
-
   const sizeClasses = {
     sm: "w-8 h-8",
     md: "w-10 h-10",
@@ -164,7 +149,7 @@ export default function FileViewer({
     if (isImage && displaySrc)
       return (
         <img
-          src={displaySrc}
+          src={thumbnailUrl}
           alt={fileName}
           className="w-full h-full object-cover"
         />
@@ -179,7 +164,7 @@ export default function FileViewer({
       case "image":
         return displaySrc ? (
           <img
-            src={displaySrc}
+            src={thumbnailUrl}
             alt={fileName}
             className="max-h-32 max-w-32 object-contain"
           />
@@ -1,4 +1,4 @@
-import React, { useRef, useState, useEffect } from "react";
+import React, { useRef, useState } from "react";
 import { Button } from "@/components/ui/button";
 import {
   X,
@@ -14,6 +14,7 @@ import { cn } from "@/lib/utils";
 import { ApiService } from "@/services/api";
 import useChunkedFileUpload from "@/hooks/useChunkedFileUpload";
 import { Progress } from "@/components/ui/progress";
+import { getErrorMessage } from "@/lib/app-error";
 
 export interface FileItem {
   id: string;
@@ -121,7 +122,7 @@ export default function MultipleFileUpload({
 
       onChange(newFiles);
     } catch (err: unknown) {
-      setError(err instanceof Error ? err.message : String(err));
+      setError(getErrorMessage(err));
       console.error("File upload error:", err);
     } finally {
       setIsLoading(false);
@@ -199,40 +200,13 @@ export default function MultipleFileUpload({
   };
 
   const FileImageDisplay = ({ file }: { file: FileItem }) => {
-    const [imageUrl, setImageUrl] = useState<string | null>(null);
-    const [loading, setLoading] = useState(false);
-
-    useEffect(() => {
-      const loadImage = async () => {
-        if (file.src.startsWith("file://") || file.src.startsWith("files/")) {
-          setLoading(true);
-          try {
-            const filePath = file.src.startsWith("file://")
-              ? file.src.substring(7)
-              : file.src;
-            const base64Data = await ApiService.getFile(filePath);
-            setImageUrl(base64Data);
-          } catch (error) {
-            console.error("Failed to load image:", error);
-            setImageUrl(null);
-          } finally {
-            setLoading(false);
-          }
-        } else {
-          setImageUrl(file.src);
-        }
-      };
-
-      loadImage();
-    }, [file.src]);
-
-    if (loading) {
-      return (
-        <div className="h-12 w-12 bg-muted rounded flex items-center justify-center">
-          <div className="animate-spin h-4 w-4 border-2 border-primary border-t-transparent rounded-full" />
-        </div>
-      );
-    }
+    const imageUrl =
+      file.src.startsWith("file://") || file.src.startsWith("files/")
+        ? ApiService.getThumbnailUrl(
+            file.src.startsWith("file://") ? file.src.substring(7) : file.src,
+            "small"
+          )
+        : file.src;
 
     return (
       <img
@@ -11,8 +11,36 @@ import { Pencil, Loader2, Trash } from "lucide-react";
 import DexaGoalForm from "./dexa-goal-form";
 import { GoalStorageService } from "./goal-storage-service";
 import { ConfirmDeleteDialog } from "@/components/reusable/confirm-delete-dialog";
+import { ApiService } from "@/services/api";
+import { analytics } from "wailsjs/go/models";
+import { format } from "date-fns";
 import { DexaGoal } from "../dexa";
 
+type GoalForecasts = {
+  bodyFat?: analytics.Forecast | null;
+  weight?: analytics.Forecast | null;
+  vat?: analytics.Forecast | null;
+};
+
+function ForecastNote({ forecast }: { forecast?: analytics.Forecast | null }) {
+  if (!forecast || forecast.reached) {
+    return null;
+  }
+
+  const formatDate = (date: string) => format(new Date(date), "MMM yyyy");
+  let text = "Not on track at the current pace";
+  if (forecast.date) {
+    text = `On track for ${formatDate(forecast.date)}`;
+    if (forecast.earliest) {
+      text += ` (${formatDate(forecast.earliest)} – ${
+        forecast.latest ? formatDate(forecast.latest) : "later"
+      })`;
+    }
+  }
+
+  return <p className="text-xs text-muted-foreground mt-2">{text}</p>;
+}
+
 export default function DexaGoalDisplay({
   latestScan,
   onGoalChange,
@@ -29,6 +57,7 @@ export default function DexaGoalDisplay({
   const [isLoading, setIsLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
   const [isEditing, setIsEditing] = useState(false);
+  const [forecasts, setForecasts] = useState<GoalForecasts>({});
 
   const loadGoal = () => {
     setIsLoading(true);
@@ -49,6 +78,36 @@ export default function DexaGoalDisplay({
     loadGoal();
   }, []);
 
+  // Scans may store body fat as a fraction rather than a percentage.
+  const bodyFatIsFraction =
+    !!latestScan && latestScan.total_body_fat_percentage < 1;
+
+  useEffect(() => {
+    if (!goal) {
+      setForecasts({});
+      return;
+    }
+
+    const bodyFatTarget = bodyFatIsFraction
+      ? goal.bodyFatPercent / 100
+      : goal.bodyFatPercent;
+
+    Promise.all([
+      ApiService.forecastGoal({
+        series: { kind: "body_fat" },
+        target: bodyFatTarget,
+      }),
+      ApiService.forecastGoal({
+        series: { kind: "weight" },
+        target: goal.totalWeightLbs,
+      }),
+      ApiService.forecastGoal({
+        series: { datasetId: "dexa", field: "vat_mass_lbs" },
+        target: goal.vatMassLbs,
+      }),
+    ]).then(([bodyFat, weight, vat]) => setForecasts({ bodyFat, weight, vat }));
+  }, [goal, bodyFatIsFraction]);
+
   const handleGoalSuccess = () => {
     setIsEditing(false);
     loadGoal();
@@ -197,6 +256,7 @@ export default function DexaGoalDisplay({
                 ></div>
               </div>
             )}
+            <ForecastNote forecast={forecasts.bodyFat} />
           </div>
 
           <div className="p-4 rounded-lg bg-muted">
@@ -217,6 +277,7 @@ export default function DexaGoalDisplay({
                 ></div>
               </div>
             )}
+            <ForecastNote forecast={forecasts.weight} />
           </div>
 
           <div className="p-4 rounded-lg bg-muted">
@@ -237,6 +298,7 @@ export default function DexaGoalDisplay({
                 ></div>
               </div>
             )}
+            <ForecastNote forecast={forecasts.vat} />
           </div>
         </div>
       </CardContent>
@@ -39,7 +39,7 @@ export const PEOPLE_FIELD_DEFINITIONS: FieldDefinitionsDataset = {
     },
     {
       key: "tags",
-      type: "text",
+      type: "tags",
       displayName: "Tags",
       description: "Comma-separated tags",
       isOptional: true,
@@ -121,7 +121,7 @@ export const MEETINGS_FIELD_DEFINITIONS: FieldDefinitionsDataset = {
     },
     {
       key: "tags",
-      type: "text",
+      type: "tags",
       displayName: "Tags",
       description: "Comma-separated tags",
       isOptional: true,
@@ -276,7 +276,7 @@ export const PERSON_NOTES_FIELD_DEFINITIONS: FieldDefinitionsDataset = {
     },
     {
       key: "tags",
-      type: "text",
+      type: "tags",
       displayName: "Tags",
       description: "Comma-separated tags",
       isOptional: true,
@@ -52,7 +52,7 @@ export const TIME_ENTRIES_FIELD_DEFINITIONS: FieldDefinitionsDataset = {
     ),
     {
       key: "tags",
-      type: "text",
+      type: "tags",
       displayName: "Tags",
       description: "Comma-separated tags for the activity",
       isSearchable: true,
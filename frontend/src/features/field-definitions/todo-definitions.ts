@@ -44,7 +44,7 @@ export const TODO_FIELD_DEFINITIONS: FieldDefinitionsDataset = {
     },
     {
       key: "tags",
-      type: "text",
+      type: "tags",
       displayName: "Tags",
       description: "Comma-separated tags for categorization",
       isOptional: true,
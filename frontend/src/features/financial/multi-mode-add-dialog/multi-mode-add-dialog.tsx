@@ -24,6 +24,7 @@ import { DataStoreName } from "@/store/data-store";
 import MultiEntryTable from "./multi-entry-table";
 import { Alert, AlertDescription } from "@/components/ui/alert";
 import { parseCSV, createCSVTemplate, validateCSV } from "@/lib/csv-parser";
+import { getErrorMessage } from "@/lib/app-error";
 
 export default function MultiModeAddDialog({
   open,
@@ -194,7 +195,7 @@ export default function MultiModeAddDialog({
     } catch (error) {
       console.error("Error importing CSV:", error);
       toast.error("Failed to import CSV file", {
-        description: error instanceof Error ? error.message : "Unknown error",
+        description: getErrorMessage(error),
       });
     } finally {
       setIsUploadingCSV(false);
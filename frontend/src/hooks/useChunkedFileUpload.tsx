@@ -1,7 +1,6 @@
 import { useState } from "react";
 import { ApiService } from "@/services/api";
 import { toast } from "sonner";
-import { v4 as uuidv4 } from "uuid";
 
 interface ChunkedUploadOptions {
   chunkSize?: number; // in bytes
@@ -18,6 +17,31 @@ interface ChunkedUploadResult {
   ) => Promise<string | null>;
 }
 
+const MAX_CHUNK_ATTEMPTS = 3;
+// Hashing the whole file needs it in memory, so bigger files rely on the
+// per-chunk checksums alone.
+const MAX_WHOLE_FILE_HASH_BYTES = 64 * 1024 * 1024;
+
+const toHex = (buffer: ArrayBuffer) =>
+  Array.from(new Uint8Array(buffer))
+    .map((b) => b.toString(16).padStart(2, "0"))
+    .join("");
+
+const sha256 = async (data: ArrayBuffer) =>
+  toHex(await crypto.subtle.digest("SHA-256", data));
+
+const toBase64 = (data: ArrayBuffer) => {
+  const bytes = new Uint8Array(data);
+  let binary = "";
+  for (let i = 0; i < bytes.length; i += 0x8000) {
+    binary += String.fromCharCode(...bytes.subarray(i, i + 0x8000));
+  }
+  return btoa(binary);
+};
+
+const resumeKey = (file: File) =>
+  `upload:${file.name}:${file.size}:${file.lastModified}`;
+
 export default function useChunkedFileUpload(): ChunkedUploadResult {
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
@@ -31,8 +55,7 @@ export default function useChunkedFileUpload(): ChunkedUploadResult {
     setError(null);
 
     const chunkSize = options?.chunkSize || 1024 * 1024; // 1MB default
-    const totalChunks = Math.ceil(file.size / chunkSize);
-    const sessionId = uuidv4();
+    const key = resumeKey(file);
 
     try {
       if (file.size < chunkSize) {
@@ -57,41 +80,73 @@ export default function useChunkedFileUpload(): ChunkedUploadResult {
         return result;
       }
 
-      for (let i = 0; i < totalChunks; i++) {
-        const start = i * chunkSize;
-        const end = Math.min(file.size, start + chunkSize);
-        const chunk = file.slice(start, end);
+      let status: Awaited<ReturnType<typeof ApiService.getUploadStatus>> =
+        null;
 
-        const reader = new FileReader();
-        const base64Promise = new Promise<string>((resolve, reject) => {
-          reader.onload = () => resolve(reader.result as string);
-          reader.onerror = reject;
-          reader.readAsDataURL(chunk);
-        });
-
-        const base64Data = await base64Promise;
+      const previousSession = localStorage.getItem(key);
+      if (previousSession) {
+        status = await ApiService.getUploadStatus(previousSession);
+        if (status && status.session.chunkSize !== chunkSize) {
+          await ApiService.abortUpload(previousSession);
+          status = null;
+        }
+      }
 
-        const result = await ApiService.uploadFileChunk(
-          base64Data,
+      if (!status) {
+        const wholeFileHash =
+          file.size <= MAX_WHOLE_FILE_HASH_BYTES
+            ? await sha256(await file.arrayBuffer())
+            : "";
+        const session = await ApiService.beginUpload(
           file.name,
-          i,
-          totalChunks,
-          sessionId
+          file.size,
+          chunkSize,
+          wholeFileHash
         );
+        localStorage.setItem(key, session.id);
+        status = await ApiService.getUploadStatus(session.id);
+        if (!status) {
+          throw new Error("Failed to start upload");
+        }
+      }
 
-        if (options?.onProgress) {
-          options.onProgress(Math.round(((i + 1) / totalChunks) * 100));
+      const sessionId = status.session.id;
+      const totalChunks = status.session.totalChunks;
+      let received = status.received;
+
+      for (const index of status.missing) {
+        const start = index * chunkSize;
+        const chunk = await file
+          .slice(start, Math.min(file.size, start + chunkSize))
+          .arrayBuffer();
+        const chunkHash = await sha256(chunk);
+        const chunkData = toBase64(chunk);
+
+        for (let attempt = 1; ; attempt++) {
+          try {
+            await ApiService.uploadChunk(sessionId, index, chunkData, chunkHash);
+            break;
+          } catch (err) {
+            if (attempt >= MAX_CHUNK_ATTEMPTS) {
+              throw err;
+            }
+          }
         }
 
-        if (i === totalChunks - 1 && result) {
-          return result;
+        received++;
+        if (options?.onProgress) {
+          options.onProgress(Math.round((received / totalChunks) * 100));
         }
       }
 
-      throw new Error("Failed to complete chunked upload");
+      const result = await ApiService.finishUpload(sessionId);
+      localStorage.removeItem(key);
+      return result;
     } catch (err: any) {
-      setError(err.message);
-      toast.error(`File upload failed: ${err.message}`);
+      localStorage.removeItem(key);
+      const message = err?.message || String(err);
+      setError(message);
+      toast.error(`File upload failed: ${message}`);
       return null;
     } finally {
       setLoading(false);
@@ -0,0 +1,53 @@
+// Errors returned by Go App methods. The backend rejects with this shape
+// instead of a plain string, see backend.FormatError.
+export type AppErrorCode =
+  | "not_found"
+  | "unique_violation"
+  | "referenced"
+  | "validation"
+  | "conflict"
+  | "internal";
+
+export interface AppError {
+  code: AppErrorCode;
+  message: string;
+  details?: Record<string, any>;
+}
+
+export interface UniqueViolationDetails {
+  datasetId: string;
+  field: string;
+  fieldName: string;
+  value: string;
+  conflictingId: string;
+}
+
+export interface ReferencedDetails {
+  resource: string;
+  id: string;
+  references: { datasetId: string; recordId: string; field: string }[];
+}
+
+export interface ValidationDetails {
+  fields: { field: string; message: string }[];
+}
+
+export function isAppError(error: unknown): error is AppError {
+  return (
+    typeof error === "object" &&
+    error !== null &&
+    typeof (error as AppError).code === "string" &&
+    typeof (error as AppError).message === "string"
+  );
+}
+
+export function hasErrorCode(error: unknown, code: AppErrorCode): boolean {
+  return isAppError(error) && error.code === code;
+}
+
+export function getErrorMessage(error: unknown): string {
+  if (isAppError(error) || error instanceof Error) {
+    return error.message;
+  }
+  return String(error);
+}
@@ -10,16 +10,26 @@ import {
   GetRecord,
   GetRecords,
   GetRecordsWithRelations,
+  PreviewDelete,
+  Undo,
+  Redo,
   ImportRecords,
   UpdateDataset,
   UpdateRecord,
-  UploadFileChunk,
+  BeginUpload,
+  UploadChunk,
+  GetUploadStatus,
+  FinishUpload,
+  AbortUpload,
   UploadFile,
   GetFileAsBase64,
   DeleteFile,
+  AnalyzeSeries,
+  ForecastGoal,
 } from "../../wailsjs/go/backend/App";
-import { database } from "wailsjs/go/models";
+import { analytics, database, file } from "wailsjs/go/models";
 import { toast } from "sonner";
+import { getErrorMessage, hasErrorCode } from "@/lib/app-error";
 
 export const ApiService = {
   async getDatasets(): Promise<database.Dataset[]> {
@@ -88,8 +98,7 @@ export const ApiService = {
     } catch (error: any) {
       console.error(`Failed to delete dataset ${id}:`, error);
 
-      const errorMessage = error.toString();
-      if (errorMessage.includes("referenced by other records")) {
+      if (hasErrorCode(error, "referenced")) {
         toast.error(
           "Cannot delete this dataset because it contains records used by other records"
         );
@@ -159,9 +168,7 @@ export const ApiService = {
     } catch (error) {
       console.error(`Failed to add record to dataset ${datasetId}:`, error);
 
-      const errorMessage =
-        error instanceof Error ? error.message : String(error);
-      if (errorMessage.includes("must be unique")) {
+      if (hasErrorCode(error, "unique_violation")) {
         throw error;
       }
 
@@ -188,9 +195,7 @@ export const ApiService = {
     } catch (error) {
       console.error(`Failed to update record ${id}:`, error);
 
-      const errorMessage =
-        error instanceof Error ? error.message : String(error);
-      if (errorMessage.includes("must be unique")) {
+      if (hasErrorCode(error, "unique_violation")) {
         throw error;
       }
 
@@ -206,8 +211,7 @@ export const ApiService = {
     } catch (error: any) {
       console.error(`Failed to delete record ${id}:`, error);
 
-      const errorMessage = error.toString();
-      if (errorMessage.includes("referenced by other records")) {
+      if (hasErrorCode(error, "referenced")) {
         toast.error(
           "Cannot delete this record because it is used by other records"
         );
@@ -219,6 +223,67 @@ export const ApiService = {
     }
   },
 
+  async previewDelete(id: string): Promise<database.DeleteImpact | null> {
+    try {
+      return await PreviewDelete(id);
+    } catch (error) {
+      console.error(`Failed to preview deleting record ${id}:`, error);
+      return null;
+    }
+  },
+
+  async undo(): Promise<database.JournalEntry | null> {
+    try {
+      return await Undo();
+    } catch (error) {
+      console.error("Failed to undo:", error);
+      toast.error(getErrorMessage(error));
+      return null;
+    }
+  },
+
+  async redo(): Promise<database.JournalEntry | null> {
+    try {
+      return await Redo();
+    } catch (error) {
+      console.error("Failed to redo:", error);
+      toast.error(getErrorMessage(error));
+      return null;
+    }
+  },
+
+  async analyzeSeries(
+    series: Partial<analytics.SeriesQuery>,
+    options: {
+      window?: number;
+      alpha?: number;
+      period?: "week" | "month";
+      percentiles?: number[];
+    } = {},
+  ): Promise<analytics.Analysis | null> {
+    try {
+      return await AnalyzeSeries(JSON.stringify(series), JSON.stringify(options));
+    } catch (error) {
+      console.error("Failed to analyze series:", error);
+      return null;
+    }
+  },
+
+  async forecastGoal(goal: {
+    series: Partial<analytics.SeriesQuery>;
+    target?: number;
+    direction?: "up" | "down";
+    method?: "linear" | "holt";
+    horizonDays?: number;
+  }): Promise<analytics.Forecast | null> {
+    try {
+      return await ForecastGoal(JSON.stringify(goal));
+    } catch (error) {
+      console.error("Failed to forecast goal:", error);
+      return null;
+    }
+  },
+
   async checkForDuplicates(
     datasetId: string,
     records: Record<string, any>[],
@@ -265,6 +330,15 @@ export const ApiService = {
     }
   },
 
+  getFileUrl(filePath: string): string {
+    const normalized = filePath.replace(/\\/g, "/").replace(/^\/+/, "");
+    return "/" + normalized.split("/").map(encodeURIComponent).join("/");
+  },
+
+  getThumbnailUrl(filePath: string, size: "small" | "medium" | "large"): string {
+    return `${this.getFileUrl(filePath)}?size=${size}`;
+  },
+
   async getFile(filePath: string): Promise<string | null> {
     try {
       if (!filePath) return null;
@@ -290,14 +364,8 @@ export const ApiService = {
 
   async downloadFile(filePath: string, fileName: string): Promise<void> {
     try {
-      const fileData = await this.getFile(filePath);
-      if (!fileData) {
-        toast.error("File not found");
-        return;
-      }
-
       const link = document.createElement("a");
-      link.href = fileData;
+      link.href = this.getFileUrl(filePath);
       link.download = fileName || "download";
       document.body.appendChild(link);
       link.click();
@@ -308,26 +376,41 @@ export const ApiService = {
     }
   },
 
-  async uploadFileChunk(
-    chunkData: string,
+  async beginUpload(
     fileName: string,
-    chunkIndex: number,
-    totalChunks: number,
-    sessionId: string
-  ): Promise<string | null> {
+    size: number,
+    chunkSize: number,
+    sha256: string
+  ): Promise<file.UploadSession> {
+    return BeginUpload(fileName, size, chunkSize, sha256);
+  },
+
+  async uploadChunk(
+    sessionId: string,
+    index: number,
+    chunkData: string,
+    chunkSha256: string
+  ): Promise<file.UploadStatus> {
+    return UploadChunk(sessionId, index, chunkData, chunkSha256);
+  },
+
+  async getUploadStatus(sessionId: string): Promise<file.UploadStatus | null> {
     try {
-      const result = await UploadFileChunk(
-        chunkData,
-        fileName,
-        chunkIndex,
-        totalChunks,
-        sessionId
-      );
-      return result;
-    } catch (error) {
-      console.error("Failed to upload file chunk:", error);
-      toast.error("Failed to upload file chunk");
+      return await GetUploadStatus(sessionId);
+    } catch {
       return null;
     }
   },
+
+  async finishUpload(sessionId: string): Promise<string> {
+    return FinishUpload(sessionId);
+  },
+
+  async abortUpload(sessionId: string): Promise<void> {
+    try {
+      await AbortUpload(sessionId);
+    } catch (error) {
+      console.error(`Failed to abort upload ${sessionId}:`, error);
+    }
+  },
 };
@@ -261,6 +261,7 @@ export interface FieldDefinition {
   isSearchable?: boolean;
   isOptional?: boolean;
   isUnique?: boolean;
+  isIndexed?: boolean;
   isRelation?: boolean;
   relatedDataset?: DatasetId;
   relatedField?: string;
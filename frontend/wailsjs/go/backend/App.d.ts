@@ -1,54 +1,199 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {analytics} from '../models';
+import {file} from '../models';
 import {backend} from '../models';
 import {database} from '../models';
+import {backup} from '../models';
+import {server} from '../models';
+import {digest} from '../models';
+import {settings} from '../models';
+import {integrity} from '../models';
+
+export function AbortUpload(arg1:string):Promise<void>;
 
 export function AddRecord(arg1:string,arg2:string,arg3:boolean):Promise<Record<string, any>>;
 
+export function AnalyzeSeries(arg1:string,arg2:string):Promise<analytics.Analysis>;
+
+export function BeginUpload(arg1:string,arg2:number,arg3:number,arg4:string):Promise<file.UploadSession>;
+
 export function CheckForDuplicates(arg1:string,arg2:string,arg3:Array<string>):Promise<Array<backend.DuplicateResult>>;
 
+export function CleanupGeneratedData():Promise<database.GeneratedCleanup>;
+
+export function CreateAPIToken(arg1:string,arg2:boolean):Promise<database.CreatedAPIToken>;
+
+export function CreateBackup():Promise<backup.Info>;
+
+export function CreateBackupIn(arg1:string):Promise<backup.Info>;
+
 export function CreateDataset(arg1:string,arg2:string,arg3:string,arg4:string):Promise<database.Dataset>;
 
+export function CreateRule(arg1:string):Promise<database.Rule>;
+
+export function CreateView(arg1:string):Promise<database.SavedView>;
+
 export function DeleteDataset(arg1:string):Promise<void>;
 
 export function DeleteFile(arg1:string):Promise<void>;
 
 export function DeleteRecord(arg1:string):Promise<void>;
 
+export function DeleteRecordsMatching(arg1:string,arg2:string):Promise<number>;
+
+export function DeleteRule(arg1:string):Promise<void>;
+
+export function DeleteTag(arg1:string):Promise<number>;
+
+export function DeleteView(arg1:string):Promise<void>;
+
+export function DisableEncryption(arg1:string):Promise<backend.EncryptionStatus>;
+
+export function EnableEncryption(arg1:string):Promise<backend.EncryptionStatus>;
+
+export function FinishUpload(arg1:string):Promise<string>;
+
+export function ForecastGoal(arg1:string):Promise<analytics.Forecast>;
+
+export function GenerateData(arg1:string):Promise<database.GeneratedBatch>;
+
+export function GetAPIServerStatus():Promise<server.Status>;
+
+export function GetDailyDigest(arg1:string):Promise<digest.Digest>;
+
+export function GetDailyDigestMarkdown(arg1:string):Promise<string>;
+
 export function GetDataset(arg1:string):Promise<database.Dataset>;
 
 export function GetDatasets():Promise<Array<database.Dataset>>;
 
+export function GetEffectiveSettings():Promise<settings.Settings>;
+
+export function GetEncryptionStatus():Promise<backend.EncryptionStatus>;
+
 export function GetFileAsBase64(arg1:string):Promise<string>;
 
+export function GetFileInfo(arg1:string):Promise<database.FileEntry>;
+
 export function GetFilePath(arg1:string):Promise<string>;
 
+export function GetQuarantinedRecords():Promise<Array<database.QuarantinedRecord>>;
+
 export function GetRecord(arg1:string,arg2:boolean,arg3:boolean):Promise<Record<string, any>>;
 
 export function GetRecords(arg1:string,arg2:boolean):Promise<Array<Record<string, any>>>;
 
+export function GetRecordsSorted(arg1:string,arg2:string,arg3:boolean):Promise<Array<Record<string, any>>>;
+
 export function GetRecordsWithRelations(arg1:string,arg2:boolean):Promise<Array<Record<string, any>>>;
 
 export function GetRelatedRecords(arg1:string,arg2:string):Promise<Array<Record<string, any>>>;
 
+export function GetRules():Promise<Array<database.Rule>>;
+
+export function GetSettings():Promise<settings.Settings>;
+
+export function GetTags():Promise<Array<database.Tag>>;
+
+export function GetThumbnail(arg1:string,arg2:string):Promise<string>;
+
+export function GetThumbnails(arg1:string):Promise<Record<string, string>>;
+
+export function GetTimeline(arg1:string,arg2:string,arg3:Array<string>,arg4:number,arg5:number):Promise<database.TimelinePage>;
+
+export function GetUndoHistory():Promise<Array<database.JournalEntry>>;
+
+export function GetUploadStatus(arg1:string):Promise<file.UploadStatus>;
+
+export function GetView(arg1:string):Promise<database.SavedView>;
+
+export function GetViews(arg1:string):Promise<Array<database.SavedView>>;
+
 export function ImportRecords(arg1:string,arg2:string):Promise<number>;
 
+export function ListAPITokens():Promise<Array<database.APIToken>>;
+
+export function ListBackups():Promise<Array<backup.Info>>;
+
+export function ListFiles(arg1:string):Promise<Array<database.FileEntry>>;
+
+export function ListGeneratedBatches():Promise<Array<database.GeneratedBatch>>;
+
 export function LoadSampleData():Promise<void>;
 
+export function LockVault():Promise<backend.EncryptionStatus>;
+
+export function MergeTags(arg1:string,arg2:string):Promise<number>;
+
+export function PreviewDelete(arg1:string):Promise<database.DeleteImpact>;
+
 export function ProcessRecord(arg1:Record<string, any>,arg2:boolean):Promise<void>;
 
 export function ProcessRecordWithFiles(arg1:Record<string, any>,arg2:boolean):Promise<void>;
 
+export function QueryRecords(arg1:string,arg2:string):Promise<Array<Record<string, any>>>;
+
+export function Redo():Promise<database.JournalEntry>;
+
+export function RenameTag(arg1:string,arg2:string):Promise<number>;
+
+export function RepairIntegrity(arg1:string):Promise<integrity.RepairResult>;
+
 export function ResetAllData():Promise<void>;
 
+export function RestoreBackup(arg1:string):Promise<void>;
+
+export function RevokeAPIToken(arg1:string):Promise<void>;
+
+export function RunView(arg1:string):Promise<backend.ViewResult>;
+
 export function SaveFiles(arg1:any,arg2:string):Promise<any>;
 
+export function SearchRecords(arg1:string,arg2:Array<string>):Promise<Array<Record<string, any>>>;
+
+export function SetAPIServerSettings(arg1:boolean,arg2:number):Promise<settings.Settings>;
+
+export function SetBackupSettings(arg1:string):Promise<settings.Settings>;
+
+export function SetDatabasePath(arg1:string):Promise<settings.Settings>;
+
+export function SetFilesLocation(arg1:string):Promise<settings.Settings>;
+
+export function SetLockTimeout(arg1:number):Promise<settings.Settings>;
+
+export function SetRuleEnabled(arg1:string,arg2:boolean):Promise<database.Rule>;
+
+export function SetStripPhotoMetadata(arg1:boolean):Promise<settings.Settings>;
+
+export function SetUnitSystem(arg1:string):Promise<settings.Settings>;
+
+export function StartAPIServer():Promise<server.Status>;
+
+export function StopAPIServer():Promise<void>;
+
+export function Undo():Promise<database.JournalEntry>;
+
+export function UnlockVault(arg1:string):Promise<backend.EncryptionStatus>;
+
 export function UpdateDataset(arg1:string,arg2:string,arg3:string,arg4:string):Promise<database.Dataset>;
 
 export function UpdateRecord(arg1:string,arg2:string,arg3:boolean,arg4:boolean):Promise<Record<string, any>>;
 
-export function UploadFile(arg1:string,arg2:string,arg3:string):Promise<string>;
+export function UpdateRecordsMatching(arg1:string,arg2:string,arg3:string):Promise<number>;
+
+export function UpdateRule(arg1:string,arg2:string):Promise<database.Rule>;
+
+export function UpdateSettings(arg1:string):Promise<settings.Settings>;
+
+export function UpdateView(arg1:string,arg2:string):Promise<database.SavedView>;
 
-export function UploadFileChunk(arg1:string,arg2:string,arg3:number,arg4:number,arg5:string):Promise<string>;
+export function UploadChunk(arg1:string,arg2:number,arg3:string,arg4:string):Promise<file.UploadStatus>;
+
+export function UploadFile(arg1:string,arg2:string,arg3:string):Promise<string>;
 
 export function UploadFileWithName(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function ValidateFilter(arg1:string,arg2:string):Promise<void>;
+
+export function VerifyIntegrity():Promise<integrity.Report>;
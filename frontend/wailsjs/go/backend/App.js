@@ -2,18 +2,54 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AbortUpload(arg1) {
+  return window['go']['backend']['App']['AbortUpload'](arg1);
+}
+
 export function AddRecord(arg1, arg2, arg3) {
   return window['go']['backend']['App']['AddRecord'](arg1, arg2, arg3);
 }
 
+export function AnalyzeSeries(arg1, arg2) {
+  return window['go']['backend']['App']['AnalyzeSeries'](arg1, arg2);
+}
+
+export function BeginUpload(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['BeginUpload'](arg1, arg2, arg3, arg4);
+}
+
 export function CheckForDuplicates(arg1, arg2, arg3) {
   return window['go']['backend']['App']['CheckForDuplicates'](arg1, arg2, arg3);
 }
 
+export function CleanupGeneratedData() {
+  return window['go']['backend']['App']['CleanupGeneratedData']();
+}
+
+export function CreateAPIToken(arg1, arg2) {
+  return window['go']['backend']['App']['CreateAPIToken'](arg1, arg2);
+}
+
+export function CreateBackup() {
+  return window['go']['backend']['App']['CreateBackup']();
+}
+
+export function CreateBackupIn(arg1) {
+  return window['go']['backend']['App']['CreateBackupIn'](arg1);
+}
+
 export function CreateDataset(arg1, arg2, arg3, arg4) {
   return window['go']['backend']['App']['CreateDataset'](arg1, arg2, arg3, arg4);
 }
 
+export function CreateRule(arg1) {
+  return window['go']['backend']['App']['CreateRule'](arg1);
+}
+
+export function CreateView(arg1) {
+  return window['go']['backend']['App']['CreateView'](arg1);
+}
+
 export function DeleteDataset(arg1) {
   return window['go']['backend']['App']['DeleteDataset'](arg1);
 }
@@ -26,6 +62,54 @@ export function DeleteRecord(arg1) {
   return window['go']['backend']['App']['DeleteRecord'](arg1);
 }
 
+export function DeleteRecordsMatching(arg1, arg2) {
+  return window['go']['backend']['App']['DeleteRecordsMatching'](arg1, arg2);
+}
+
+export function DeleteRule(arg1) {
+  return window['go']['backend']['App']['DeleteRule'](arg1);
+}
+
+export function DeleteTag(arg1) {
+  return window['go']['backend']['App']['DeleteTag'](arg1);
+}
+
+export function DeleteView(arg1) {
+  return window['go']['backend']['App']['DeleteView'](arg1);
+}
+
+export function DisableEncryption(arg1) {
+  return window['go']['backend']['App']['DisableEncryption'](arg1);
+}
+
+export function EnableEncryption(arg1) {
+  return window['go']['backend']['App']['EnableEncryption'](arg1);
+}
+
+export function FinishUpload(arg1) {
+  return window['go']['backend']['App']['FinishUpload'](arg1);
+}
+
+export function ForecastGoal(arg1) {
+  return window['go']['backend']['App']['ForecastGoal'](arg1);
+}
+
+export function GenerateData(arg1) {
+  return window['go']['backend']['App']['GenerateData'](arg1);
+}
+
+export function GetAPIServerStatus() {
+  return window['go']['backend']['App']['GetAPIServerStatus']();
+}
+
+export function GetDailyDigest(arg1) {
+  return window['go']['backend']['App']['GetDailyDigest'](arg1);
+}
+
+export function GetDailyDigestMarkdown(arg1) {
+  return window['go']['backend']['App']['GetDailyDigestMarkdown'](arg1);
+}
+
 export function GetDataset(arg1) {
   return window['go']['backend']['App']['GetDataset'](arg1);
 }
@@ -34,14 +118,30 @@ export function GetDatasets() {
   return window['go']['backend']['App']['GetDatasets']();
 }
 
+export function GetEffectiveSettings() {
+  return window['go']['backend']['App']['GetEffectiveSettings']();
+}
+
+export function GetEncryptionStatus() {
+  return window['go']['backend']['App']['GetEncryptionStatus']();
+}
+
 export function GetFileAsBase64(arg1) {
   return window['go']['backend']['App']['GetFileAsBase64'](arg1);
 }
 
+export function GetFileInfo(arg1) {
+  return window['go']['backend']['App']['GetFileInfo'](arg1);
+}
+
 export function GetFilePath(arg1) {
   return window['go']['backend']['App']['GetFilePath'](arg1);
 }
 
+export function GetQuarantinedRecords() {
+  return window['go']['backend']['App']['GetQuarantinedRecords']();
+}
+
 export function GetRecord(arg1, arg2, arg3) {
   return window['go']['backend']['App']['GetRecord'](arg1, arg2, arg3);
 }
@@ -50,6 +150,10 @@ export function GetRecords(arg1, arg2) {
   return window['go']['backend']['App']['GetRecords'](arg1, arg2);
 }
 
+export function GetRecordsSorted(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['GetRecordsSorted'](arg1, arg2, arg3);
+}
+
 export function GetRecordsWithRelations(arg1, arg2) {
   return window['go']['backend']['App']['GetRecordsWithRelations'](arg1, arg2);
 }
@@ -58,14 +162,82 @@ export function GetRelatedRecords(arg1, arg2) {
   return window['go']['backend']['App']['GetRelatedRecords'](arg1, arg2);
 }
 
+export function GetRules() {
+  return window['go']['backend']['App']['GetRules']();
+}
+
+export function GetSettings() {
+  return window['go']['backend']['App']['GetSettings']();
+}
+
+export function GetTags() {
+  return window['go']['backend']['App']['GetTags']();
+}
+
+export function GetThumbnail(arg1, arg2) {
+  return window['go']['backend']['App']['GetThumbnail'](arg1, arg2);
+}
+
+export function GetThumbnails(arg1) {
+  return window['go']['backend']['App']['GetThumbnails'](arg1);
+}
+
+export function GetTimeline(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['backend']['App']['GetTimeline'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetUndoHistory() {
+  return window['go']['backend']['App']['GetUndoHistory']();
+}
+
+export function GetUploadStatus(arg1) {
+  return window['go']['backend']['App']['GetUploadStatus'](arg1);
+}
+
+export function GetView(arg1) {
+  return window['go']['backend']['App']['GetView'](arg1);
+}
+
+export function GetViews(arg1) {
+  return window['go']['backend']['App']['GetViews'](arg1);
+}
+
 export function ImportRecords(arg1, arg2) {
   return window['go']['backend']['App']['ImportRecords'](arg1, arg2);
 }
 
+export function ListAPITokens() {
+  return window['go']['backend']['App']['ListAPITokens']();
+}
+
+export function ListBackups() {
+  return window['go']['backend']['App']['ListBackups']();
+}
+
+export function ListFiles(arg1) {
+  return window['go']['backend']['App']['ListFiles'](arg1);
+}
+
+export function ListGeneratedBatches() {
+  return window['go']['backend']['App']['ListGeneratedBatches']();
+}
+
 export function LoadSampleData() {
   return window['go']['backend']['App']['LoadSampleData']();
 }
 
+export function LockVault() {
+  return window['go']['backend']['App']['LockVault']();
+}
+
+export function MergeTags(arg1, arg2) {
+  return window['go']['backend']['App']['MergeTags'](arg1, arg2);
+}
+
+export function PreviewDelete(arg1) {
+  return window['go']['backend']['App']['PreviewDelete'](arg1);
+}
+
 export function ProcessRecord(arg1, arg2) {
   return window['go']['backend']['App']['ProcessRecord'](arg1, arg2);
 }
@@ -74,14 +246,94 @@ export function ProcessRecordWithFiles(arg1, arg2) {
   return window['go']['backend']['App']['ProcessRecordWithFiles'](arg1, arg2);
 }
 
+export function QueryRecords(arg1, arg2) {
+  return window['go']['backend']['App']['QueryRecords'](arg1, arg2);
+}
+
+export function Redo() {
+  return window['go']['backend']['App']['Redo']();
+}
+
+export function RenameTag(arg1, arg2) {
+  return window['go']['backend']['App']['RenameTag'](arg1, arg2);
+}
+
+export function RepairIntegrity(arg1) {
+  return window['go']['backend']['App']['RepairIntegrity'](arg1);
+}
+
 export function ResetAllData() {
   return window['go']['backend']['App']['ResetAllData']();
 }
 
+export function RestoreBackup(arg1) {
+  return window['go']['backend']['App']['RestoreBackup'](arg1);
+}
+
+export function RevokeAPIToken(arg1) {
+  return window['go']['backend']['App']['RevokeAPIToken'](arg1);
+}
+
+export function RunView(arg1) {
+  return window['go']['backend']['App']['RunView'](arg1);
+}
+
 export function SaveFiles(arg1, arg2) {
   return window['go']['backend']['App']['SaveFiles'](arg1, arg2);
 }
 
+export function SearchRecords(arg1, arg2) {
+  return window['go']['backend']['App']['SearchRecords'](arg1, arg2);
+}
+
+export function SetAPIServerSettings(arg1, arg2) {
+  return window['go']['backend']['App']['SetAPIServerSettings'](arg1, arg2);
+}
+
+export function SetBackupSettings(arg1) {
+  return window['go']['backend']['App']['SetBackupSettings'](arg1);
+}
+
+export function SetDatabasePath(arg1) {
+  return window['go']['backend']['App']['SetDatabasePath'](arg1);
+}
+
+export function SetFilesLocation(arg1) {
+  return window['go']['backend']['App']['SetFilesLocation'](arg1);
+}
+
+export function SetLockTimeout(arg1) {
+  return window['go']['backend']['App']['SetLockTimeout'](arg1);
+}
+
+export function SetRuleEnabled(arg1, arg2) {
+  return window['go']['backend']['App']['SetRuleEnabled'](arg1, arg2);
+}
+
+export function SetStripPhotoMetadata(arg1) {
+  return window['go']['backend']['App']['SetStripPhotoMetadata'](arg1);
+}
+
+export function SetUnitSystem(arg1) {
+  return window['go']['backend']['App']['SetUnitSystem'](arg1);
+}
+
+export function StartAPIServer() {
+  return window['go']['backend']['App']['StartAPIServer']();
+}
+
+export function StopAPIServer() {
+  return window['go']['backend']['App']['StopAPIServer']();
+}
+
+export function Undo() {
+  return window['go']['backend']['App']['Undo']();
+}
+
+export function UnlockVault(arg1) {
+  return window['go']['backend']['App']['UnlockVault'](arg1);
+}
+
 export function UpdateDataset(arg1, arg2, arg3, arg4) {
   return window['go']['backend']['App']['UpdateDataset'](arg1, arg2, arg3, arg4);
 }
@@ -90,14 +342,38 @@ export function UpdateRecord(arg1, arg2, arg3, arg4) {
   return window['go']['backend']['App']['UpdateRecord'](arg1, arg2, arg3, arg4);
 }
 
-export function UploadFile(arg1, arg2, arg3) {
-  return window['go']['backend']['App']['UploadFile'](arg1, arg2, arg3);
+export function UpdateRecordsMatching(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['UpdateRecordsMatching'](arg1, arg2, arg3);
+}
+
+export function UpdateRule(arg1, arg2) {
+  return window['go']['backend']['App']['UpdateRule'](arg1, arg2);
+}
+
+export function UpdateSettings(arg1) {
+  return window['go']['backend']['App']['UpdateSettings'](arg1);
+}
+
+export function UpdateView(arg1, arg2) {
+  return window['go']['backend']['App']['UpdateView'](arg1, arg2);
 }
 
-export function UploadFileChunk(arg1, arg2, arg3, arg4, arg5) {
-  return window['go']['backend']['App']['UploadFileChunk'](arg1, arg2, arg3, arg4, arg5);
+export function UploadChunk(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['UploadChunk'](arg1, arg2, arg3, arg4);
+}
+
+export function UploadFile(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['UploadFile'](arg1, arg2, arg3);
 }
 
 export function UploadFileWithName(arg1, arg2, arg3) {
   return window['go']['backend']['App']['UploadFileWithName'](arg1, arg2, arg3);
 }
+
+export function ValidateFilter(arg1, arg2) {
+  return window['go']['backend']['App']['ValidateFilter'](arg1, arg2);
+}
+
+export function VerifyIntegrity() {
+  return window['go']['backend']['App']['VerifyIntegrity']();
+}
@@ -1,88 +1,2164 @@
+export namespace analytics {
+	
+	export class Trend {
+	    points: number;
+	    // Go type: time
+	    origin: any;
+	    intercept: number;
+	    slope: number;
+	    slopeLow: number;
+	    slopeHigh: number;
+	    perWeek: number;
+	    perMonth: number;
+	    rSquared: number;
+	    stdError: number;
+	    significant: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Trend(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.points = source["points"];
+	        this.origin = this.convertValues(source["origin"], null);
+	        this.intercept = source["intercept"];
+	        this.slope = source["slope"];
+	        this.slopeLow = source["slopeLow"];
+	        this.slopeHigh = source["slopeHigh"];
+	        this.perWeek = source["perWeek"];
+	        this.perMonth = source["perMonth"];
+	        this.rSquared = source["rSquared"];
+	        this.stdError = source["stdError"];
+	        this.significant = source["significant"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PeriodChange {
+	    // Go type: time
+	    start: any;
+	    count: number;
+	    mean: number;
+	    change?: number;
+	    percent?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PeriodChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = this.convertValues(source["start"], null);
+	        this.count = source["count"];
+	        this.mean = source["mean"];
+	        this.change = source["change"];
+	        this.percent = source["percent"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WindowStats {
+	    // Go type: time
+	    date: any;
+	    count: number;
+	    mean: number;
+	    min: number;
+	    max: number;
+	    percentiles: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new WindowStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = this.convertValues(source["date"], null);
+	        this.count = source["count"];
+	        this.mean = source["mean"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.percentiles = source["percentiles"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Summary {
+	    count: number;
+	    mean: number;
+	    min: number;
+	    max: number;
+	    percentiles: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Summary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.count = source["count"];
+	        this.mean = source["mean"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.percentiles = source["percentiles"];
+	    }
+	}
+	export class Point {
+	    // Go type: time
+	    date: any;
+	    value: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Point(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = this.convertValues(source["date"], null);
+	        this.value = source["value"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SeriesQuery {
+	    kind?: string;
+	    key?: string;
+	    datasetId?: string;
+	    field?: string;
+	    dateField?: string;
+	    filter?: string;
+	    from?: string;
+	    to?: string;
+	    aggregate?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SeriesQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.key = source["key"];
+	        this.datasetId = source["datasetId"];
+	        this.field = source["field"];
+	        this.dateField = source["dateField"];
+	        this.filter = source["filter"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.aggregate = source["aggregate"];
+	    }
+	}
+	export class Analysis {
+	    query: SeriesQuery;
+	    unit?: string;
+	    points: Point[];
+	    summary: Summary;
+	    rolling: WindowStats[];
+	    smoothed: Point[];
+	    changes: PeriodChange[];
+	    trend?: Trend;
+	
+	    static createFrom(source: any = {}) {
+	        return new Analysis(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.query = this.convertValues(source["query"], SeriesQuery);
+	        this.unit = source["unit"];
+	        this.points = this.convertValues(source["points"], Point);
+	        this.summary = this.convertValues(source["summary"], Summary);
+	        this.rolling = this.convertValues(source["rolling"], WindowStats);
+	        this.smoothed = this.convertValues(source["smoothed"], Point);
+	        this.changes = this.convertValues(source["changes"], PeriodChange);
+	        this.trend = this.convertValues(source["trend"], Trend);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Projection {
+	    // Go type: time
+	    date: any;
+	    value: number;
+	    low: number;
+	    high: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Projection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = this.convertValues(source["date"], null);
+	        this.value = source["value"];
+	        this.low = source["low"];
+	        this.high = source["high"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Goal {
+	    series: SeriesQuery;
+	    target?: number;
+	    direction?: string;
+	    method?: string;
+	    horizonDays?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Goal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.series = this.convertValues(source["series"], SeriesQuery);
+	        this.target = source["target"];
+	        this.direction = source["direction"];
+	        this.method = source["method"];
+	        this.horizonDays = source["horizonDays"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Forecast {
+	    goal: Goal;
+	    unit?: string;
+	    target: number;
+	    current: Projection;
+	    perWeek: number;
+	    reached: boolean;
+	    // Go type: time
+	    date?: any;
+	    // Go type: time
+	    earliest?: any;
+	    // Go type: time
+	    latest?: any;
+	    projection: Projection[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Forecast(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.goal = this.convertValues(source["goal"], Goal);
+	        this.unit = source["unit"];
+	        this.target = source["target"];
+	        this.current = this.convertValues(source["current"], Projection);
+	        this.perWeek = source["perWeek"];
+	        this.reached = source["reached"];
+	        this.date = this.convertValues(source["date"], null);
+	        this.earliest = this.convertValues(source["earliest"], null);
+	        this.latest = this.convertValues(source["latest"], null);
+	        this.projection = this.convertValues(source["projection"], Projection);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	
+	
+	
+	
+
+}
+
 export namespace backend {
 	
-	export class DuplicateResult {
-	    importRecord: Record<string, any>;
-	    existingRecords: any[];
-	    duplicateFields: string[];
-	    confidence: number;
+	export class DuplicateResult {
+	    importRecord: Record<string, any>;
+	    existingRecords: any[];
+	    duplicateFields: string[];
+	    confidence: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DuplicateResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.importRecord = source["importRecord"];
+	        this.existingRecords = source["existingRecords"];
+	        this.duplicateFields = source["duplicateFields"];
+	        this.confidence = source["confidence"];
+	    }
+	}
+	export class EncryptionJob {
+	    running: boolean;
+	    encrypting: boolean;
+	    progress: file.ConvertProgress;
+	    error?: string;
+	    // Go type: time
+	    startedAt?: any;
+	    // Go type: time
+	    finishedAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionJob(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.running = source["running"];
+	        this.encrypting = source["encrypting"];
+	        this.progress = this.convertValues(source["progress"], file.ConvertProgress);
+	        this.error = source["error"];
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.finishedAt = this.convertValues(source["finishedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EncryptionStatus {
+	    enabled: boolean;
+	    configured: boolean;
+	    unlocked: boolean;
+	    job: EncryptionJob;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.configured = source["configured"];
+	        this.unlocked = source["unlocked"];
+	        this.job = this.convertValues(source["job"], EncryptionJob);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ViewGroup {
+	    value: any;
+	    count: number;
+	    records: any[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ViewGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.count = source["count"];
+	        this.records = source["records"];
+	    }
+	}
+	export class ViewResult {
+	    view: database.SavedView;
+	    records?: any[];
+	    groups?: ViewGroup[];
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ViewResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.view = this.convertValues(source["view"], database.SavedView);
+	        this.records = source["records"];
+	        this.groups = this.convertValues(source["groups"], ViewGroup);
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace backup {
+	
+	export class Info {
+	    path: string;
+	    name: string;
+	    size: number;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Info(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace database {
+	
+	export class APIToken {
+	    id: string;
+	    name: string;
+	    allowPrivate: boolean;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    lastUsedAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new APIToken(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.allowPrivate = source["allowPrivate"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.lastUsedAt = this.convertValues(source["lastUsedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CreatedAPIToken {
+	    id: string;
+	    name: string;
+	    allowPrivate: boolean;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    lastUsedAt?: any;
+	    token: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CreatedAPIToken(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.allowPrivate = source["allowPrivate"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.lastUsedAt = this.convertValues(source["lastUsedAt"], null);
+	        this.token = source["token"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FieldDefinition {
+	    key: string;
+	    type: string;
+	    displayName: string;
+	    description?: string;
+	    unit?: string;
+	    isSearchable?: boolean;
+	    isOptional?: boolean;
+	    isUnique?: boolean;
+	    isIndexed?: boolean;
+	    relatedDataset?: string;
+	    relatedField?: string;
+	    isRelation?: boolean;
+	    preventDeleteIfReferenced?: boolean;
+	    cascadeDeleteIfReferenced?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldDefinition(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.type = source["type"];
+	        this.displayName = source["displayName"];
+	        this.description = source["description"];
+	        this.unit = source["unit"];
+	        this.isSearchable = source["isSearchable"];
+	        this.isOptional = source["isOptional"];
+	        this.isUnique = source["isUnique"];
+	        this.isIndexed = source["isIndexed"];
+	        this.relatedDataset = source["relatedDataset"];
+	        this.relatedField = source["relatedField"];
+	        this.isRelation = source["isRelation"];
+	        this.preventDeleteIfReferenced = source["preventDeleteIfReferenced"];
+	        this.cascadeDeleteIfReferenced = source["cascadeDeleteIfReferenced"];
+	    }
+	}
+	export class Dataset {
+	    id: string;
+	    name: string;
+	    description?: string;
+	    type: string;
+	    fields: FieldDefinition[];
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    lastModified: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Dataset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.type = source["type"];
+	        this.fields = this.convertValues(source["fields"], FieldDefinition);
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.lastModified = this.convertValues(source["lastModified"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Reference {
+	    datasetId: string;
+	    recordId: string;
+	    field: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Reference(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.datasetId = source["datasetId"];
+	        this.recordId = source["recordId"];
+	        this.field = source["field"];
+	    }
+	}
+	export class ImpactFile {
+	    path: string;
+	    recordId: string;
+	    field: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImpactFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.recordId = source["recordId"];
+	        this.field = source["field"];
+	    }
+	}
+	export class ImpactRecord {
+	    id: string;
+	    datasetId: string;
+	    title: string;
+	    cascadeFrom?: string;
+	    field?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImpactRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.datasetId = source["datasetId"];
+	        this.title = source["title"];
+	        this.cascadeFrom = source["cascadeFrom"];
+	        this.field = source["field"];
+	    }
+	}
+	export class DeleteImpact {
+	    recordId: string;
+	    datasetId: string;
+	    records: ImpactRecord[];
+	    files: ImpactFile[];
+	    blockers: Reference[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DeleteImpact(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.datasetId = source["datasetId"];
+	        this.records = this.convertValues(source["records"], ImpactRecord);
+	        this.files = this.convertValues(source["files"], ImpactFile);
+	        this.blockers = this.convertValues(source["blockers"], Reference);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class FileOwner {
+	    recordId: string;
+	    datasetId: string;
+	    field: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileOwner(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.datasetId = source["datasetId"];
+	        this.field = source["field"];
+	    }
+	}
+	export class FileEntry {
+	    path: string;
+	    hash: string;
+	    size: number;
+	    originalName: string;
+	    mimeType: string;
+	    image?: file.ImageMetadata;
+	    refCount: number;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    lastModified: any;
+	    owners: FileOwner[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FileEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.hash = source["hash"];
+	        this.size = source["size"];
+	        this.originalName = source["originalName"];
+	        this.mimeType = source["mimeType"];
+	        this.image = this.convertValues(source["image"], file.ImageMetadata);
+	        this.refCount = source["refCount"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.lastModified = this.convertValues(source["lastModified"], null);
+	        this.owners = this.convertValues(source["owners"], FileOwner);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class GeneratedBatch {
+	    id: string;
+	    seed: number;
+	    options: number[];
+	    counts: Record<string, number>;
+	    recordCount: number;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new GeneratedBatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.seed = source["seed"];
+	        this.options = source["options"];
+	        this.counts = source["counts"];
+	        this.recordCount = source["recordCount"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GeneratedCleanup {
+	    deleted: Record<string, number>;
+	    total: number;
+	    kept: number;
+	    keptIds: string[];
+	    batches: number;
+	    remaining: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GeneratedCleanup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.deleted = source["deleted"];
+	        this.total = source["total"];
+	        this.kept = source["kept"];
+	        this.keptIds = source["keptIds"];
+	        this.batches = source["batches"];
+	        this.remaining = source["remaining"];
+	    }
+	}
+	
+	
+	export class JournalEntry {
+	    id: number;
+	    label: string;
+	    changes: number;
+	    undone: boolean;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	        this.changes = source["changes"];
+	        this.undone = source["undone"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class QuarantinedRecord {
+	    id: string;
+	    datasetId: string;
+	    data: number[];
+	    reason: string;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    lastModified: any;
+	    // Go type: time
+	    quarantinedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new QuarantinedRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.datasetId = source["datasetId"];
+	        this.data = source["data"];
+	        this.reason = source["reason"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.lastModified = this.convertValues(source["lastModified"], null);
+	        this.quarantinedAt = this.convertValues(source["quarantinedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class RuleFieldMapping {
+	    target: string;
+	    source?: string;
+	    value?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleFieldMapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.target = source["target"];
+	        this.source = source["source"];
+	        this.value = source["value"];
+	    }
+	}
+	export class RuleAction {
+	    type: string;
+	    targetDataset: string;
+	    targetRecordField?: string;
+	    targetConditions?: RuleCondition[];
+	    targetFilter?: string;
+	    mappings: RuleFieldMapping[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleAction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.targetDataset = source["targetDataset"];
+	        this.targetRecordField = source["targetRecordField"];
+	        this.targetConditions = this.convertValues(source["targetConditions"], RuleCondition);
+	        this.targetFilter = source["targetFilter"];
+	        this.mappings = this.convertValues(source["mappings"], RuleFieldMapping);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RuleCondition {
+	    field: string;
+	    operator: string;
+	    value?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleCondition(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.operator = source["operator"];
+	        this.value = source["value"];
+	    }
+	}
+	export class Rule {
+	    id: string;
+	    name: string;
+	    description?: string;
+	    enabled: boolean;
+	    sourceDataset: string;
+	    events: string[];
+	    conditions: RuleCondition[];
+	    filter?: string;
+	    action: RuleAction;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    lastModified: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Rule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.enabled = source["enabled"];
+	        this.sourceDataset = source["sourceDataset"];
+	        this.events = source["events"];
+	        this.conditions = this.convertValues(source["conditions"], RuleCondition);
+	        this.filter = source["filter"];
+	        this.action = this.convertValues(source["action"], RuleAction);
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.lastModified = this.convertValues(source["lastModified"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class SavedView {
+	    id: string;
+	    name: string;
+	    description?: string;
+	    datasetId: string;
+	    filter?: string;
+	    sort?: string;
+	    descending?: boolean;
+	    columns: string[];
+	    groupBy?: string;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    lastModified: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.datasetId = source["datasetId"];
+	        this.filter = source["filter"];
+	        this.sort = source["sort"];
+	        this.descending = source["descending"];
+	        this.columns = source["columns"];
+	        this.groupBy = source["groupBy"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.lastModified = this.convertValues(source["lastModified"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Tag {
+	    id: string;
+	    name: string;
+	    usage: number;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.usage = source["usage"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TimelineDataset {
+	    id: string;
+	    name: string;
+	    dateField: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelineDataset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.dateField = source["dateField"];
+	    }
+	}
+	export class TimelineEntry {
+	    recordId: string;
+	    datasetId: string;
+	    dateField: string;
+	    // Go type: time
+	    date: any;
+	    title: string;
+	    private?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelineEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.datasetId = source["datasetId"];
+	        this.dateField = source["dateField"];
+	        this.date = this.convertValues(source["date"], null);
+	        this.title = source["title"];
+	        this.private = source["private"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TimelinePage {
+	    entries: TimelineEntry[];
+	    datasets: TimelineDataset[];
+	    total: number;
+	    offset: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelinePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], TimelineEntry);
+	        this.datasets = this.convertValues(source["datasets"], TimelineDataset);
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace digest {
+	
+	export class PriorEntry {
+	    recordId: string;
+	    datasetId: string;
+	    dataset: string;
+	    title: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PriorEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.datasetId = source["datasetId"];
+	        this.dataset = source["dataset"];
+	        this.title = source["title"];
+	    }
+	}
+	export class PriorYear {
+	    year: number;
+	    yearsAgo: number;
+	    total: number;
+	    entries: PriorEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PriorYear(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.yearsAgo = source["yearsAgo"];
+	        this.total = source["total"];
+	        this.entries = this.convertValues(source["entries"], PriorEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Measurement {
+	    recordId: string;
+	    measurement: string;
+	    value: number;
+	    unit?: string;
+	    time?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Measurement(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.measurement = source["measurement"];
+	        this.value = source["value"];
+	        this.unit = source["unit"];
+	        this.time = source["time"];
+	    }
+	}
+	export class Transaction {
+	    recordId: string;
+	    description: string;
+	    category?: string;
+	    amount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Transaction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.description = source["description"];
+	        this.category = source["category"];
+	        this.amount = source["amount"];
+	    }
+	}
+	export class Transactions {
+	    entries: Transaction[];
+	    income: number;
+	    expenses: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Transactions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], Transaction);
+	        this.income = source["income"];
+	        this.expenses = source["expenses"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Todo {
+	    recordId: string;
+	    title: string;
+	    deadline?: string;
+	    priority?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Todo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.title = source["title"];
+	        this.deadline = source["deadline"];
+	        this.priority = source["priority"];
+	    }
+	}
+	export class Todos {
+	    completed: Todo[];
+	    overdue: Todo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Todos(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.completed = this.convertValues(source["completed"], Todo);
+	        this.overdue = this.convertValues(source["overdue"], Todo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Meeting {
+	    recordId: string;
+	    person?: string;
+	    location?: string;
+	    durationMinutes?: number;
+	    description?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Meeting(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.person = source["person"];
+	        this.location = source["location"];
+	        this.durationMinutes = source["durationMinutes"];
+	        this.description = source["description"];
+	    }
+	}
+	export class JournalNote {
+	    recordId: string;
+	    datasetId: string;
+	    journal: string;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalNote(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.datasetId = source["datasetId"];
+	        this.journal = source["journal"];
+	        this.text = source["text"];
+	    }
+	}
+	export class TimeCategory {
+	    name: string;
+	    minutes: number;
+	    entries: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimeCategory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.minutes = source["minutes"];
+	        this.entries = source["entries"];
+	    }
+	}
+	export class TimeTracked {
+	    totalMinutes: number;
+	    categories: TimeCategory[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TimeTracked(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.totalMinutes = source["totalMinutes"];
+	        this.categories = this.convertValues(source["categories"], TimeCategory);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MetricRef {
+	    metricId: string;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MetricRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.metricId = source["metricId"];
+	        this.name = source["name"];
+	    }
+	}
+	export class MetricLog {
+	    recordId: string;
+	    metricId?: string;
+	    name: string;
+	    value: string;
+	    unit?: string;
+	    notes?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MetricLog(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recordId = source["recordId"];
+	        this.metricId = source["metricId"];
+	        this.name = source["name"];
+	        this.value = source["value"];
+	        this.unit = source["unit"];
+	        this.notes = source["notes"];
+	    }
+	}
+	export class Metrics {
+	    logged: MetricLog[];
+	    missed: MetricRef[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Metrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.logged = this.convertValues(source["logged"], MetricLog);
+	        this.missed = this.convertValues(source["missed"], MetricRef);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Digest {
+	    date: string;
+	    weekday: string;
+	    metrics: Metrics;
+	    time: TimeTracked;
+	    journal: JournalNote[];
+	    meetings: Meeting[];
+	    todos: Todos;
+	    transactions: Transactions;
+	    measurements: Measurement[];
+	    priorYears: PriorYear[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Digest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.weekday = source["weekday"];
+	        this.metrics = this.convertValues(source["metrics"], Metrics);
+	        this.time = this.convertValues(source["time"], TimeTracked);
+	        this.journal = this.convertValues(source["journal"], JournalNote);
+	        this.meetings = this.convertValues(source["meetings"], Meeting);
+	        this.todos = this.convertValues(source["todos"], Todos);
+	        this.transactions = this.convertValues(source["transactions"], Transactions);
+	        this.measurements = this.convertValues(source["measurements"], Measurement);
+	        this.priorYears = this.convertValues(source["priorYears"], PriorYear);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+
+}
+
+export namespace file {
+	
+	export class ConvertProgress {
+	    total: number;
+	    done: number;
+	    converted: number;
+	    failed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ConvertProgress(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.done = source["done"];
+	        this.converted = source["converted"];
+	        this.failed = source["failed"];
+	    }
+	}
+	export class ImageMetadata {
+	    width: number;
+	    height: number;
+	    orientation: number;
+	    // Go type: time
+	    takenAt?: any;
+	    cameraMake?: string;
+	    cameraModel?: string;
+	    hadLocation: boolean;
+	    stripped: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImageMetadata(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.orientation = source["orientation"];
+	        this.takenAt = this.convertValues(source["takenAt"], null);
+	        this.cameraMake = source["cameraMake"];
+	        this.cameraModel = source["cameraModel"];
+	        this.hadLocation = source["hadLocation"];
+	        this.stripped = source["stripped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UploadSession {
+	    id: string;
+	    fileName: string;
+	    size: number;
+	    chunkSize: number;
+	    totalChunks: number;
+	    sha256?: string;
+	    // Go type: time
+	    createdAt: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new DuplicateResult(source);
+	        return new UploadSession(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.importRecord = source["importRecord"];
-	        this.existingRecords = source["existingRecords"];
-	        this.duplicateFields = source["duplicateFields"];
-	        this.confidence = source["confidence"];
+	        this.id = source["id"];
+	        this.fileName = source["fileName"];
+	        this.size = source["size"];
+	        this.chunkSize = source["chunkSize"];
+	        this.totalChunks = source["totalChunks"];
+	        this.sha256 = source["sha256"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UploadStatus {
+	    session: UploadSession;
+	    received: number;
+	    missing: number[];
+	    complete: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UploadStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session = this.convertValues(source["session"], UploadSession);
+	        this.received = source["received"];
+	        this.missing = source["missing"];
+	        this.complete = source["complete"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
 
-export namespace database {
+export namespace integrity {
 	
-	export class FieldDefinition {
-	    key: string;
-	    type: string;
-	    displayName: string;
-	    description?: string;
-	    unit?: string;
-	    isSearchable?: boolean;
-	    isOptional?: boolean;
-	    isUnique?: boolean;
-	    relatedDataset?: string;
-	    relatedField?: string;
-	    isRelation?: boolean;
-	    preventDeleteIfReferenced?: boolean;
-	    cascadeDeleteIfReferenced?: boolean;
+	export class Issue {
+	    kind: string;
+	    datasetId?: string;
+	    recordId?: string;
+	    field?: string;
+	    path?: string;
+	    message: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FieldDefinition(source);
+	        return new Issue(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.key = source["key"];
-	        this.type = source["type"];
-	        this.displayName = source["displayName"];
-	        this.description = source["description"];
-	        this.unit = source["unit"];
-	        this.isSearchable = source["isSearchable"];
-	        this.isOptional = source["isOptional"];
-	        this.isUnique = source["isUnique"];
-	        this.relatedDataset = source["relatedDataset"];
-	        this.relatedField = source["relatedField"];
-	        this.isRelation = source["isRelation"];
-	        this.preventDeleteIfReferenced = source["preventDeleteIfReferenced"];
-	        this.cascadeDeleteIfReferenced = source["cascadeDeleteIfReferenced"];
+	        this.kind = source["kind"];
+	        this.datasetId = source["datasetId"];
+	        this.recordId = source["recordId"];
+	        this.field = source["field"];
+	        this.path = source["path"];
+	        this.message = source["message"];
 	    }
 	}
-	export class Dataset {
-	    id: string;
-	    name: string;
-	    description?: string;
-	    type: string;
-	    fields: FieldDefinition[];
-	    // Go type: time
-	    createdAt: any;
+	export class RepairAction {
+	    issue: Issue;
+	    action: string;
+	    applied: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RepairAction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.issue = this.convertValues(source["issue"], Issue);
+	        this.action = source["action"];
+	        this.applied = source["applied"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Report {
 	    // Go type: time
-	    lastModified: any;
+	    checkedAt: any;
+	    recordsChecked: number;
+	    filesChecked: number;
+	    issues: Issue[];
+	    counts: Record<string, number>;
 	
 	    static createFrom(source: any = {}) {
-	        return new Dataset(source);
+	        return new Report(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.description = source["description"];
-	        this.type = source["type"];
-	        this.fields = this.convertValues(source["fields"], FieldDefinition);
-	        this.createdAt = this.convertValues(source["createdAt"], null);
-	        this.lastModified = this.convertValues(source["lastModified"], null);
+	        this.checkedAt = this.convertValues(source["checkedAt"], null);
+	        this.recordsChecked = source["recordsChecked"];
+	        this.filesChecked = source["filesChecked"];
+	        this.issues = this.convertValues(source["issues"], Issue);
+	        this.counts = source["counts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RepairResult {
+	    dryRun: boolean;
+	    report: Report;
+	    actions: RepairAction[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RepairResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dryRun = source["dryRun"];
+	        this.report = this.convertValues(source["report"], Report);
+	        this.actions = this.convertValues(source["actions"], RepairAction);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace server {
+	
+	export class Status {
+	    running: boolean;
+	    address?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Status(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.running = source["running"];
+	        this.address = source["address"];
+	    }
+	}
+
+}
+
+export namespace settings {
+	
+	export class AttachmentSettings {
+	    stripPhotoMetadata: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AttachmentSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.stripPhotoMetadata = source["stripPhotoMetadata"];
+	    }
+	}
+	export class BackupSettings {
+	    enabled: boolean;
+	    directory?: string;
+	    intervalHours: number;
+	    retention: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.directory = source["directory"];
+	        this.intervalHours = source["intervalHours"];
+	        this.retention = source["retention"];
+	    }
+	}
+	export class EncryptionSettings {
+	    enabled: boolean;
+	    salt?: string;
+	    keyCheck?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.salt = source["salt"];
+	        this.keyCheck = source["keyCheck"];
+	    }
+	}
+	export class ServerSettings {
+	    enabled: boolean;
+	    port: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServerSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.port = source["port"];
+	    }
+	}
+	export class Settings {
+	    databasePath?: string;
+	    filesLocation?: string;
+	    backup: BackupSettings;
+	    server: ServerSettings;
+	    encryption: EncryptionSettings;
+	    attachments: AttachmentSettings;
+	    lockTimeoutMs: number;
+	    unitSystem: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.databasePath = source["databasePath"];
+	        this.filesLocation = source["filesLocation"];
+	        this.backup = this.convertValues(source["backup"], BackupSettings);
+	        this.server = this.convertValues(source["server"], ServerSettings);
+	        this.encryption = this.convertValues(source["encryption"], EncryptionSettings);
+	        this.attachments = this.convertValues(source["attachments"], AttachmentSettings);
+	        this.lockTimeoutMs = source["lockTimeoutMs"];
+	        this.unitSystem = source["unitSystem"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
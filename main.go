@@ -4,6 +4,8 @@ import (
 	"embed"
 	"log"
 	"myproject/backend"
+	"myproject/backend/cli"
+	"myproject/backend/settings"
 	"os"
 
 	"github.com/wailsapp/wails/v2"
@@ -16,24 +18,36 @@ import (
 var assets embed.FS
 
 func main() {
-	app := backend.NewApp()
+	envOverrides, err := settings.OverridesFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	if len(os.Args) > 1 && os.Args[1] == "dev" {
-		os.Setenv("BUILD_MODE", "dev")
-	} else {
-		os.Setenv("BUILD_MODE", "production")
+	flagOverrides, args, err := settings.ParseFlags(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	err := wails.Run(&options.App{
+	overrides := envOverrides.Merge(flagOverrides)
+
+	if len(args) > 0 && cli.IsCommand(args[0]) {
+		os.Exit(cli.Run(args, overrides))
+	}
+
+	app := backend.NewApp(overrides)
+
+	err = wails.Run(&options.App{
 		Title:  "Data Desktop",
 		Width:  800,
 		Height: 600,
 		AssetServer: &assetserver.Options{
-			Assets: assets,
+			Assets:  assets,
+			Handler: backend.NewFileHandler(app),
 		},
 		BackgroundColour: &options.RGBA{R: 255, G: 255, B: 255, A: 1},
 		OnStartup:        app.Startup,
 		OnShutdown:       app.Shutdown,
+		ErrorFormatter:   backend.FormatError,
 		Bind: []interface{}{
 			app,
 		},